@@ -0,0 +1,146 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/ui/components"
+	"midi-viewer/internal/ui/theme"
+)
+
+// screen is the component the app is showing
+type screen int
+
+const (
+	screenDevices screen = iota
+	screenViewer
+	screenOptions
+)
+
+// app is the root model: it shows the device selector, then the event viewer for the chosen
+// device, with the options modal over the viewer
+type app struct {
+	theme    theme.Theme
+	screen   screen
+	selector components.DeviceSelector
+	viewer   components.EventViewer
+	options  components.OptionsModal
+	width    int
+	height   int
+
+	send    func(tea.Msg) // delivers MIDI input to the running program
+	stop    func()        // stops listening to the viewer's device, or nil
+	offline bool          // showing an opened capture, with no device selection
+}
+
+// newApp creates the app at device selection
+func newApp(t theme.Theme, send func(tea.Msg)) app {
+	return app{
+		theme:    t,
+		screen:   screenDevices,
+		selector: components.NewDeviceSelector(t),
+		send:     send,
+	}
+}
+
+// newOfflineApp creates the app showing a saved capture, without device selection
+func newOfflineApp(t theme.Theme, name string, events []midi.Event) app {
+	return app{
+		theme:   t,
+		screen:  screenViewer,
+		viewer:  components.NewOfflineViewer(name, events, t),
+		offline: true,
+	}
+}
+
+func (a app) Init() tea.Cmd {
+	if a.screen == screenViewer {
+		return a.viewer.Init()
+	}
+	return a.selector.Init()
+}
+
+func (a app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		a.width, a.height = msg.Width, msg.Height
+		a.selector, _ = a.selector.Update(msg)
+		a.options, _ = a.options.Update(msg)
+		if a.screen != screenDevices {
+			a.viewer, cmd = a.viewer.Update(msg)
+		}
+		return a, cmd
+
+	case components.DeviceSelectedMsg:
+		a.stopListening()
+		a.viewer = components.NewEventViewer(msg.Device, a.theme)
+		a.viewer, _ = a.viewer.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+		stop, err := components.ListenDevice(msg.Device, a.send)
+		if err != nil {
+			a.selector, cmd = a.selector.Update(components.DeviceErrorMsg{Err: err})
+			return a, cmd
+		}
+		a.stop = stop
+		a.screen = screenViewer
+		return a, a.viewer.Init()
+
+	case components.BackToDeviceSelectionMsg:
+		a.stopListening()
+		if a.offline {
+			return a, tea.Quit // an opened capture has no device selection to go back to
+		}
+		a.screen = screenDevices
+		return a, a.selector.Init()
+
+	case components.OpenOptionsModalMsg:
+		a.options = components.NewOptionsModal(a.viewer.GetFilter(), a.theme)
+		a.options, _ = a.options.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+		a.screen = screenOptions
+		return a, nil
+
+	case components.CloseOptionsModalMsg:
+		a.screen = screenViewer
+		a.viewer, cmd = a.viewer.Update(components.FilterUpdatedMsg{Filter: msg.Filter})
+		return a, cmd
+
+	case tea.KeyMsg, tea.MouseMsg:
+		// Input goes to the component on screen
+		switch a.screen {
+		case screenDevices:
+			a.selector, cmd = a.selector.Update(msg)
+		case screenViewer:
+			a.viewer, cmd = a.viewer.Update(msg)
+		case screenOptions:
+			a.options, cmd = a.options.Update(msg)
+		}
+		return a, cmd
+	}
+
+	// Anything else is the result of a component's command; the viewer keeps running
+	// under the options modal
+	if a.screen == screenDevices {
+		a.selector, cmd = a.selector.Update(msg)
+	} else {
+		a.viewer, cmd = a.viewer.Update(msg)
+	}
+	return a, cmd
+}
+
+func (a app) View() string {
+	switch a.screen {
+	case screenViewer:
+		return a.viewer.View()
+	case screenOptions:
+		return a.options.View()
+	}
+	return a.selector.View()
+}
+
+// stopListening stops listening to the viewer's device, if it is listened to
+func (a *app) stopListening() {
+	if a.stop != nil {
+		a.stop()
+		a.stop = nil
+	}
+}
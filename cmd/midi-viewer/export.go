@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/models"
+)
+
+// runExport captures from input device number until interrupted, or for duration if it is
+// positive, and writes the events to path in the format its extension picks, without the TUI
+func runExport(number int, path string, duration time.Duration) error {
+	cleanup, err := midi.InitDriver()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	devices, err := midi.GetInputDevices()
+	if err != nil {
+		return err
+	}
+	devices = append(devices, midi.SerialDevices(len(devices))...)
+	if number < 0 || number >= len(devices) {
+		return fmt.Errorf("no input device %d; %s", number, deviceList(devices))
+	}
+	device := devices[number]
+
+	var mu sync.Mutex
+	var events []midi.Event
+	assembler := midi.NewDeviceAssembler(device)
+	stop, err := midi.Listen(device, func(data []byte, timestamp, arrived time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, event := range assembler.Feed(data, timestamp) {
+			event.Arrived = arrived
+			event.Processed = time.Now()
+			events = append(events, event)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Capturing from %s; press Ctrl+C to stop and write %s\n", device.Name, path)
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	var timeout <-chan time.Time
+	if duration > 0 {
+		timeout = time.After(duration)
+	}
+	select {
+	case <-interrupted:
+	case <-timeout:
+	}
+	signal.Stop(interrupted)
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = models.WriteEvents(file, events, models.ExportFormatFor(path))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d events to %s (%s)\n", len(events), path, models.ExportFormatFor(path))
+	return nil
+}
+
+// deviceList names the input devices by number, for choosing one with -device
+func deviceList(devices []midi.Device) string {
+	if len(devices) == 0 {
+		return "no MIDI inputs found"
+	}
+	list := "inputs:"
+	for i, device := range devices {
+		list += fmt.Sprintf("\n  %d: %s", i, device.Name)
+	}
+	return list
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/models"
+	"midi-viewer/internal/ui/theme"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "midi-viewer:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	themeName := flag.String("theme", "dark", "color theme: dark or light")
+	exportPath := flag.String("export", "", "capture without the TUI and write the events to this file (.jsonl, .csv or text)")
+	device := flag.Int("device", 0, "input device number to capture from with -export")
+	duration := flag.Duration("duration", 0, "stop an -export capture after this long (default: on Ctrl+C)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: midi-viewer [flags] [open <capture file>]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *exportPath != "" {
+		return runExport(*device, *exportPath, *duration)
+	}
+
+	var t theme.Theme
+	switch *themeName {
+	case "dark":
+		t = theme.Dark()
+	case "light":
+		t = theme.Light()
+	default:
+		return fmt.Errorf("unknown theme %q: use dark or light", *themeName)
+	}
+
+	switch {
+	case flag.NArg() == 0:
+	case flag.Arg(0) == "open" && flag.NArg() == 2:
+		return runOpen(flag.Arg(1), t)
+	default:
+		flag.Usage()
+		return fmt.Errorf("unexpected arguments %q", flag.Args())
+	}
+
+	cleanup, err := midi.InitDriver()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var program *tea.Program
+	program = tea.NewProgram(newApp(t, func(msg tea.Msg) { program.Send(msg) }), tea.WithAltScreen())
+	final, err := program.Run()
+	if a, ok := final.(app); ok {
+		a.stopListening()
+	}
+	return err
+}
+
+// runOpen shows a saved capture in the event viewer. The MIDI driver is never initialised,
+// so this works without RtMidi or any MIDI hardware.
+func runOpen(path string, t theme.Theme) error {
+	events, err := models.LoadCapture(path)
+	if err != nil {
+		return err
+	}
+	_, err = tea.NewProgram(newOfflineApp(t, filepath.Base(path), events), tea.WithAltScreen()).Run()
+	return err
+}
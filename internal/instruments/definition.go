@@ -0,0 +1,135 @@
+package instruments
+
+import (
+	"strings"
+)
+
+// Definition describes the names a device uses for its controllers, NRPNs, programs and drum notes
+type Definition struct {
+	Name         string         `json:"name" yaml:"name"`
+	BasedOn      string         `json:"based_on,omitempty" yaml:"based_on,omitempty"`           // definition to inherit names from
+	Devices      []string       `json:"devices,omitempty" yaml:"devices,omitempty"`             // device name substrings (empty = every device)
+	Channels     []int          `json:"channels,omitempty" yaml:"channels,omitempty"`           // channels 1-16 (empty = every channel)
+	Controllers  map[int]string `json:"controllers,omitempty" yaml:"controllers,omitempty"`     // CC number -> name
+	NRPNs        map[int]string `json:"nrpns,omitempty" yaml:"nrpns,omitempty"`                 // 14-bit NRPN number -> name
+	Banks        []Bank         `json:"banks,omitempty" yaml:"banks,omitempty"`                 // program names per bank
+	Drums        map[int]string `json:"drums,omitempty" yaml:"drums,omitempty"`                 // note number -> drum name
+	DrumChannels []int          `json:"drum_channels,omitempty" yaml:"drum_channels,omitempty"` // channels 1-16 using drum names (empty = 10)
+}
+
+// Bank names the programs selected by a bank select MSB/LSB pair
+type Bank struct {
+	Name     string         `json:"name,omitempty" yaml:"name,omitempty"`
+	MSB      *int           `json:"msb,omitempty" yaml:"msb,omitempty"` // nil matches any MSB
+	LSB      *int           `json:"lsb,omitempty" yaml:"lsb,omitempty"` // nil matches any LSB
+	Programs map[int]string `json:"programs" yaml:"programs"`           // program number (0-127) -> name
+}
+
+// matchesDevice returns true if the definition is bound to the named device
+func (d Definition) matchesDevice(device string) bool {
+	if len(d.Devices) == 0 {
+		return true
+	}
+
+	device = strings.ToLower(device)
+	for _, pattern := range d.Devices {
+		if strings.Contains(device, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesChannel returns true if the definition applies to a zero-based channel
+func (d Definition) matchesChannel(ch uint8) bool {
+	return len(d.Channels) == 0 || containsChannel(d.Channels, ch)
+}
+
+// isDrumChannel returns true if drum names apply to a zero-based channel
+func (d Definition) isDrumChannel(ch uint8) bool {
+	if len(d.DrumChannels) == 0 {
+		return ch == 9
+	}
+	return containsChannel(d.DrumChannels, ch)
+}
+
+func containsChannel(channels []int, ch uint8) bool {
+	for _, c := range channels {
+		if c == int(ch)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// programName returns the name of a program in the given bank, or an empty string
+func (d Definition) programName(msb, lsb, program uint8) string {
+	for _, bank := range d.Banks {
+		if bank.MSB != nil && *bank.MSB != int(msb) {
+			continue
+		}
+		if bank.LSB != nil && *bank.LSB != int(lsb) {
+			continue
+		}
+		if name, ok := bank.Programs[int(program)]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// merge fills in names from base that the definition does not define itself
+func (d Definition) merge(base Definition) Definition {
+	d.Controllers = mergeNames(base.Controllers, d.Controllers)
+	d.NRPNs = mergeNames(base.NRPNs, d.NRPNs)
+	d.Drums = mergeNames(base.Drums, d.Drums)
+	d.Banks = append(append([]Bank(nil), d.Banks...), base.Banks...)
+	if len(d.Channels) == 0 {
+		d.Channels = base.Channels
+	}
+	if len(d.DrumChannels) == 0 {
+		d.DrumChannels = base.DrumChannels
+	}
+	return d
+}
+
+func mergeNames(base, override map[int]string) map[int]string {
+	if len(base) == 0 {
+		return override
+	}
+
+	merged := make(map[int]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// resolveBasedOn merges every definition with the definition it is based on, following chains
+func resolveBasedOn(defs []Definition) []Definition {
+	byName := make(map[string]Definition, len(defs))
+	for _, def := range defs {
+		byName[def.Name] = def
+	}
+
+	resolved := make([]Definition, len(defs))
+	for i, def := range defs {
+		seen := map[string]bool{def.Name: true}
+		for base := def.BasedOn; base != "" && !seen[base]; {
+			seen[base] = true
+			parent, ok := byName[base]
+			if !ok {
+				break
+			}
+			def = def.merge(parent)
+			base = parent.BasedOn
+		}
+		resolved[i] = def
+	}
+
+	return resolved
+}
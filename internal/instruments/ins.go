@@ -0,0 +1,180 @@
+package instruments
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// insFile holds the named lists and instrument sections of a Cakewalk .ins file
+type insFile struct {
+	lists       map[string]map[string]map[int]string // section -> list name -> number -> name
+	basedOn     map[string]map[string]string         // section -> list name -> base list name
+	instruments []insInstrument
+}
+
+type insInstrument struct {
+	name string
+	keys map[string]string // raw key -> value, e.g. "Patch[0]" -> "Bank A"
+	// order keeps Patch[...] keys in file order so earlier banks win
+	order []string
+}
+
+// ParseINS parses the instrument definitions in a Cakewalk .ins file.
+// Each instrument becomes a definition bound to devices whose name contains the instrument name.
+func ParseINS(r io.Reader) ([]Definition, error) {
+	file := insFile{
+		lists:   make(map[string]map[string]map[int]string),
+		basedOn: make(map[string]map[string]string),
+	}
+
+	var section, list string
+	var current *insInstrument
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "."):
+			section = strings.TrimSpace(line[1:])
+			list = ""
+			current = nil
+			if file.lists[section] == nil {
+				file.lists[section] = make(map[string]map[int]string)
+				file.basedOn[section] = make(map[string]string)
+			}
+
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			list = line[1 : len(line)-1]
+			if section == "Instrument Definitions" {
+				file.instruments = append(file.instruments, insInstrument{name: list, keys: make(map[string]string)})
+				current = &file.instruments[len(file.instruments)-1]
+			} else if section != "" {
+				file.lists[section][list] = make(map[int]string)
+			}
+
+		default:
+			eq := strings.Index(line, "=")
+			if eq < 0 || list == "" {
+				return nil, fmt.Errorf("line %d: unexpected %q", lineNo, line)
+			}
+			key, value := strings.TrimSpace(line[:eq]), strings.TrimSpace(line[eq+1:])
+
+			if current != nil {
+				current.keys[key] = value
+				current.order = append(current.order, key)
+				continue
+			}
+
+			if key == "BasedOn" {
+				file.basedOn[section][list] = value
+				continue
+			}
+
+			number, err := strconv.Atoi(key)
+			if err != nil {
+				// Lists may carry other settings; only numbered entries are names
+				continue
+			}
+			file.lists[section][list][number] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	defs := make([]Definition, 0, len(file.instruments))
+	for _, inst := range file.instruments {
+		defs = append(defs, file.definition(inst))
+	}
+
+	return defs, nil
+}
+
+// list returns a named list with the lists it is based on merged underneath
+func (f insFile) list(section, name string) map[int]string {
+	seen := make(map[string]bool)
+	var chain []map[int]string
+	for name != "" && !seen[name] {
+		seen[name] = true
+		entries, ok := f.lists[section][name]
+		if !ok {
+			break
+		}
+		chain = append(chain, entries)
+		name = f.basedOn[section][name]
+	}
+
+	merged := make(map[int]string)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for k, v := range chain[i] {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func (f insFile) definition(inst insInstrument) Definition {
+	def := Definition{
+		Name:    inst.name,
+		Devices: []string{inst.name},
+	}
+
+	if name, ok := inst.keys["Control"]; ok {
+		def.Controllers = f.list("Controller Names", name)
+	}
+	if name, ok := inst.keys["NRPN"]; ok {
+		def.NRPNs = f.list("NRPN Names", name)
+	}
+
+	method, _ := strconv.Atoi(inst.keys["BankSelMethod"])
+
+	hasDrums := false
+	for _, key := range inst.order {
+		if strings.HasPrefix(key, "Drum[") && inst.keys[key] == "1" {
+			hasDrums = true
+		}
+	}
+
+	for _, key := range inst.order {
+		switch {
+		case strings.HasPrefix(key, "Patch[") && strings.HasSuffix(key, "]"):
+			bank := Bank{
+				Name:     inst.keys[key],
+				Programs: f.list("Patch Names", inst.keys[key]),
+			}
+			if number, err := strconv.Atoi(key[len("Patch[") : len(key)-1]); err == nil {
+				bank.MSB, bank.LSB = bankSelect(number, method)
+			}
+			def.Banks = append(def.Banks, bank)
+
+		case strings.HasPrefix(key, "Key[") && hasDrums && def.Drums == nil:
+			def.Drums = f.list("Note Names", inst.keys[key])
+		}
+	}
+
+	return def
+}
+
+// bankSelect splits a .ins bank number into the MSB/LSB it matches for a BankSelMethod
+func bankSelect(number, method int) (msb, lsb *int) {
+	switch method {
+	case 1: // MSB only
+		return &number, nil
+	case 2: // LSB only
+		return nil, &number
+	case 3: // program change only
+		return nil, nil
+	default: // MSB and LSB
+		m, l := number>>7, number&0x7F
+		return &m, &l
+	}
+}
@@ -0,0 +1,109 @@
+package instruments
+
+import (
+	"strings"
+	"testing"
+)
+
+const testINS = `; Test instrument file
+.Patch Names
+
+[Rev2 U1]
+0=Bright Lead
+1=Warm Pad
+
+.Note Names
+
+[Rev2 Drums]
+36=Kick 1
+38=Snare 1
+
+.Controller Names
+
+[Standard]
+1=Modulation
+7=Volume
+
+[Rev2 Controllers]
+BasedOn=Standard
+74=Filter Cutoff
+
+.NRPN Names
+
+[Rev2 NRPN]
+133=Osc 1 Shape
+
+.Instrument Definitions
+
+[Prophet Rev2]
+Control=Rev2 Controllers
+NRPN=Rev2 NRPN
+Patch[0]=Rev2 U1
+
+[Rev2 Drum Mode]
+BankSelMethod=1
+Patch[*]=Rev2 U1
+Key[*,*]=Rev2 Drums
+Drum[*,*]=1
+`
+
+func TestParseINS(t *testing.T) {
+	defs, err := ParseINS(strings.NewReader(testINS))
+	if err != nil {
+		t.Fatalf("ParseINS() error = %v", err)
+	}
+	if len(defs) != 2 {
+		t.Fatalf("ParseINS() returned %d definitions; want 2", len(defs))
+	}
+
+	rev2 := defs[0]
+	if rev2.Name != "Prophet Rev2" || len(rev2.Devices) != 1 || rev2.Devices[0] != "Prophet Rev2" {
+		t.Errorf("definition name/devices = %q/%v", rev2.Name, rev2.Devices)
+	}
+	if rev2.Controllers[74] != "Filter Cutoff" || rev2.Controllers[7] != "Volume" {
+		t.Errorf("Controllers = %v; want own and BasedOn names", rev2.Controllers)
+	}
+	if rev2.NRPNs[133] != "Osc 1 Shape" {
+		t.Errorf("NRPNs = %v", rev2.NRPNs)
+	}
+	if len(rev2.Banks) != 1 || *rev2.Banks[0].MSB != 0 || *rev2.Banks[0].LSB != 0 {
+		t.Fatalf("Banks = %+v; want one bank 0/0", rev2.Banks)
+	}
+	if rev2.Banks[0].Programs[1] != "Warm Pad" {
+		t.Errorf("Banks[0].Programs = %v", rev2.Banks[0].Programs)
+	}
+	if rev2.Drums != nil {
+		t.Errorf("Drums = %v; want none for a melodic instrument", rev2.Drums)
+	}
+
+	drums := defs[1]
+	if drums.Drums[36] != "Kick 1" {
+		t.Errorf("Drums = %v; want Kick 1 on 36", drums.Drums)
+	}
+	if drums.Banks[0].MSB != nil || drums.Banks[0].LSB != nil {
+		t.Errorf("Patch[*] bank should match any bank, got %+v", drums.Banks[0])
+	}
+}
+
+func TestBankSelect(t *testing.T) {
+	msb, lsb := bankSelect(130, 0)
+	if *msb != 1 || *lsb != 2 {
+		t.Errorf("bankSelect(130, 0) = %d/%d; want 1/2", *msb, *lsb)
+	}
+
+	msb, lsb = bankSelect(5, 1)
+	if *msb != 5 || lsb != nil {
+		t.Errorf("bankSelect(5, 1) = %v/%v; want MSB 5 only", msb, lsb)
+	}
+
+	msb, lsb = bankSelect(5, 2)
+	if msb != nil || *lsb != 5 {
+		t.Errorf("bankSelect(5, 2) = %v/%v; want LSB 5 only", msb, lsb)
+	}
+}
+
+func TestParseINSError(t *testing.T) {
+	if _, err := ParseINS(strings.NewReader(".Patch Names\n[List]\nnot a name\n")); err == nil {
+		t.Error("ParseINS() should reject lines without '='")
+	}
+}
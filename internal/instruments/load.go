@@ -0,0 +1,122 @@
+package instruments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDir returns the directory instrument definitions are loaded from by default
+func DefaultDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find config directory: %w", err)
+	}
+	return filepath.Join(configDir, "midi-viewer", "instruments"), nil
+}
+
+// LoadDir loads every definition file in a directory. A missing directory yields no definitions.
+func LoadDir(dir string) ([]Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read instrument directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && isDefinitionFile(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var defs []Definition
+	for _, name := range names {
+		fileDefs, err := LoadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, fileDefs...)
+	}
+
+	return defs, nil
+}
+
+func isDefinitionFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml", ".ins":
+		return true
+	}
+	return false
+}
+
+// LoadFile loads the definitions in a JSON, YAML or Cakewalk .ins file.
+// JSON and YAML files hold either a single definition or a list of them.
+func LoadFile(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read instrument file: %w", err)
+	}
+
+	var defs []Definition
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		defs, err = parseJSON(data)
+	case ".yaml", ".yml":
+		defs, err = parseYAML(data)
+	case ".ins":
+		defs, err = ParseINS(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported instrument file %q", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", filepath.Base(path), err)
+	}
+
+	return defs, nil
+}
+
+func parseJSON(data []byte) ([]Definition, error) {
+	var defs []Definition
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err := json.Unmarshal(data, &defs)
+		return defs, err
+	}
+
+	var def Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, err
+	}
+	return []Definition{def}, nil
+}
+
+func parseYAML(data []byte) ([]Definition, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	if node.Content[0].Kind == yaml.SequenceNode {
+		var defs []Definition
+		err := node.Decode(&defs)
+		return defs, err
+	}
+
+	var def Definition
+	if err := node.Decode(&def); err != nil {
+		return nil, err
+	}
+	return []Definition{def}, nil
+}
@@ -0,0 +1,78 @@
+package instruments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "prophet.yaml", `
+name: Prophet
+devices: [Prophet]
+controllers:
+  74: Filter Cutoff
+banks:
+  - name: U1
+    msb: 0
+    lsb: 0
+    programs:
+      0: Bright Lead
+`)
+	writeFile(t, dir, "synths.json", `[
+  {"name": "Juno", "controllers": {"74": "VCF Freq"}},
+  {"name": "TR", "drums": {"36": "Kick 1"}, "drum_channels": [10, 11]}
+]`)
+	writeFile(t, dir, "notes.txt", "ignored")
+
+	defs, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if len(defs) != 3 {
+		t.Fatalf("LoadDir() returned %d definitions; want 3", len(defs))
+	}
+
+	byName := make(map[string]Definition)
+	for _, def := range defs {
+		byName[def.Name] = def
+	}
+
+	if byName["Prophet"].Controllers[74] != "Filter Cutoff" {
+		t.Errorf("YAML controllers = %v", byName["Prophet"].Controllers)
+	}
+	if byName["Prophet"].Banks[0].Programs[0] != "Bright Lead" {
+		t.Errorf("YAML banks = %+v", byName["Prophet"].Banks)
+	}
+	if byName["Juno"].Controllers[74] != "VCF Freq" {
+		t.Errorf("JSON controllers = %v", byName["Juno"].Controllers)
+	}
+	if len(byName["TR"].DrumChannels) != 2 {
+		t.Errorf("JSON drum channels = %v", byName["TR"].DrumChannels)
+	}
+}
+
+func TestLoadDirMissing(t *testing.T) {
+	defs, err := LoadDir(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || defs != nil {
+		t.Errorf("LoadDir(missing) = %v, %v; want nil, nil", defs, err)
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "broken.json", `{"name": `)
+
+	if _, err := LoadFile(filepath.Join(dir, "broken.json")); err == nil {
+		t.Error("LoadFile() should fail on invalid JSON")
+	}
+}
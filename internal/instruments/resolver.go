@@ -0,0 +1,165 @@
+package instruments
+
+import (
+	"fmt"
+
+	"midi-viewer/internal/midi"
+)
+
+// Resolver names events using the definitions bound to one device.
+// It follows bank select and NRPN selection per channel, so events must be resolved in order.
+type Resolver struct {
+	defs    []Definition
+	bankMSB map[uint8]uint8
+	bankLSB map[uint8]uint8
+	nrpnMSB map[uint8]uint8
+	nrpnLSB map[uint8]uint8
+	nrpnSel map[uint8]bool // channel -> an NRPN (not an RPN) is currently selected
+}
+
+// NewResolver creates a resolver for the definitions bound to the named device
+func NewResolver(defs []Definition, device string) *Resolver {
+	r := &Resolver{
+		bankMSB: make(map[uint8]uint8),
+		bankLSB: make(map[uint8]uint8),
+		nrpnMSB: make(map[uint8]uint8),
+		nrpnLSB: make(map[uint8]uint8),
+		nrpnSel: make(map[uint8]bool),
+	}
+
+	for _, def := range resolveBasedOn(defs) {
+		if def.matchesDevice(device) {
+			r.defs = append(r.defs, def)
+		}
+	}
+
+	return r
+}
+
+// Definitions returns the names of the definitions bound to the device
+func (r *Resolver) Definitions() []string {
+	names := make([]string, len(r.defs))
+	for i, def := range r.defs {
+		names[i] = def.Name
+	}
+	return names
+}
+
+// Resolve updates the per-channel bank and NRPN state from an event and returns its device-specific names
+func (r *Resolver) Resolve(event midi.Event) midi.Labels {
+	var labels midi.Labels
+	if r == nil {
+		return labels
+	}
+
+	var ch, key, vel, controller, value, program, pressure uint8
+
+	switch {
+	case event.Message.GetNoteOn(&ch, &key, &vel),
+		event.Message.GetNoteOff(&ch, &key, &vel),
+		event.Message.GetPolyAfterTouch(&ch, &key, &pressure):
+		labels.Note = r.DrumName(ch, key)
+
+	case event.Message.GetControlChange(&ch, &controller, &value):
+		r.trackController(ch, controller, value)
+		labels.Controller = r.ControllerName(ch, controller)
+
+		switch controller {
+		case 6, 38, 96, 97, 98, 99:
+			// Data entry and NRPN select messages are named after the selected NRPN
+			if name := r.selectedNRPNName(ch); name != "" {
+				labels.Controller = fmt.Sprintf("NRPN %s", name)
+			}
+		}
+
+	case event.Message.GetProgramChange(&ch, &program):
+		labels.Program = r.ProgramName(ch, program)
+	}
+
+	return labels
+}
+
+func (r *Resolver) trackController(ch, controller, value uint8) {
+	switch controller {
+	case 0:
+		r.bankMSB[ch] = value
+	case 32:
+		r.bankLSB[ch] = value
+	case 99:
+		r.nrpnMSB[ch] = value
+		r.nrpnSel[ch] = true
+	case 98:
+		r.nrpnLSB[ch] = value
+		r.nrpnSel[ch] = true
+	case 100, 101:
+		r.nrpnSel[ch] = false
+	}
+}
+
+func (r *Resolver) selectedNRPNName(ch uint8) string {
+	if !r.nrpnSel[ch] {
+		return ""
+	}
+
+	number := int(r.nrpnMSB[ch])<<7 | int(r.nrpnLSB[ch])
+	for _, def := range r.defs {
+		if !def.matchesChannel(ch) {
+			continue
+		}
+		if name, ok := def.NRPNs[number]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// ControllerName returns the device-specific name of a controller, or an empty string
+func (r *Resolver) ControllerName(ch, controller uint8) string {
+	if r == nil {
+		return ""
+	}
+
+	for _, def := range r.defs {
+		if !def.matchesChannel(ch) {
+			continue
+		}
+		if name, ok := def.Controllers[int(controller)]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// ProgramName returns the device-specific name of a program in the channel's current bank, or an empty string
+func (r *Resolver) ProgramName(ch, program uint8) string {
+	if r == nil {
+		return ""
+	}
+
+	for _, def := range r.defs {
+		if !def.matchesChannel(ch) {
+			continue
+		}
+		if name := def.programName(r.bankMSB[ch], r.bankLSB[ch], program); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// DrumName returns the device-specific drum name of a note, or an empty string
+func (r *Resolver) DrumName(ch, key uint8) string {
+	if r == nil {
+		return ""
+	}
+
+	for _, def := range r.defs {
+		if !def.matchesChannel(ch) || !def.isDrumChannel(ch) {
+			continue
+		}
+		if name, ok := def.Drums[int(key)]; ok {
+			return name
+		}
+	}
+	return ""
+}
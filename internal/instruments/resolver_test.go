@@ -0,0 +1,122 @@
+package instruments
+
+import (
+	"testing"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+func intPtr(v int) *int { return &v }
+
+var prophet = Definition{
+	Name:        "Prophet",
+	Devices:     []string{"prophet"},
+	Controllers: map[int]string{74: "Filter Cutoff"},
+	NRPNs:       map[int]string{(1 << 7) | 5: "Osc 1 Shape"},
+	Banks: []Bank{
+		{Name: "U1", MSB: intPtr(0), LSB: intPtr(0), Programs: map[int]string{0: "Bright Lead"}},
+		{Name: "U2", MSB: intPtr(0), LSB: intPtr(1), Programs: map[int]string{0: "Warm Pad"}},
+	},
+}
+
+func resolve(r *Resolver, msg gomidi.Message) midi.Labels {
+	return r.Resolve(midi.ParseMessage(msg))
+}
+
+func TestResolverControllerNames(t *testing.T) {
+	r := NewResolver([]Definition{prophet}, "Prophet Rev2 MIDI")
+
+	if got := resolve(r, gomidi.ControlChange(0, 74, 10)).Controller; got != "Filter Cutoff" {
+		t.Errorf("CC 74 controller label = %q; want Filter Cutoff", got)
+	}
+	if got := resolve(r, gomidi.ControlChange(0, 7, 10)).Controller; got != "" {
+		t.Errorf("CC 7 controller label = %q; want empty", got)
+	}
+}
+
+func TestResolverDeviceBinding(t *testing.T) {
+	r := NewResolver([]Definition{prophet}, "Some Other Synth")
+
+	if got := resolve(r, gomidi.ControlChange(0, 74, 10)).Controller; got != "" {
+		t.Errorf("unbound device controller label = %q; want empty", got)
+	}
+	if len(r.Definitions()) != 0 {
+		t.Errorf("Definitions() = %v; want none", r.Definitions())
+	}
+}
+
+func TestResolverBankSelect(t *testing.T) {
+	r := NewResolver([]Definition{prophet}, "Prophet")
+
+	if got := resolve(r, gomidi.ProgramChange(0, 0)).Program; got != "Bright Lead" {
+		t.Errorf("program in bank 0/0 = %q; want Bright Lead", got)
+	}
+
+	resolve(r, gomidi.ControlChange(0, 0, 0))
+	resolve(r, gomidi.ControlChange(0, 32, 1))
+	if got := resolve(r, gomidi.ProgramChange(0, 0)).Program; got != "Warm Pad" {
+		t.Errorf("program in bank 0/1 = %q; want Warm Pad", got)
+	}
+
+	// Bank selection is per channel
+	if got := resolve(r, gomidi.ProgramChange(1, 0)).Program; got != "Bright Lead" {
+		t.Errorf("program on channel 2 = %q; want Bright Lead", got)
+	}
+}
+
+func TestResolverNRPN(t *testing.T) {
+	r := NewResolver([]Definition{prophet}, "Prophet")
+
+	resolve(r, gomidi.ControlChange(0, 99, 1))
+	resolve(r, gomidi.ControlChange(0, 98, 5))
+	if got := resolve(r, gomidi.ControlChange(0, 6, 64)).Controller; got != "NRPN Osc 1 Shape" {
+		t.Errorf("data entry label = %q; want NRPN Osc 1 Shape", got)
+	}
+
+	// Selecting an RPN stops data entry being named after the NRPN
+	resolve(r, gomidi.ControlChange(0, 101, 0))
+	if got := resolve(r, gomidi.ControlChange(0, 6, 64)).Controller; got != "" {
+		t.Errorf("data entry label after RPN select = %q; want empty", got)
+	}
+}
+
+func TestResolverDrums(t *testing.T) {
+	kit := Definition{Name: "Drum Machine", Drums: map[int]string{36: "Kick 1"}}
+	r := NewResolver([]Definition{kit}, "Anything")
+
+	if got := resolve(r, gomidi.NoteOn(9, 36, 100)).Note; got != "Kick 1" {
+		t.Errorf("drum note on channel 10 = %q; want Kick 1", got)
+	}
+	if got := resolve(r, gomidi.NoteOn(0, 36, 100)).Note; got != "" {
+		t.Errorf("note on channel 1 = %q; want empty", got)
+	}
+}
+
+func TestResolverBasedOn(t *testing.T) {
+	binding := Definition{
+		Name:        "Studio Prophet",
+		BasedOn:     "Prophet",
+		Devices:     []string{"USB MIDI Interface 3"},
+		Channels:    []int{2},
+		Controllers: map[int]string{1: "Vibrato"},
+	}
+	r := NewResolver([]Definition{prophet, binding}, "USB MIDI Interface 3")
+
+	if got := resolve(r, gomidi.ControlChange(1, 74, 0)).Controller; got != "Filter Cutoff" {
+		t.Errorf("inherited controller label = %q; want Filter Cutoff", got)
+	}
+	if got := resolve(r, gomidi.ControlChange(1, 1, 0)).Controller; got != "Vibrato" {
+		t.Errorf("own controller label = %q; want Vibrato", got)
+	}
+	if got := resolve(r, gomidi.ControlChange(0, 74, 0)).Controller; got != "" {
+		t.Errorf("controller label on unbound channel = %q; want empty", got)
+	}
+}
+
+func TestNilResolver(t *testing.T) {
+	var r *Resolver
+	if got := resolve(r, gomidi.ControlChange(0, 74, 10)); got != (midi.Labels{}) {
+		t.Errorf("nil Resolve() = %+v; want empty labels", got)
+	}
+}
@@ -0,0 +1,54 @@
+package midi
+
+import "time"
+
+// Assembler reassembles the bytes delivered by driver callbacks into events.
+// SysEx may arrive in fragments spread over several callbacks and interleaved with
+// realtime bytes; other messages must be complete within one callback.
+// Malformed input becomes events of type ErrorMessageType explaining the problem.
+//
+// A stream assembler instead takes a raw byte stream, such as a serial port's, where any
+// message may span callbacks and running status carries over from one to the next.
+type Assembler struct {
+	parser Parser
+	stream bool
+}
+
+// NewAssembler creates an assembler with no partial message
+func NewAssembler() Assembler {
+	return Assembler{parser: NewParser()}
+}
+
+// NewStreamAssembler creates an assembler for a raw byte stream
+func NewStreamAssembler() Assembler {
+	return Assembler{parser: NewParser(), stream: true}
+}
+
+// NewDeviceAssembler creates an assembler for the bytes Listen delivers from a device:
+// a stream assembler for a serial port, which delivers its byte stream as read
+func NewDeviceAssembler(device Device) Assembler {
+	if _, ok := device.Port.(*SerialIn); ok {
+		return NewStreamAssembler()
+	}
+	return NewAssembler()
+}
+
+// Feed processes the bytes of one driver callback received at the given time
+// and returns the complete messages and errors found in them
+func (a *Assembler) Feed(data []byte, at time.Time) []Event {
+	events := a.parser.Feed(data, at)
+	if a.stream {
+		return events
+	}
+
+	// Drivers deliver whole messages, so only SysEx carries over to the next callback
+	events = append(events, a.parser.flushMessage(at)...)
+	a.parser.running = 0
+
+	return events
+}
+
+// Reset discards any partial SysEx
+func (a *Assembler) Reset() {
+	a.parser.Reset()
+}
@@ -0,0 +1,155 @@
+package midi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// summarise returns "Type" or "Type: explanation" for each event
+func summarise(events []Event) []string {
+	out := make([]string, len(events))
+	for i, event := range events {
+		out[i] = event.MessageType
+		if event.MessageType == ErrorMessageType {
+			out[i] += ": " + event.Data
+		}
+	}
+	return out
+}
+
+func TestAssemblerCompleteMessages(t *testing.T) {
+	a := NewAssembler()
+	at := time.Now()
+
+	events := a.Feed([]byte{0x90, 0x3C, 0x64, 0xB0, 0x07, 0x64, 0xF8}, at)
+	got := summarise(events)
+	want := []string{"Note On", "CC", "Clock"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("Feed() = %v; want %v", got, want)
+	}
+	if !events[0].Timestamp.Equal(at) {
+		t.Errorf("Feed() Timestamp = %v; want %v", events[0].Timestamp, at)
+	}
+}
+
+func TestStreamAssemblerRunningStatus(t *testing.T) {
+	a := NewStreamAssembler()
+	at := time.Now()
+
+	// A note and a running status note split across reads
+	var events []Event
+	for _, data := range [][]byte{{0x90, 0x3C}, {0x64, 0x3E}, {0x64}} {
+		events = append(events, a.Feed(data, at)...)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Feed() = %v; want 2 notes", summarise(events))
+	}
+	if events[0].RunningStatus || !events[1].RunningStatus {
+		t.Errorf("RunningStatus = %v, %v; want false, true", events[0].RunningStatus, events[1].RunningStatus)
+	}
+	if !bytes.Equal(events[1].RawBytes, []byte{0x90, 0x3E, 0x64}) {
+		t.Errorf("RawBytes = % X; want 90 3E 64", events[1].RawBytes)
+	}
+}
+
+func TestNewDeviceAssembler(t *testing.T) {
+	if a := NewDeviceAssembler(NewSerialDevice("/dev/ttyUSB0", DefaultSerialBaud, 0)); !a.stream {
+		t.Error("NewDeviceAssembler(serial device) should assemble a stream")
+	}
+	if a := NewDeviceAssembler(Device{Name: "Keyboard"}); a.stream {
+		t.Error("NewDeviceAssembler(driver device) should assemble driver callbacks")
+	}
+}
+
+func TestAssemblerSysExFragments(t *testing.T) {
+	a := NewAssembler()
+	at := time.Now()
+
+	if events := a.Feed([]byte{0xF0, 0x41, 0x10}, at); len(events) != 0 {
+		t.Fatalf("first fragment produced %v", summarise(events))
+	}
+	// A clock byte in the middle of the SysEx is delivered on its own
+	events := a.Feed([]byte{0x42, 0xF8, 0x12}, at)
+	if got := summarise(events); len(got) != 1 || got[0] != "Clock" {
+		t.Fatalf("second fragment produced %v; want [Clock]", got)
+	}
+	events = a.Feed([]byte{0x40, 0x00, 0x7F, 0x00, 0x41, 0xF7}, at)
+	if len(events) != 1 || events[0].MessageType != "SysEx" {
+		t.Fatalf("last fragment produced %v; want [SysEx]", summarise(events))
+	}
+
+	want := []byte{0xF0, 0x41, 0x10, 0x42, 0x12, 0x40, 0x00, 0x7F, 0x00, 0x41, 0xF7}
+	if !bytes.Equal(events[0].RawBytes, want) {
+		t.Errorf("SysEx RawBytes = % X; want % X", events[0].RawBytes, want)
+	}
+}
+
+func TestAssemblerErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		feeds [][]byte
+		want  []string
+	}{
+		{
+			"missing F7",
+			[][]byte{{0xF0, 0x43, 0x10}, {0x90, 0x3C, 0x64}},
+			[]string{"Error: SysEx missing F7: interrupted by 90 after 3 bytes", "Note On"},
+		},
+		{
+			"SysEx restarted",
+			[][]byte{{0xF0, 0x43, 0xF0, 0x7E, 0xF7}},
+			[]string{"Error: SysEx missing F7: interrupted by F0 after 2 bytes", "SysEx"},
+		},
+		{
+			"stray data bytes",
+			[][]byte{{0x3C, 0x64, 0x80, 0x3C, 0x00}},
+			[]string{"Error: 2 data bytes with no status byte", "Note Off"},
+		},
+		{
+			"truncated at end of callback",
+			[][]byte{{0xB0, 0x07}},
+			[]string{"Error: truncated CC: expected 2 data bytes, got 1"},
+		},
+		{
+			"truncated by another status",
+			[][]byte{{0xC0, 0x90, 0x3C, 0x64}},
+			[]string{"Error: truncated Program Change: expected 1 data byte, got 0", "Note On"},
+		},
+		{
+			"running status does not carry over to the next callback",
+			[][]byte{{0x90, 0x3C, 0x64, 0x3E, 0x64}, {0x40, 0x64}},
+			[]string{"Note On", "Note On", "Error: 2 data bytes with no status byte"},
+		},
+		{
+			"stray F7",
+			[][]byte{{0xF7}},
+			[]string{"Error: End of SysEx (F7) with no SysEx in progress"},
+		},
+		{
+			"undefined status",
+			[][]byte{{0xF4, 0xFD}},
+			[]string{"Error: undefined system common status F4", "Error: undefined realtime status FD"},
+		},
+	}
+
+	for _, tt := range tests {
+		a := NewAssembler()
+		var got []string
+		for _, feed := range tt.feeds {
+			got = append(got, summarise(a.Feed(feed, time.Now()))...)
+		}
+		if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+			t.Errorf("%s: Feed() = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAssemblerErrorKeepsBytes(t *testing.T) {
+	a := NewAssembler()
+	events := a.Feed([]byte{0xB0, 0x07}, time.Now())
+	if len(events) != 1 || !bytes.Equal(events[0].RawBytes, []byte{0xB0, 0x07}) {
+		t.Errorf("Feed() error RawBytes = % X; want B0 07", events[0].RawBytes)
+	}
+}
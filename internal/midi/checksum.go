@@ -0,0 +1,87 @@
+package midi
+
+// ChecksumStatus is the outcome of validating a SysEx message's checksum
+type ChecksumStatus int
+
+const (
+	ChecksumUnknown ChecksumStatus = iota // not a format with a recognisable checksum
+	ChecksumValid
+	ChecksumInvalid
+)
+
+func (s ChecksumStatus) String() string {
+	switch s {
+	case ChecksumValid:
+		return "ok"
+	case ChecksumInvalid:
+		return "BAD"
+	}
+	return "-"
+}
+
+// VerifyChecksum validates the checksum of a complete SysEx message (F0 ... F7)
+// in the Roland DT1 and Yamaha bulk dump formats
+func VerifyChecksum(msg []byte) ChecksumStatus {
+	if len(msg) < 3 || msg[0] != 0xF0 || msg[len(msg)-1] != 0xF7 {
+		return ChecksumUnknown
+	}
+	data := msg[1 : len(msg)-1]
+
+	switch data[0] {
+	case 0x41:
+		return verifyRoland(data[1:])
+	case 0x43:
+		return verifyYamaha(data[1:])
+	}
+	return ChecksumUnknown
+}
+
+// checksumOK reports whether bytes including a trailing 7-bit checksum sum to zero
+func checksumOK(b []byte) ChecksumStatus {
+	sum := 0
+	for _, v := range b {
+		sum += int(v)
+	}
+	if sum&0x7F == 0 {
+		return ChecksumValid
+	}
+	return ChecksumInvalid
+}
+
+// verifyRoland checks a DT1 body starting at the device ID: the checksum covers address and data
+func verifyRoland(body []byte) ChecksumStatus {
+	if len(body) < 3 {
+		return ChecksumUnknown
+	}
+
+	modelLen := rolandModelLength(body[1:])
+	rest := body[1+modelLen:]
+	addrLen := 3
+	if modelLen > 1 {
+		addrLen = 4
+	}
+	if len(rest) < 1+addrLen+1 || rest[0] != 0x12 {
+		return ChecksumUnknown
+	}
+
+	return checksumOK(rest[1:])
+}
+
+// verifyYamaha checks a bulk dump body starting at the device byte: 0n ff bh bl data cs,
+// where bh bl counts the data. DX/TX dumps checksum the data alone; XG dumps (model 4C),
+// whose data starts with an address, also checksum the count.
+func verifyYamaha(body []byte) ChecksumStatus {
+	if len(body) < 5 || body[0]>>4 != 0x0 {
+		return ChecksumUnknown
+	}
+
+	count := uint14(body[3], body[2])
+	if count != len(body)-5 {
+		return ChecksumUnknown
+	}
+
+	if body[1] == 0x4C {
+		return checksumOK(body[2:])
+	}
+	return checksumOK(body[4:])
+}
@@ -0,0 +1,68 @@
+package midi
+
+import "testing"
+
+func TestVerifyChecksum(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  []byte
+		want ChecksumStatus
+	}{
+		{
+			"Roland GS reset",
+			[]byte{0xF0, 0x41, 0x10, 0x42, 0x12, 0x40, 0x00, 0x7F, 0x00, 0x41, 0xF7},
+			ChecksumValid,
+		},
+		{
+			"Roland bad checksum",
+			[]byte{0xF0, 0x41, 0x10, 0x42, 0x12, 0x40, 0x00, 0x7F, 0x00, 0x42, 0xF7},
+			ChecksumInvalid,
+		},
+		{
+			"Roland RQ1 has no data checksum",
+			[]byte{0xF0, 0x41, 0x10, 0x42, 0x11, 0x40, 0x00, 0x7F, 0x00, 0x00, 0x01, 0x40, 0xF7},
+			ChecksumUnknown,
+		},
+		{"DX7 32-voice dump", dx7Dump(0x09, 0x20, 0x00, false), ChecksumValid},
+		{"DX7 1-voice dump", dx7Dump(0x00, 0x01, 0x1B, false), ChecksumValid},
+		{"DX7 dump checksummed with its count", dx7Dump(0x00, 0x01, 0x1B, true), ChecksumInvalid},
+		{
+			"Yamaha XG bulk dump",
+			// count 00 04 covers address 00 00 7E and data 00
+			[]byte{0xF0, 0x43, 0x00, 0x4C, 0x00, 0x04, 0x00, 0x00, 0x7E, 0x00, 0x7E, 0xF7},
+			ChecksumValid,
+		},
+		{
+			"Yamaha parameter change",
+			[]byte{0xF0, 0x43, 0x10, 0x4C, 0x00, 0x00, 0x7E, 0x00, 0xF7},
+			ChecksumUnknown,
+		},
+		{
+			"Universal message",
+			[]byte{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7},
+			ChecksumUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := VerifyChecksum(tt.msg); got != tt.want {
+			t.Errorf("%s: VerifyChecksum() = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// dx7Dump builds a DX7 bulk dump of format ff with the byte count bh bl and patterned data,
+// checksummed over the data or, wrongly, over count and data
+func dx7Dump(format, bh, bl byte, withCount bool) []byte {
+	msg := []byte{0xF0, 0x43, 0x00, format, bh, bl}
+	sum := 0
+	if withCount {
+		sum = int(bh) + int(bl)
+	}
+	for i := 0; i < uint14(bl, bh); i++ {
+		b := byte(i*7) & 0x7F
+		msg = append(msg, b)
+		sum += int(b)
+	}
+	return append(msg, byte(-sum)&0x7F, 0xF7)
+}
@@ -0,0 +1,58 @@
+package midi
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// IdentifyTimeout is how long Identify waits for an Identity Reply
+const IdentifyTimeout = 2 * time.Second
+
+// Identify sends a Universal Identity Request to the device's output and waits for an
+// Identity Reply on its input. The input must not be in use by another listener.
+func Identify(device Device, timeout time.Duration) (IdentityReply, error) {
+	if device.Out == nil {
+		return IdentityReply{}, fmt.Errorf("no MIDI output paired with %q", device.Name)
+	}
+	if device.Port == nil {
+		return IdentityReply{}, fmt.Errorf("no MIDI input for %q", device.Name)
+	}
+
+	if !device.Port.IsOpen() {
+		if err := device.Port.Open(); err != nil {
+			return IdentityReply{}, fmt.Errorf("could not open MIDI input %q: %w", device.Name, err)
+		}
+	}
+
+	replies := make(chan IdentityReply, 1)
+	stop, err := device.Port.Listen(func(msg []byte, _ int32) {
+		var data []byte
+		if !midi.Message(msg).GetSysEx(&data) {
+			return
+		}
+		if reply, ok := ParseIdentityReply(data); ok {
+			select {
+			case replies <- reply:
+			default:
+			}
+		}
+	}, drivers.ListenConfig{SysEx: true})
+	if err != nil {
+		return IdentityReply{}, fmt.Errorf("could not listen on %q: %w", device.Name, err)
+	}
+	defer stop()
+
+	if err := SendMessages(device.Out, midi.SysEx(IdentityRequest())); err != nil {
+		return IdentityReply{}, err
+	}
+
+	select {
+	case reply := <-replies:
+		return reply, nil
+	case <-time.After(timeout):
+		return IdentityReply{}, fmt.Errorf("no identity reply from %q", device.Name)
+	}
+}
@@ -0,0 +1,83 @@
+package midi
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// fakeIn delivers messages to its listener when told to
+type fakeIn struct {
+	open    bool
+	onMsg   func(msg []byte, milliseconds int32)
+	stopped bool
+}
+
+func (f *fakeIn) Open() error             { f.open = true; return nil }
+func (f *fakeIn) Close() error            { f.open = false; return nil }
+func (f *fakeIn) IsOpen() bool            { return f.open }
+func (f *fakeIn) Number() int             { return 0 }
+func (f *fakeIn) String() string          { return "Fake In" }
+func (f *fakeIn) Underlying() interface{} { return nil }
+func (f *fakeIn) Listen(onMsg func(msg []byte, milliseconds int32), config drivers.ListenConfig) (func(), error) {
+	f.onMsg = onMsg
+	return func() { f.stopped = true }, nil
+}
+
+// replyingOut answers an identity request through the paired input
+type replyingOut struct {
+	fakeOut
+	in    *fakeIn
+	reply []byte
+}
+
+func (r *replyingOut) Send(b []byte) error {
+	r.fakeOut.Send(b)
+	if r.reply != nil {
+		r.in.onMsg([]byte{0xF8}, 0) // unrelated realtime traffic is ignored
+		r.in.onMsg(r.reply, 0)
+	}
+	return nil
+}
+
+func TestIdentify(t *testing.T) {
+	in := &fakeIn{}
+	out := &replyingOut{
+		fakeOut: fakeOut{name: "Synth"},
+		in:      in,
+		reply:   []byte{0xF0, 0x7E, 0x10, 0x06, 0x02, 0x41, 0x42, 0x00, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0xF7},
+	}
+
+	reply, err := Identify(Device{Name: "Synth", Port: in, Out: out}, time.Second)
+	if err != nil {
+		t.Fatalf("Identify() error = %v", err)
+	}
+	if ManufacturerName(reply.ManufacturerID) != "Roland" || reply.Family != 0x42 || reply.Model != 0x03 {
+		t.Errorf("Identify() = %s", reply)
+	}
+
+	want := []byte{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7}
+	if len(out.sent) != 1 || !bytes.Equal(out.sent[0], want) {
+		t.Errorf("Identify() sent % X; want % X", out.sent, want)
+	}
+	if !in.stopped {
+		t.Error("Identify() should stop listening")
+	}
+}
+
+func TestIdentifyTimeout(t *testing.T) {
+	in := &fakeIn{}
+	out := &replyingOut{fakeOut: fakeOut{name: "Silent"}, in: in}
+
+	if _, err := Identify(Device{Name: "Silent", Port: in, Out: out}, 10*time.Millisecond); err == nil {
+		t.Error("Identify() should fail when no reply arrives")
+	}
+}
+
+func TestIdentifyNoOutput(t *testing.T) {
+	if _, err := Identify(Device{Name: "Input Only", Port: &fakeIn{}}, time.Second); err == nil {
+		t.Error("Identify() should fail without an output")
+	}
+}
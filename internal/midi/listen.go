@@ -0,0 +1,85 @@
+package midi
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// DriverClock converts driver timestamps, in milliseconds since listening started, to wall-clock
+// times. A callback can only run after the time the driver stamped, so the clock is anchored at
+// the earliest start time implied by any callback; a delayed callback alone never moves it later.
+//
+// Drivers round each callback's delta to whole milliseconds and add those up, so the driver's
+// time can fall steadily behind the real one. When every callback for clockLagWindow arrives
+// more than maxClockLag after its time, the clock is re-anchored at the arrival.
+type DriverClock struct {
+	start       time.Time
+	behindSince time.Time // arrival of the first callback of a run more than maxClockLag behind
+}
+
+// maxClockLag is how far behind its arrival a callback's time may fall before the lag counts
+// as drift rather than the time a callback takes to run
+const maxClockLag = 2 * time.Millisecond
+
+// clockLagWindow is how long callbacks must stay behind before the clock is re-anchored, so a
+// short stall delivering several delayed callbacks keeps the driver's times
+const clockLagWindow = 100 * time.Millisecond
+
+// Time returns the wall-clock time of a driver timestamp delivered to a callback at arrived
+func (c *DriverClock) Time(milliseconds int32, arrived time.Time) time.Time {
+	offset := time.Duration(milliseconds) * time.Millisecond
+	start := arrived.Add(-offset)
+	if c.start.IsZero() || start.Before(c.start) {
+		c.start = start
+	}
+
+	if arrived.Sub(c.start.Add(offset)) <= maxClockLag {
+		c.behindSince = time.Time{}
+	} else if c.behindSince.IsZero() {
+		c.behindSince = arrived
+	} else if arrived.Sub(c.behindSince) >= clockLagWindow {
+		c.start = start // the driver's time has drifted: re-anchor
+		c.behindSince = time.Time{}
+	}
+	return c.start.Add(offset)
+}
+
+// Listen listens on a device's input and calls onData with the bytes of each driver callback,
+// the driver's timestamp for them and the time the callback ran. Both times are taken in the
+// listener goroutine, so they do not include any delay in handling the bytes afterwards.
+// SysEx is delivered as it arrives, possibly in fragments; feed the bytes to an Assembler.
+// A serial port delivers its byte stream as read, with running status, and is timed as it
+// is read; feed its bytes to the assembler NewDeviceAssembler returns.
+func Listen(device Device, onData func(data []byte, timestamp, arrived time.Time)) (func(), error) {
+	if device.Port == nil {
+		return nil, fmt.Errorf("%s has no MIDI input", device.Name)
+	}
+	if !device.Port.IsOpen() {
+		if err := device.Port.Open(); err != nil {
+			return nil, fmt.Errorf("could not open %s: %w", device.Name, err)
+		}
+	}
+
+	if serial, ok := device.Port.(*SerialIn); ok {
+		stop, err := serial.ListenBytes(func(data []byte, at time.Time) {
+			onData(append([]byte(nil), data...), at, at)
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not listen to %s: %w", device.Name, err)
+		}
+		return stop, nil
+	}
+
+	var clock DriverClock
+	stop, err := device.Port.Listen(func(data []byte, milliseconds int32) {
+		arrived := time.Now()
+		onData(append([]byte(nil), data...), clock.Time(milliseconds, arrived), arrived)
+	}, drivers.ListenConfig{SysEx: true, ActiveSense: true, TimeCode: true})
+	if err != nil {
+		return nil, fmt.Errorf("could not listen to %s: %w", device.Name, err)
+	}
+
+	return stop, nil
+}
@@ -0,0 +1,102 @@
+package midi
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDriverClock(t *testing.T) {
+	var clock DriverClock
+	base := time.Now()
+
+	// The first callback anchors the clock
+	if got := clock.Time(100, base.Add(100*time.Millisecond)); !got.Equal(base.Add(100 * time.Millisecond)) {
+		t.Errorf("Time(100) = %s; want base+100ms", got.Sub(base))
+	}
+
+	// A late callback keeps the driver's time rather than the arrival time
+	if got := clock.Time(200, base.Add(250*time.Millisecond)); !got.Equal(base.Add(200 * time.Millisecond)) {
+		t.Errorf("Time(200) arriving late = %s; want base+200ms", got.Sub(base))
+	}
+
+	// A callback arriving sooner than the anchor implies moves the anchor earlier
+	if got := clock.Time(300, base.Add(290*time.Millisecond)); !got.Equal(base.Add(290 * time.Millisecond)) {
+		t.Errorf("Time(300) arriving early = %s; want base+290ms", got.Sub(base))
+	}
+	if got := clock.Time(400, base.Add(500*time.Millisecond)); !got.Equal(base.Add(390 * time.Millisecond)) {
+		t.Errorf("Time(400) after re-anchoring = %s; want base+390ms", got.Sub(base))
+	}
+}
+
+func TestDriverClockDrift(t *testing.T) {
+	var clock DriverClock
+	base := time.Now()
+
+	// MIDI clock at 123 BPM: ticks 20.33ms apart, each delta rounded down to 20ms by the
+	// driver. Without re-anchoring events would be stamped a second early after a minute.
+	tick := 20330 * time.Microsecond
+	var worst time.Duration
+	for i := 0; i < 3000; i++ {
+		arrived := base.Add(time.Duration(i) * tick)
+		got := clock.Time(int32(i*20), arrived)
+		if got.After(arrived) {
+			t.Fatalf("tick %d stamped %s after its arrival", i, got.Sub(arrived))
+		}
+		worst = max(worst, arrived.Sub(got))
+	}
+	// Lag builds up by 0.33ms a tick for at most clockLagWindow past maxClockLag
+	if limit := maxClockLag + 3*time.Millisecond; worst > limit {
+		t.Errorf("drifting timestamps fell %s behind arrival; want at most %s", worst, limit)
+	}
+}
+
+func TestListen(t *testing.T) {
+	in := &fakeIn{}
+
+	type received struct {
+		data               []byte
+		timestamp, arrived time.Time
+	}
+	var got []received
+
+	before := time.Now()
+	stop, err := Listen(Device{Name: "Fake", Port: in}, func(data []byte, timestamp, arrived time.Time) {
+		got = append(got, received{data, timestamp, arrived})
+	})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	if !in.open {
+		t.Error("Listen() should open the port")
+	}
+
+	buf := []byte{0x90, 60, 100}
+	in.onMsg(buf, 0)
+	buf[0] = 0x80 // the driver may reuse its buffer
+	in.onMsg([]byte{0xF8}, 5)
+	after := time.Now()
+	stop()
+
+	if len(got) != 2 {
+		t.Fatalf("Listen() delivered %d callbacks; want 2", len(got))
+	}
+	if !bytes.Equal(got[0].data, []byte{0x90, 60, 100}) {
+		t.Errorf("data = % X; want a copy of the callback's bytes", got[0].data)
+	}
+	for i, r := range got {
+		if r.arrived.Before(before) || r.arrived.After(after) {
+			t.Errorf("callback %d arrived outside the test", i)
+		}
+		if r.timestamp.After(r.arrived) {
+			t.Errorf("callback %d timestamp %s is after its arrival %s", i, r.timestamp, r.arrived)
+		}
+	}
+	if !in.stopped {
+		t.Error("stop should stop listening")
+	}
+
+	if _, err := Listen(Device{Name: "None"}, nil); err == nil {
+		t.Error("Listen() without an input port should fail")
+	}
+}
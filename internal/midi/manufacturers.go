@@ -0,0 +1,380 @@
+package midi
+
+import "fmt"
+
+// Manufacturer SysEx IDs used for universal messages
+const (
+	NonCommercialID      byte = 0x7D
+	UniversalNonRealtime byte = 0x7E
+	UniversalRealtime    byte = 0x7F
+)
+
+// oneByteManufacturers maps single-byte SysEx manufacturer IDs to names
+var oneByteManufacturers = map[byte]string{
+	0x01: "Sequential Circuits",
+	0x02: "Big Briar",
+	0x03: "Octave/Plateau",
+	0x04: "Moog",
+	0x05: "Passport Designs",
+	0x06: "Lexicon",
+	0x07: "Kurzweil",
+	0x08: "Fender",
+	0x09: "Gulbransen",
+	0x0A: "AKG Acoustics",
+	0x0B: "Voyce Music",
+	0x0C: "Waveframe",
+	0x0D: "ADA Signal Processors",
+	0x0E: "Garfield Electronics",
+	0x0F: "Ensoniq",
+	0x10: "Oberheim",
+	0x11: "Apple",
+	0x12: "Grey Matter Response",
+	0x13: "Digidesign",
+	0x14: "Palmtree Instruments",
+	0x15: "JLCooper Electronics",
+	0x16: "Lowrey Organ",
+	0x17: "Adams-Smith",
+	0x18: "E-mu",
+	0x19: "Harmony Systems",
+	0x1A: "ART",
+	0x1B: "Baldwin",
+	0x1C: "Eventide",
+	0x1D: "Inventronics",
+	0x1E: "Key Concepts",
+	0x1F: "Clarity",
+	0x20: "Passac",
+	0x21: "Proel Labs (SIEL)",
+	0x22: "Synthaxe",
+	0x23: "Stepp",
+	0x24: "Hohner",
+	0x25: "Twister",
+	0x26: "Ketron",
+	0x27: "Jellinghaus MS",
+	0x28: "Southworth Music Systems",
+	0x29: "PPG",
+	0x2A: "JEN",
+	0x2B: "Solid State Logic",
+	0x2C: "Audio Veritrieb-P. Struven",
+	0x2D: "Neve",
+	0x2E: "Soundtracs",
+	0x2F: "Elka",
+	0x30: "Dynacord",
+	0x31: "Viscount",
+	0x32: "Drawmer",
+	0x33: "Clavia",
+	0x34: "Audio Architecture",
+	0x35: "Generalmusic",
+	0x36: "Cheetah Marketing",
+	0x37: "C.T.M.",
+	0x38: "Simmons",
+	0x39: "Soundcraft Electronics",
+	0x3A: "Steinberg",
+	0x3B: "Wersi",
+	0x3C: "AVAB Niethammer",
+	0x3D: "Digigram",
+	0x3E: "Waldorf",
+	0x3F: "Quasimidi",
+	0x40: "Kawai",
+	0x41: "Roland",
+	0x42: "Korg",
+	0x43: "Yamaha",
+	0x44: "Casio",
+	0x46: "Kamiya Studio",
+	0x47: "Akai",
+	0x48: "Victor (JVC)",
+	0x4B: "Fujitsu",
+	0x4C: "Sony",
+	0x4E: "Teac",
+	0x50: "Matsushita Electric",
+	0x51: "Fostex",
+	0x52: "Zoom",
+	0x54: "Matsushita Communication",
+	0x55: "Suzuki",
+	0x56: "Fuji Sound",
+	0x57: "Acoustic Technical Laboratory",
+	0x7D: "Non-Commercial",
+	0x7E: "Universal Non-Realtime",
+	0x7F: "Universal Realtime",
+}
+
+// threeByteManufacturers maps extended (00 xx xx) SysEx manufacturer IDs to names, from the
+// MMA/AMEI manufacturer ID list: 00 00 xx and 00 01 xx are American, 00 20 xx and 00 21 xx
+// European and 00 40 xx Japanese
+var threeByteManufacturers = map[[2]byte]string{
+	{0x00, 0x01}: "Time/Warner Interactive",
+	{0x00, 0x02}: "Advanced Gravis",
+	{0x00, 0x03}: "Media Vision",
+	{0x00, 0x04}: "Dornes Research Group",
+	{0x00, 0x05}: "K-Muse",
+	{0x00, 0x06}: "Stypher",
+	{0x00, 0x07}: "Digital Music Corp",
+	{0x00, 0x08}: "IOTA Systems",
+	{0x00, 0x09}: "New England Digital",
+	{0x00, 0x0A}: "Artisyn",
+	{0x00, 0x0B}: "IVL Technologies",
+	{0x00, 0x0C}: "Southern Music Systems",
+	{0x00, 0x0D}: "Lake Butler Sound Company",
+	{0x00, 0x0E}: "Alesis",
+	{0x00, 0x0F}: "Sound Creation",
+	{0x00, 0x10}: "DOD Electronics",
+	{0x00, 0x11}: "Studer-Editech",
+	{0x00, 0x12}: "Sonus",
+	{0x00, 0x13}: "Temporal Acuity Products",
+	{0x00, 0x14}: "Perfect Fretworks",
+	{0x00, 0x15}: "KAT",
+	{0x00, 0x16}: "Opcode Systems",
+	{0x00, 0x17}: "Rane",
+	{0x00, 0x18}: "Anadi Electronique",
+	{0x00, 0x19}: "KMX",
+	{0x00, 0x1A}: "Allen & Heath Brenell",
+	{0x00, 0x1B}: "Peavey Electronics",
+	{0x00, 0x1C}: "360 Systems",
+	{0x00, 0x1D}: "Spectrum Design and Development",
+	{0x00, 0x1E}: "Marquis Music",
+	{0x00, 0x1F}: "Zeta Systems",
+	{0x00, 0x20}: "Axxes",
+	{0x00, 0x21}: "Orban",
+	{0x00, 0x22}: "Indian Valley Mfg",
+	{0x00, 0x23}: "Intone",
+	{0x00, 0x24}: "Hotz Instruments Technologies",
+	{0x00, 0x3B}: "Mark of the Unicorn",
+	{0x00, 0x41}: "Microsoft",
+	{0x00, 0x66}: "Mackie",
+
+	{0x01, 0x05}: "M-Audio",
+
+	{0x20, 0x00}: "Dream",
+	{0x20, 0x01}: "Strand Lighting",
+	{0x20, 0x02}: "Amek",
+	{0x20, 0x03}: "Casa Di Risparmio Di Loreto",
+	{0x20, 0x04}: "Böhm Electronic",
+	{0x20, 0x05}: "Syntec Digital Audio",
+	{0x20, 0x06}: "Trident Audio Developments",
+	{0x20, 0x07}: "Real World Studio",
+	{0x20, 0x08}: "Evolution Synthesis",
+	{0x20, 0x09}: "Yes Technology",
+	{0x20, 0x0A}: "Audiomatica",
+	{0x20, 0x0B}: "Bontempi (Sigma)",
+	{0x20, 0x0C}: "F.B.T. Elettronica",
+	{0x20, 0x0D}: "MidiTemp",
+	{0x20, 0x0E}: "LA Audio (Larking Audio)",
+	{0x20, 0x0F}: "Zero 88 Lighting",
+	{0x20, 0x10}: "Micon Audio Electronics",
+	{0x20, 0x11}: "Forefront Technology",
+	{0x20, 0x12}: "Studio Audio and Video",
+	{0x20, 0x13}: "Kenton Electronics",
+	{0x20, 0x14}: "Celco/Electrosonic",
+	{0x20, 0x15}: "ADB",
+	{0x20, 0x16}: "Marshall Products",
+	{0x20, 0x17}: "DDA",
+	{0x20, 0x18}: "BSS Audio",
+	{0x20, 0x19}: "MA Lighting Technology",
+	{0x20, 0x1A}: "Fatar",
+	{0x20, 0x1B}: "QSC Audio Products",
+	{0x20, 0x1C}: "Artisan Classic Organ",
+	{0x20, 0x1D}: "Orla",
+	{0x20, 0x1E}: "Pinnacle Audio (Klark Teknik)",
+	{0x20, 0x1F}: "TC Electronic",
+	{0x20, 0x20}: "Doepfer Musikelektronik",
+	{0x20, 0x21}: "Creative ATC/E-mu",
+	{0x20, 0x22}: "Seyddo/Minami",
+	{0x20, 0x23}: "LG Electronics (Goldstar)",
+	{0x20, 0x24}: "Midisoft",
+	{0x20, 0x25}: "Samick Musical Instruments",
+	{0x20, 0x26}: "Penny and Giles",
+	{0x20, 0x27}: "Acorn Computer",
+	{0x20, 0x28}: "LSC Electronics",
+	{0x20, 0x29}: "Focusrite/Novation",
+	{0x20, 0x2A}: "Samkyung Mechatronics",
+	{0x20, 0x2B}: "Medeli Electronics",
+	{0x20, 0x2C}: "Charlie Lab",
+	{0x20, 0x2D}: "Blue Chip Music Technology",
+	{0x20, 0x2E}: "BEE OH Corp",
+	{0x20, 0x2F}: "LG Semicon America",
+	{0x20, 0x30}: "TESI",
+	{0x20, 0x31}: "Emagic",
+	{0x20, 0x32}: "Behringer",
+	{0x20, 0x33}: "Access Music",
+	{0x20, 0x34}: "Synoptic",
+	{0x20, 0x35}: "Hanmesoft",
+	{0x20, 0x36}: "Terratec Electronic",
+	{0x20, 0x37}: "Proel",
+	{0x20, 0x38}: "IBK MIDI",
+	{0x20, 0x39}: "IRCAM",
+	{0x20, 0x3A}: "Propellerhead Software",
+	{0x20, 0x3B}: "Red Sound Systems",
+	{0x20, 0x3C}: "Elektron",
+	{0x20, 0x3D}: "Sintefex Audio",
+	{0x20, 0x3E}: "MAM (Music and More)",
+	{0x20, 0x3F}: "Amsaro",
+	{0x20, 0x40}: "CDS Advanced Technology (Lanbox)",
+	{0x20, 0x41}: "Mode Machines (Touched By Sound)",
+	{0x20, 0x42}: "DSP Arts",
+	{0x20, 0x43}: "Phil Rees Music Tech",
+	{0x20, 0x44}: "Stamer Musikanlagen",
+	{0x20, 0x45}: "Soundart (Musical Muntaner)",
+	{0x20, 0x46}: "C-Mexx Software",
+	{0x20, 0x47}: "Klavis Technologies",
+	{0x20, 0x48}: "Noteheads",
+	{0x20, 0x49}: "Algorithmix",
+	{0x20, 0x4A}: "Skrydstrup R&D",
+	{0x20, 0x4B}: "Professional Audio Company",
+	{0x20, 0x4C}: "NewWave Labs (MadWaves)",
+	{0x20, 0x4D}: "Vermona",
+	{0x20, 0x4E}: "Nokia",
+	{0x20, 0x4F}: "Wave Idea",
+	{0x20, 0x50}: "Hartmann",
+	{0x20, 0x51}: "Lion's Tracs",
+	{0x20, 0x52}: "Analogue Systems",
+	{0x20, 0x53}: "Focal-JMlab",
+	{0x20, 0x54}: "Ringway Electronics",
+	{0x20, 0x55}: "Faith Technologies (Digiplug)",
+	{0x20, 0x56}: "Showworks",
+	{0x20, 0x57}: "Manikin Electronic",
+	{0x20, 0x58}: "1 Come Tech",
+	{0x20, 0x59}: "Phonic",
+	{0x20, 0x5A}: "Dolby Australia (Lake)",
+	{0x20, 0x5B}: "Silansys Technologies",
+	{0x20, 0x5C}: "Winbond Electronics",
+	{0x20, 0x5D}: "Cinetix Medien und Interface",
+	{0x20, 0x5E}: "A&G Soluzioni Digitali",
+	{0x20, 0x5F}: "Sequentix",
+	{0x20, 0x60}: "Oram Pro Audio",
+	{0x20, 0x61}: "Be4",
+	{0x20, 0x62}: "Infection Music",
+	{0x20, 0x63}: "Central Music Co. (CME)",
+	{0x20, 0x64}: "genoQs Machines",
+	{0x20, 0x65}: "Medialon",
+	{0x20, 0x66}: "Waves Audio",
+	{0x20, 0x67}: "Jerash Labs",
+	{0x20, 0x68}: "Da Fact",
+	{0x20, 0x69}: "Elby Designs",
+	{0x20, 0x6A}: "Spectral Audio",
+	{0x20, 0x6B}: "Arturia",
+	{0x20, 0x6C}: "Vixid",
+	{0x20, 0x6D}: "C-Thru Music",
+	{0x20, 0x6E}: "Ya Horng Electronic",
+	{0x20, 0x6F}: "SM Pro Audio",
+	{0x20, 0x70}: "OTO Machines",
+	{0x20, 0x71}: "ELZAB (G LAB)",
+	{0x20, 0x72}: "Blackstar Amplification",
+	{0x20, 0x73}: "M3i Technologies",
+	{0x20, 0x74}: "Gemalto (Xiring)",
+	{0x20, 0x75}: "Prostage",
+	{0x20, 0x76}: "Teenage Engineering",
+	{0x20, 0x77}: "Tobias Erichsen Consulting",
+	{0x20, 0x78}: "Nixer",
+	{0x20, 0x79}: "Hanpin Electron",
+	{0x20, 0x7A}: "MIDI-hardware R.Sowa",
+	{0x20, 0x7B}: "Beyond Music Industrial",
+	{0x20, 0x7C}: "Kiss Box",
+	{0x20, 0x7D}: "Misa Digital Technologies",
+	{0x20, 0x7E}: "AI Musics Technology",
+	{0x20, 0x7F}: "Serato",
+
+	{0x21, 0x00}: "Limex",
+	{0x21, 0x01}: "Kyodday (Tokai)",
+	{0x21, 0x02}: "Mutable Instruments",
+	{0x21, 0x03}: "PreSonus Software",
+	{0x21, 0x04}: "Ingenico (Xiring)",
+	{0x21, 0x05}: "Fairlight Instruments",
+	{0x21, 0x06}: "Musicom Lab",
+	{0x21, 0x07}: "Modal Electronics",
+	{0x21, 0x08}: "RWA (Hong Kong)",
+	{0x21, 0x09}: "Native Instruments",
+	{0x21, 0x0A}: "Naonext",
+	{0x21, 0x0B}: "MFB",
+	{0x21, 0x0C}: "Teknel Research",
+	{0x21, 0x0D}: "Ploytec",
+	{0x21, 0x0E}: "Surfin Kangaroo Studio",
+	{0x21, 0x0F}: "Philips Electronics HK",
+	{0x21, 0x10}: "ROLI",
+	{0x21, 0x11}: "Panda-Audio",
+	{0x21, 0x12}: "BauM Software",
+	{0x21, 0x13}: "Machinewerks",
+	{0x21, 0x14}: "Xiamen Elane Electronics",
+	{0x21, 0x15}: "Marshall Amplification",
+	{0x21, 0x16}: "Kiwitechnics",
+	{0x21, 0x17}: "Rob Papen",
+	{0x21, 0x18}: "Spicetone",
+	{0x21, 0x19}: "V3Sound",
+	{0x21, 0x1A}: "IK Multimedia",
+	{0x21, 0x1B}: "Novalia",
+	{0x21, 0x1C}: "Modor Music",
+	{0x21, 0x1D}: "Ableton",
+	{0x21, 0x1E}: "Dtronics",
+	{0x21, 0x1F}: "ZAQ Audio",
+	{0x21, 0x20}: "Muabaobao Education Technology",
+	{0x21, 0x21}: "Flux Effects",
+	{0x21, 0x22}: "Audiothingies",
+	{0x21, 0x23}: "Retrokits",
+	{0x21, 0x24}: "Morningstar FX",
+	{0x21, 0x25}: "Changsha Hotone Audio",
+	{0x21, 0x26}: "Expressive E",
+	{0x21, 0x27}: "Expert Sleepers",
+	{0x21, 0x28}: "Timecode-Vision Technology",
+	{0x21, 0x29}: "Hornberg Research",
+	{0x21, 0x2A}: "Sonic Potions",
+	{0x21, 0x2B}: "Audiofront",
+	{0x21, 0x2C}: "Fred's Lab",
+	{0x21, 0x2D}: "Audio Modeling",
+	{0x21, 0x2E}: "C. Bechstein Digital",
+	{0x21, 0x2F}: "Motas Electronics",
+	{0x21, 0x30}: "Elk Audio",
+	{0x21, 0x31}: "Sonic Academy",
+	{0x21, 0x32}: "Bome Software",
+	{0x21, 0x33}: "AODYO",
+	{0x21, 0x34}: "Pianoforce",
+	{0x21, 0x35}: "Dreadbox",
+	{0x21, 0x36}: "TouchKeys Instruments",
+	{0x21, 0x37}: "The Gigrig",
+	{0x21, 0x38}: "ALM Co",
+	{0x21, 0x39}: "CH Sound Design",
+	{0x21, 0x3A}: "Beat Bars",
+	{0x21, 0x3B}: "Blokas",
+	{0x21, 0x3C}: "GEWA Music",
+	{0x21, 0x3D}: "dadamachines",
+	{0x21, 0x3E}: "Augmented Instruments (Bela)",
+	{0x21, 0x3F}: "Supercritical",
+	{0x21, 0x40}: "Genki Instruments",
+	{0x21, 0x41}: "Marienberg Devices Germany",
+	{0x21, 0x42}: "Supperware",
+	{0x21, 0x43}: "Imoxplus",
+	{0x21, 0x44}: "Swapperdoo",
+
+	{0x40, 0x00}: "Crimson Technology",
+	{0x40, 0x01}: "Softbank Mobile",
+	{0x40, 0x03}: "D&M Holdings",
+}
+
+// ManufacturerID returns the manufacturer ID at the start of SysEx data (without F0)
+// and the number of bytes it occupies: 1 for single-byte IDs, 3 for extended IDs starting with 00.
+func ManufacturerID(data []byte) ([]byte, int) {
+	if len(data) == 0 {
+		return nil, 0
+	}
+	if data[0] != 0x00 {
+		return data[:1], 1
+	}
+	if len(data) < 3 {
+		return data, len(data)
+	}
+	return data[:3], 3
+}
+
+// ManufacturerName returns the name of a 1- or 3-byte SysEx manufacturer ID
+func ManufacturerName(id []byte) string {
+	switch len(id) {
+	case 1:
+		if name, ok := oneByteManufacturers[id[0]]; ok {
+			return name
+		}
+		return fmt.Sprintf("Manufacturer %02X", id[0])
+	case 3:
+		if name, ok := threeByteManufacturers[[2]byte{id[1], id[2]}]; ok {
+			return name
+		}
+		return fmt.Sprintf("Manufacturer %02X %02X %02X", id[0], id[1], id[2])
+	}
+	return "Unknown Manufacturer"
+}
@@ -0,0 +1,43 @@
+package midi
+
+import "testing"
+
+func TestManufacturerID(t *testing.T) {
+	id, n := ManufacturerID([]byte{0x41, 0x10})
+	if n != 1 || id[0] != 0x41 {
+		t.Errorf("ManufacturerID(41 10) = % X, %d; want 41, 1", id, n)
+	}
+
+	id, n = ManufacturerID([]byte{0x00, 0x20, 0x32, 0x01})
+	if n != 3 || len(id) != 3 {
+		t.Errorf("ManufacturerID(00 20 32 01) = % X, %d; want 00 20 32, 3", id, n)
+	}
+
+	if _, n := ManufacturerID(nil); n != 0 {
+		t.Errorf("ManufacturerID(nil) length = %d; want 0", n)
+	}
+}
+
+func TestManufacturerName(t *testing.T) {
+	tests := []struct {
+		id       []byte
+		expected string
+	}{
+		{[]byte{0x41}, "Roland"},
+		{[]byte{0x43}, "Yamaha"},
+		{[]byte{0x01}, "Sequential Circuits"},
+		{[]byte{0x00, 0x20, 0x32}, "Behringer"},
+		{[]byte{0x00, 0x20, 0x6B}, "Arturia"},
+		{[]byte{0x00, 0x00, 0x1A}, "Allen & Heath Brenell"},
+		{[]byte{0x00, 0x20, 0x76}, "Teenage Engineering"},
+		{[]byte{0x00, 0x21, 0x1D}, "Ableton"},
+		{[]byte{0x00, 0x21, 0x27}, "Expert Sleepers"},
+		{[]byte{0x00, 0x7F, 0x7F}, "Manufacturer 00 7F 7F"},
+	}
+
+	for _, tt := range tests {
+		if got := ManufacturerName(tt.id); got != tt.expected {
+			t.Errorf("ManufacturerName(% X) = %s; want %s", tt.id, got, tt.expected)
+		}
+	}
+}
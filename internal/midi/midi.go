@@ -14,6 +14,9 @@ type Device struct {
 	Name   string
 	Number int
 	Port   drivers.In
+	Out    drivers.Out // output port with the same name (nil if none)
+
+	Identity *IdentityReply // result of an identity probe (nil if not identified)
 }
 
 // Event represents a MIDI event with metadata
@@ -24,6 +27,39 @@ type Event struct {
 	MessageType string
 	Data        string
 	RawBytes    []byte
+	Labels      Labels // device-specific names resolved when the event was received
+
+	RunningStatus bool // the status byte was omitted on the wire; RawBytes includes it
+	Sent          bool // sent to an output by the viewer (replay) rather than received
+	Bookmarked    bool // bookmarked by the user, to find it again
+
+	// Timestamp is the driver's time for the message. Arrived is when the driver callback
+	// delivered it and Processed when the viewer handled it, so their differences are the
+	// driver's and the app's own latency. Both are zero when unknown.
+	Arrived   time.Time
+	Processed time.Time
+
+	// Universal MIDI Packet fields, for events decoded from UMP (see DecodeUMP)
+	Group       uint8    // UMP group (0-15)
+	Packet      []uint32 // the packet's words (nil for MIDI 1.0 byte streams)
+	HighRes     uint32   // MIDI 2.0 value at full resolution: velocity, controller, pressure or bend
+	HighResBits uint8    // resolution of HighRes in bits: 16 for velocity, 32 otherwise (0 = none)
+}
+
+// MarkerMessageType is the MessageType of markers: notes the user drops into the stream, such
+// as "pressed preset 3", that are not MIDI messages
+const MarkerMessageType = "Marker"
+
+// NewMarker creates a marker event named name at the given time
+func NewMarker(name string, at time.Time) Event {
+	return Event{Timestamp: at, MessageType: MarkerMessageType, Data: name}
+}
+
+// Labels holds device-specific names for the parts of an event (empty = no specific name)
+type Labels struct {
+	Note       string
+	Controller string
+	Program    string
 }
 
 // InitDriver initializes the MIDI driver
@@ -47,18 +83,23 @@ func GetInputDevices() ([]Device, error) {
 	ins := midi.GetInPorts()
 	devices := make([]Device, len(ins))
 
+	// Outputs are optional; without them the device is simply input-only
+	outs, _ := GetOutputDevices()
+
 	for i, port := range ins {
 		devices[i] = Device{
 			Name:   port.String(),
 			Number: i,
 			Port:   port,
+			Out:    findOutput(outs, port.String()),
 		}
 	}
 
 	return devices, nil
 }
 
-// ParseMessage parses a MIDI message into an Event
+// ParseMessage parses a MIDI message into an Event stamped with the current time.
+// Callers with a driver timestamp replace it.
 func ParseMessage(msg midi.Message) Event {
 	event := Event{
 		Timestamp:   time.Now(),
@@ -77,6 +118,11 @@ func ParseMessage(msg midi.Message) Event {
 	return event
 }
 
+// IsRealtime reports whether the event is a single-byte system realtime message (F8-FF)
+func (e Event) IsRealtime() bool {
+	return len(e.RawBytes) == 1 && e.RawBytes[0] >= 0xF8
+}
+
 func getMessageType(msg midi.Message) string {
 	var ch, key, vel, controller, value, program, pressure uint8
 	var rel int16
@@ -121,6 +167,7 @@ func formatMessageData(msg midi.Message) string {
 	var ch, key, vel, controller, value, program, pressure uint8
 	var rel int16
 	var abs uint16
+	var bt []byte
 
 	switch {
 	case msg.GetNoteOn(&ch, &key, &vel):
@@ -137,6 +184,8 @@ func formatMessageData(msg midi.Message) string {
 		return fmt.Sprintf("Note: %d, Pressure: %d", key, pressure)
 	case msg.GetAfterTouch(&ch, &pressure):
 		return fmt.Sprintf("Pressure: %d", pressure)
+	case msg.GetSysEx(&bt):
+		return DecodeSysEx(bt).Summary
 	default:
 		return fmt.Sprintf("%v", msg.Bytes())
 	}
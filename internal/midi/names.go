@@ -0,0 +1,331 @@
+package midi
+
+import "fmt"
+
+// controllerNames holds the standard MIDI 1.0 control change assignments
+var controllerNames = [128]string{
+	0:   "Bank Select",
+	1:   "Mod Wheel",
+	2:   "Breath",
+	4:   "Foot Pedal",
+	5:   "Portamento Time",
+	6:   "Data Entry",
+	7:   "Volume",
+	8:   "Balance",
+	10:  "Pan",
+	11:  "Expression",
+	12:  "Effect Ctrl 1",
+	13:  "Effect Ctrl 2",
+	16:  "General Purpose 1",
+	17:  "General Purpose 2",
+	18:  "General Purpose 3",
+	19:  "General Purpose 4",
+	32:  "Bank Select LSB",
+	33:  "Mod Wheel LSB",
+	34:  "Breath LSB",
+	36:  "Foot Pedal LSB",
+	37:  "Portamento Time LSB",
+	38:  "Data Entry LSB",
+	39:  "Volume LSB",
+	40:  "Balance LSB",
+	42:  "Pan LSB",
+	43:  "Expression LSB",
+	44:  "Effect Ctrl 1 LSB",
+	45:  "Effect Ctrl 2 LSB",
+	48:  "General Purpose 1 LSB",
+	49:  "General Purpose 2 LSB",
+	50:  "General Purpose 3 LSB",
+	51:  "General Purpose 4 LSB",
+	64:  "Sustain",
+	65:  "Portamento",
+	66:  "Sostenuto",
+	67:  "Soft Pedal",
+	68:  "Legato",
+	69:  "Hold 2",
+	70:  "Sound Variation",
+	71:  "Resonance",
+	72:  "Release Time",
+	73:  "Attack Time",
+	74:  "Cutoff",
+	75:  "Decay Time",
+	76:  "Vibrato Rate",
+	77:  "Vibrato Depth",
+	78:  "Vibrato Delay",
+	79:  "Sound Ctrl 10",
+	80:  "General Purpose 5",
+	81:  "General Purpose 6",
+	82:  "General Purpose 7",
+	83:  "General Purpose 8",
+	84:  "Portamento Control",
+	88:  "Hi-Res Velocity Prefix",
+	91:  "Reverb",
+	92:  "Tremolo",
+	93:  "Chorus",
+	94:  "Detune",
+	95:  "Phaser",
+	96:  "Data Increment",
+	97:  "Data Decrement",
+	98:  "NRPN LSB",
+	99:  "NRPN MSB",
+	100: "RPN LSB",
+	101: "RPN MSB",
+	120: "All Sound Off",
+	121: "Reset All Controllers",
+	122: "Local Control",
+	123: "All Notes Off",
+	124: "Omni Off",
+	125: "Omni On",
+	126: "Mono On",
+	127: "Poly On",
+}
+
+// DrumChannel is the zero-based channel General MIDI reserves for percussion (channel 10)
+const DrumChannel uint8 = 9
+
+// ControllerName returns the standard name of a control change number.
+// Controllers 32-63 without a name of their own are named after their MSB counterpart,
+// and undefined controllers are named "Undefined".
+func ControllerName(controller uint8) string {
+	if controller > 127 {
+		return fmt.Sprintf("CC %d", controller)
+	}
+	if name := controllerNames[controller]; name != "" {
+		return name
+	}
+	if controller >= 32 && controller < 64 {
+		return fmt.Sprintf("CC %d LSB", controller-32)
+	}
+	return "Undefined"
+}
+
+// ProgramName returns the General MIDI name of a program number (0-127)
+func ProgramName(program uint8) string {
+	if program > 127 {
+		return ""
+	}
+	return programNames[program]
+}
+
+// DrumKitName returns the GS drum kit selected by a program number on the drum channel,
+// or an empty string if the program is not a standard kit
+func DrumKitName(program uint8) string {
+	return drumKitNames[program]
+}
+
+// DrumName returns the General MIDI percussion name of a note on the drum channel,
+// or an empty string if the note has no standard assignment
+func DrumName(note uint8) string {
+	return drumNames[note]
+}
+
+// programNames holds the General MIDI Level 1 program names, indexed by program number (0-127).
+// GS and XG sound sets use the same names for their capital tones (bank 0).
+var programNames = [128]string{
+	"Acoustic Grand Piano",
+	"Bright Acoustic Piano",
+	"Electric Grand Piano",
+	"Honky-tonk Piano",
+	"Electric Piano 1",
+	"Electric Piano 2",
+	"Harpsichord",
+	"Clavi",
+	"Celesta",
+	"Glockenspiel",
+	"Music Box",
+	"Vibraphone",
+	"Marimba",
+	"Xylophone",
+	"Tubular Bells",
+	"Dulcimer",
+	"Drawbar Organ",
+	"Percussive Organ",
+	"Rock Organ",
+	"Church Organ",
+	"Reed Organ",
+	"Accordion",
+	"Harmonica",
+	"Tango Accordion",
+	"Acoustic Guitar (nylon)",
+	"Acoustic Guitar (steel)",
+	"Electric Guitar (jazz)",
+	"Electric Guitar (clean)",
+	"Electric Guitar (muted)",
+	"Overdriven Guitar",
+	"Distortion Guitar",
+	"Guitar Harmonics",
+	"Acoustic Bass",
+	"Electric Bass (finger)",
+	"Electric Bass (pick)",
+	"Fretless Bass",
+	"Slap Bass 1",
+	"Slap Bass 2",
+	"Synth Bass 1",
+	"Synth Bass 2",
+	"Violin",
+	"Viola",
+	"Cello",
+	"Contrabass",
+	"Tremolo Strings",
+	"Pizzicato Strings",
+	"Orchestral Harp",
+	"Timpani",
+	"String Ensemble 1",
+	"String Ensemble 2",
+	"Synth Strings 1",
+	"Synth Strings 2",
+	"Choir Aahs",
+	"Voice Oohs",
+	"Synth Voice",
+	"Orchestra Hit",
+	"Trumpet",
+	"Trombone",
+	"Tuba",
+	"Muted Trumpet",
+	"French Horn",
+	"Brass Section",
+	"Synth Brass 1",
+	"Synth Brass 2",
+	"Soprano Sax",
+	"Alto Sax",
+	"Tenor Sax",
+	"Baritone Sax",
+	"Oboe",
+	"English Horn",
+	"Bassoon",
+	"Clarinet",
+	"Piccolo",
+	"Flute",
+	"Recorder",
+	"Pan Flute",
+	"Blown Bottle",
+	"Shakuhachi",
+	"Whistle",
+	"Ocarina",
+	"Lead 1 (square)",
+	"Lead 2 (sawtooth)",
+	"Lead 3 (calliope)",
+	"Lead 4 (chiff)",
+	"Lead 5 (charang)",
+	"Lead 6 (voice)",
+	"Lead 7 (fifths)",
+	"Lead 8 (bass + lead)",
+	"Pad 1 (new age)",
+	"Pad 2 (warm)",
+	"Pad 3 (polysynth)",
+	"Pad 4 (choir)",
+	"Pad 5 (bowed)",
+	"Pad 6 (metallic)",
+	"Pad 7 (halo)",
+	"Pad 8 (sweep)",
+	"FX 1 (rain)",
+	"FX 2 (soundtrack)",
+	"FX 3 (crystal)",
+	"FX 4 (atmosphere)",
+	"FX 5 (brightness)",
+	"FX 6 (goblins)",
+	"FX 7 (echoes)",
+	"FX 8 (sci-fi)",
+	"Sitar",
+	"Banjo",
+	"Shamisen",
+	"Koto",
+	"Kalimba",
+	"Bag pipe",
+	"Fiddle",
+	"Shanai",
+	"Tinkle Bell",
+	"Agogo",
+	"Steel Drums",
+	"Woodblock",
+	"Taiko Drum",
+	"Melodic Tom",
+	"Synth Drum",
+	"Reverse Cymbal",
+	"Guitar Fret Noise",
+	"Breath Noise",
+	"Seashore",
+	"Bird Tweet",
+	"Telephone Ring",
+	"Helicopter",
+	"Applause",
+	"Gunshot",
+}
+
+// drumKitNames holds the GS drum kit names selected by program changes on the drum channel
+var drumKitNames = map[uint8]string{
+	0:   "Standard Kit",
+	8:   "Room Kit",
+	16:  "Power Kit",
+	24:  "Electronic Kit",
+	25:  "TR-808 Kit",
+	32:  "Jazz Kit",
+	40:  "Brush Kit",
+	48:  "Orchestra Kit",
+	56:  "SFX Kit",
+	127: "CM-64/32L Kit",
+}
+
+// drumNames holds the GM percussion key map (35-81) plus the GS extensions (27-34, 82-87)
+var drumNames = map[uint8]string{
+	27: "High Q",
+	28: "Slap",
+	29: "Scratch Push",
+	30: "Scratch Pull",
+	31: "Sticks",
+	32: "Square Click",
+	33: "Metronome Click",
+	34: "Metronome Bell",
+	35: "Acoustic Bass Drum",
+	36: "Bass Drum 1",
+	37: "Side Stick",
+	38: "Acoustic Snare",
+	39: "Hand Clap",
+	40: "Electric Snare",
+	41: "Low Floor Tom",
+	42: "Closed Hi-Hat",
+	43: "High Floor Tom",
+	44: "Pedal Hi-Hat",
+	45: "Low Tom",
+	46: "Open Hi-Hat",
+	47: "Low-Mid Tom",
+	48: "Hi-Mid Tom",
+	49: "Crash Cymbal 1",
+	50: "High Tom",
+	51: "Ride Cymbal 1",
+	52: "Chinese Cymbal",
+	53: "Ride Bell",
+	54: "Tambourine",
+	55: "Splash Cymbal",
+	56: "Cowbell",
+	57: "Crash Cymbal 2",
+	58: "Vibraslap",
+	59: "Ride Cymbal 2",
+	60: "Hi Bongo",
+	61: "Low Bongo",
+	62: "Mute Hi Conga",
+	63: "Open Hi Conga",
+	64: "Low Conga",
+	65: "High Timbale",
+	66: "Low Timbale",
+	67: "High Agogo",
+	68: "Low Agogo",
+	69: "Cabasa",
+	70: "Maracas",
+	71: "Short Whistle",
+	72: "Long Whistle",
+	73: "Short Guiro",
+	74: "Long Guiro",
+	75: "Claves",
+	76: "Hi Wood Block",
+	77: "Low Wood Block",
+	78: "Mute Cuica",
+	79: "Open Cuica",
+	80: "Mute Triangle",
+	81: "Open Triangle",
+	82: "Shaker",
+	83: "Jingle Bell",
+	84: "Bell Tree",
+	85: "Castanets",
+	86: "Mute Surdo",
+	87: "Open Surdo",
+}
@@ -0,0 +1,71 @@
+package midi
+
+import "testing"
+
+func TestControllerName(t *testing.T) {
+	tests := []struct {
+		controller uint8
+		expected   string
+	}{
+		{1, "Mod Wheel"},
+		{7, "Volume"},
+		{10, "Pan"},
+		{11, "Expression"},
+		{64, "Sustain"},
+		{123, "All Notes Off"},
+		{3, "Undefined"},
+	}
+
+	for _, tt := range tests {
+		result := ControllerName(tt.controller)
+		if result != tt.expected {
+			t.Errorf("ControllerName(%d) = %s; want %s", tt.controller, result, tt.expected)
+		}
+	}
+}
+
+func TestControllerNameLSB(t *testing.T) {
+	if got := ControllerName(33); got != "Mod Wheel LSB" {
+		t.Errorf("ControllerName(33) = %s; want Mod Wheel LSB", got)
+	}
+	if got := ControllerName(35); got != "CC 3 LSB" {
+		t.Errorf("ControllerName(35) = %s; want CC 3 LSB", got)
+	}
+}
+
+func TestProgramName(t *testing.T) {
+	tests := []struct {
+		program  uint8
+		expected string
+	}{
+		{0, "Acoustic Grand Piano"},
+		{24, "Acoustic Guitar (nylon)"},
+		{40, "Violin"},
+		{127, "Gunshot"},
+	}
+
+	for _, tt := range tests {
+		result := ProgramName(tt.program)
+		if result != tt.expected {
+			t.Errorf("ProgramName(%d) = %s; want %s", tt.program, result, tt.expected)
+		}
+	}
+}
+
+func TestDrumNames(t *testing.T) {
+	if got := DrumName(36); got != "Bass Drum 1" {
+		t.Errorf("DrumName(36) = %s; want Bass Drum 1", got)
+	}
+	if got := DrumName(42); got != "Closed Hi-Hat" {
+		t.Errorf("DrumName(42) = %s; want Closed Hi-Hat", got)
+	}
+	if got := DrumName(100); got != "" {
+		t.Errorf("DrumName(100) = %s; want empty", got)
+	}
+	if got := DrumKitName(25); got != "TR-808 Kit" {
+		t.Errorf("DrumKitName(25) = %s; want TR-808 Kit", got)
+	}
+	if got := DrumKitName(3); got != "" {
+		t.Errorf("DrumKitName(3) = %s; want empty", got)
+	}
+}
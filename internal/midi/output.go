@@ -0,0 +1,66 @@
+package midi
+
+import (
+	"fmt"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// GetOutputDevices returns all available MIDI output ports
+func GetOutputDevices() ([]drivers.Out, error) {
+	outs, err := drivers.Outs()
+	if err != nil {
+		return nil, fmt.Errorf("could not list MIDI outputs: %w", err)
+	}
+	return outs, nil
+}
+
+// findOutput returns the output port with the given name, or nil if there is none
+func findOutput(outs []drivers.Out, name string) drivers.Out {
+	for _, out := range outs {
+		if out.String() == name {
+			return out
+		}
+	}
+	return nil
+}
+
+// SendMessages opens the output port if needed and sends the messages in order
+func SendMessages(out drivers.Out, msgs ...midi.Message) error {
+	if out == nil {
+		return fmt.Errorf("no MIDI output available")
+	}
+
+	if !out.IsOpen() {
+		if err := out.Open(); err != nil {
+			return fmt.Errorf("could not open MIDI output %q: %w", out.String(), err)
+		}
+	}
+
+	for _, msg := range msgs {
+		if err := out.Send(msg.Bytes()); err != nil {
+			return fmt.Errorf("could not send to %q: %w", out.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// AllNotesOffMessages returns an All Notes Off (CC 123) message for every channel
+func AllNotesOffMessages() []midi.Message {
+	return controllerOnAllChannels(midi.AllNotesOff)
+}
+
+// ResetAllControllersMessages returns a Reset All Controllers (CC 121) message for every channel
+func ResetAllControllersMessages() []midi.Message {
+	return controllerOnAllChannels(midi.AllControllersOff)
+}
+
+func controllerOnAllChannels(controller uint8) []midi.Message {
+	msgs := make([]midi.Message, 16)
+	for ch := uint8(0); ch < 16; ch++ {
+		msgs[ch] = midi.ControlChange(ch, controller, 0)
+	}
+	return msgs
+}
@@ -0,0 +1,83 @@
+package midi
+
+import (
+	"bytes"
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// fakeOut records everything sent to it
+type fakeOut struct {
+	name string
+	open bool
+	sent [][]byte
+}
+
+func (f *fakeOut) Open() error             { f.open = true; return nil }
+func (f *fakeOut) Close() error            { f.open = false; return nil }
+func (f *fakeOut) IsOpen() bool            { return f.open }
+func (f *fakeOut) Number() int             { return 0 }
+func (f *fakeOut) String() string          { return f.name }
+func (f *fakeOut) Underlying() interface{} { return nil }
+func (f *fakeOut) Send(b []byte) error {
+	f.sent = append(f.sent, append([]byte(nil), b...))
+	return nil
+}
+
+func TestAllNotesOffMessages(t *testing.T) {
+	msgs := AllNotesOffMessages()
+	if len(msgs) != 16 {
+		t.Fatalf("AllNotesOffMessages() returned %d messages; want 16", len(msgs))
+	}
+
+	for ch, msg := range msgs {
+		want := []byte{0xB0 | byte(ch), 123, 0}
+		if !bytes.Equal(msg.Bytes(), want) {
+			t.Errorf("AllNotesOffMessages()[%d] = % X; want % X", ch, msg.Bytes(), want)
+		}
+	}
+}
+
+func TestResetAllControllersMessages(t *testing.T) {
+	msgs := ResetAllControllersMessages()
+	if len(msgs) != 16 {
+		t.Fatalf("ResetAllControllersMessages() returned %d messages; want 16", len(msgs))
+	}
+
+	want := []byte{0xBF, 121, 0}
+	if !bytes.Equal(msgs[15].Bytes(), want) {
+		t.Errorf("ResetAllControllersMessages()[15] = % X; want % X", msgs[15].Bytes(), want)
+	}
+}
+
+func TestSendMessages(t *testing.T) {
+	out := &fakeOut{name: "Test Out"}
+
+	if err := SendMessages(out, ResetAllControllersMessages()...); err != nil {
+		t.Fatalf("SendMessages() error = %v", err)
+	}
+	if !out.open {
+		t.Error("SendMessages() should open a closed port")
+	}
+	if len(out.sent) != 16 {
+		t.Errorf("SendMessages() sent %d messages; want 16", len(out.sent))
+	}
+
+	if err := SendMessages(nil); err == nil {
+		t.Error("SendMessages(nil) should return an error")
+	}
+}
+
+func TestFindOutput(t *testing.T) {
+	a := &fakeOut{name: "Synth A"}
+	b := &fakeOut{name: "Synth B"}
+	outs := []drivers.Out{a, b}
+
+	if got := findOutput(outs, "Synth B"); got != b {
+		t.Errorf("findOutput(Synth B) = %v; want %v", got, b)
+	}
+	if got := findOutput(outs, "Synth C"); got != nil {
+		t.Errorf("findOutput(Synth C) = %v; want nil", got)
+	}
+}
@@ -0,0 +1,224 @@
+package midi
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// ErrorMessageType is the MessageType of events describing malformed input
+const ErrorMessageType = "Error"
+
+// Parser turns a MIDI 1.0 byte stream into events, as received on a DIN or serial link.
+// Messages may span calls to Feed; running status, realtime bytes interleaved anywhere
+// and SysEx are handled per the MIDI 1.0 specification. Malformed input becomes events of
+// type ErrorMessageType explaining the problem.
+type Parser struct {
+	running byte   // running status: the last channel status byte (0 = none)
+	pending []byte // channel or system common message being collected
+	implied bool   // the pending message's status byte came from running status
+	stray   []byte // data bytes with no status byte
+	sysex   []byte // SysEx received so far, starting with F0
+	inSysEx bool
+}
+
+// NewParser creates a parser at the start of a stream
+func NewParser() Parser {
+	return Parser{}
+}
+
+// Parse parses a complete byte stream received at the given time
+func Parse(data []byte, at time.Time) []Event {
+	p := NewParser()
+	events := p.Feed(data, at)
+	return append(events, p.Flush(at)...)
+}
+
+// Feed parses the next bytes of the stream, received at the given time, and returns
+// the messages and errors completed by them
+func (p *Parser) Feed(data []byte, at time.Time) []Event {
+	var events []Event
+
+	for _, b := range data {
+		switch {
+		case b >= 0xF8:
+			// Realtime bytes may appear anywhere, even inside other messages, and leave running status alone
+			if b == 0xF9 || b == 0xFD {
+				events = append(events, errorEvent(fmt.Sprintf("undefined realtime status %02X", b), []byte{b}, at))
+				continue
+			}
+			events = append(events, newEvent([]byte{b}, false, at))
+
+		case p.inSysEx && b < 0x80:
+			p.sysex = append(p.sysex, b)
+
+		case b == 0xF7:
+			if !p.inSysEx {
+				events = append(events, p.flushMessage(at)...)
+				p.running = 0
+				events = append(events, errorEvent("End of SysEx (F7) with no SysEx in progress", []byte{b}, at))
+				continue
+			}
+			p.sysex = append(p.sysex, b)
+			events = append(events, newEvent(p.sysex, false, at))
+			p.sysex, p.inSysEx = nil, false
+
+		case b >= 0x80:
+			// Any other status byte ends an unterminated SysEx and any partial message
+			if p.inSysEx {
+				events = append(events, errorEvent(
+					fmt.Sprintf("SysEx missing F7: interrupted by %02X after %s", b, byteCount(len(p.sysex))),
+					p.sysex, at))
+				p.sysex, p.inSysEx = nil, false
+			}
+			events = append(events, p.flushMessage(at)...)
+
+			// Channel messages set running status; system common messages cancel it
+			p.running = 0
+			if b < 0xF0 {
+				p.running = b
+			}
+
+			if b == 0xF0 {
+				p.sysex, p.inSysEx = []byte{b}, true
+				continue
+			}
+			if b == 0xF4 || b == 0xF5 {
+				events = append(events, errorEvent(fmt.Sprintf("undefined system common status %02X", b), []byte{b}, at))
+				continue
+			}
+
+			p.pending, p.implied = []byte{b}, false
+			if dataLength(b) == 0 {
+				events = append(events, newEvent(p.pending, false, at))
+				p.pending = nil
+			}
+
+		default:
+			if len(p.pending) == 0 {
+				if p.running == 0 {
+					p.stray = append(p.stray, b)
+					continue
+				}
+				p.pending, p.implied = []byte{p.running}, true
+			}
+			p.pending = append(p.pending, b)
+			if len(p.pending) == 1+dataLength(p.pending[0]) {
+				events = append(events, newEvent(p.pending, p.implied, at))
+				p.pending = nil
+			}
+		}
+	}
+
+	return events
+}
+
+// Flush ends the stream: any partial message or unterminated SysEx is reported as an error
+// and the parser is ready for a new stream
+func (p *Parser) Flush(at time.Time) []Event {
+	events := p.flushMessage(at)
+	if p.inSysEx {
+		events = append(events, errorEvent(
+			fmt.Sprintf("SysEx missing F7 at end of input after %s", byteCount(len(p.sysex))),
+			p.sysex, at))
+	}
+	p.Reset()
+	return events
+}
+
+// Reset discards any partial message and running status
+func (p *Parser) Reset() {
+	*p = Parser{}
+}
+
+// flushMessage reports stray data bytes and a partial channel or system common message as errors
+func (p *Parser) flushMessage(at time.Time) []Event {
+	var events []Event
+	if len(p.stray) > 0 {
+		events = append(events, errorEvent(fmt.Sprintf("%s with no status byte", dataByteCount(len(p.stray))), p.stray, at))
+		p.stray = nil
+	}
+	if len(p.pending) > 0 {
+		events = append(events, errorEvent(truncatedMessage(p.pending), p.pending, at))
+		p.pending = nil
+	}
+	return events
+}
+
+// newEvent parses a complete message received at the given time, copying its bytes
+func newEvent(raw []byte, runningStatus bool, at time.Time) Event {
+	event := ParseMessage(midi.Message(append([]byte(nil), raw...)))
+	event.Timestamp = at
+	event.RunningStatus = runningStatus
+	return event
+}
+
+// errorEvent creates an event describing malformed bytes
+func errorEvent(explanation string, raw []byte, at time.Time) Event {
+	raw = append([]byte(nil), raw...)
+	return Event{
+		Timestamp:   at,
+		Message:     midi.Message(raw),
+		MessageType: ErrorMessageType,
+		Data:        explanation,
+		RawBytes:    raw,
+	}
+}
+
+// dataLength returns the number of data bytes that follow a channel or system common status byte
+func dataLength(status byte) int {
+	switch {
+	case status < 0xC0, status >= 0xE0 && status < 0xF0:
+		return 2 // note off/on, poly aftertouch, CC, pitch bend
+	case status < 0xE0:
+		return 1 // program change, channel aftertouch
+	case status == 0xF1, status == 0xF3:
+		return 1 // MTC quarter frame, song select
+	case status == 0xF2:
+		return 2 // song position pointer
+	}
+	return 0 // tune request
+}
+
+// truncatedMessage explains a message that ended before all its data bytes arrived
+func truncatedMessage(pending []byte) string {
+	return fmt.Sprintf("truncated %s: expected %s, got %d",
+		statusName(pending[0]), dataByteCount(dataLength(pending[0])), len(pending)-1)
+}
+
+// statusName names the message started by a channel or system common status byte
+func statusName(status byte) string {
+	switch status >> 4 {
+	case 0x8:
+		return "Note Off"
+	case 0x9:
+		return "Note On"
+	case 0xA:
+		return "Poly Aftertouch"
+	case 0xB:
+		return "CC"
+	case 0xC:
+		return "Program Change"
+	case 0xD:
+		return "Aftertouch"
+	case 0xE:
+		return "Pitch Bend"
+	}
+	switch status {
+	case 0xF1:
+		return "MTC Quarter Frame"
+	case 0xF2:
+		return "Song Position"
+	case 0xF3:
+		return "Song Select"
+	}
+	return fmt.Sprintf("status %02X", status)
+}
+
+func dataByteCount(n int) string {
+	if n == 1 {
+		return "1 data byte"
+	}
+	return fmt.Sprintf("%d data bytes", n)
+}
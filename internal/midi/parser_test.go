@@ -0,0 +1,99 @@
+package midi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRunningStatus(t *testing.T) {
+	// Note On C4, then E4 and G4 using running status, then a CC that changes it
+	data := []byte{0x90, 0x3C, 0x64, 0x40, 0x64, 0x43, 0x64, 0xB0, 0x07, 0x64, 0x0A, 0x40}
+
+	events := Parse(data, time.Now())
+	got := summarise(events)
+	want := []string{"Note On", "Note On", "Note On", "CC", "CC"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("Parse() = %v; want %v", got, want)
+	}
+
+	running := []bool{false, true, true, false, true}
+	for i, event := range events {
+		if event.RunningStatus != running[i] {
+			t.Errorf("event %d RunningStatus = %v; want %v", i, event.RunningStatus, running[i])
+		}
+	}
+	if !bytes.Equal(events[1].RawBytes, []byte{0x90, 0x40, 0x64}) {
+		t.Errorf("running status RawBytes = % X; want 90 40 64", events[1].RawBytes)
+	}
+}
+
+func TestParseRealtimeInsideMessage(t *testing.T) {
+	// Clock bytes may arrive between a status byte and its data, and do not cancel running status
+	events := Parse([]byte{0x90, 0xF8, 0x3C, 0x64, 0xF8, 0x3E, 0xFE, 0x64}, time.Now())
+
+	got := summarise(events)
+	want := []string{"Clock", "Note On", "Clock", "Active Sense", "Note On"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("Parse() = %v; want %v", got, want)
+	}
+	if !events[4].RunningStatus {
+		t.Error("second Note On should use running status")
+	}
+}
+
+func TestParseSystemCommonCancelsRunningStatus(t *testing.T) {
+	// Tune Request (F6) cancels running status, so the following data bytes are stray
+	got := summarise(Parse([]byte{0x90, 0x3C, 0x64, 0xF6, 0x3E, 0x64}, time.Now()))
+	want := []string{"Note On", "Unknown", "Error: 2 data bytes with no status byte"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("Parse() = %v; want %v", got, want)
+	}
+
+	// So does SysEx
+	got = summarise(Parse([]byte{0xB0, 0x07, 0x64, 0xF0, 0x7E, 0xF7, 0x07, 0x00}, time.Now()))
+	want = []string{"CC", "SysEx", "Error: 2 data bytes with no status byte"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("Parse() = %v; want %v", got, want)
+	}
+}
+
+func TestParserMessagesSpanFeeds(t *testing.T) {
+	p := NewParser()
+	at := time.Now()
+
+	var got []string
+	for _, chunk := range [][]byte{{0x90}, {0x3C}, {0x64, 0x3E}, {0x64, 0xF0, 0x43}, {0x10, 0xF7}} {
+		got = append(got, summarise(p.Feed(chunk, at))...)
+	}
+	got = append(got, summarise(p.Flush(at))...)
+
+	want := []string{"Note On", "Note On", "SysEx"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Feed() = %v; want %v", got, want)
+	}
+}
+
+func TestParserFlush(t *testing.T) {
+	p := NewParser()
+	at := time.Now()
+
+	p.Feed([]byte{0x90, 0x3C}, at)
+	got := summarise(p.Flush(at))
+	if len(got) != 1 || got[0] != "Error: truncated Note On: expected 2 data bytes, got 1" {
+		t.Errorf("Flush() = %v", got)
+	}
+
+	p.Feed([]byte{0xF0, 0x43, 0x10}, at)
+	got = summarise(p.Flush(at))
+	if len(got) != 1 || got[0] != "Error: SysEx missing F7 at end of input after 3 bytes" {
+		t.Errorf("Flush() = %v", got)
+	}
+
+	// Flush starts a new stream without running status
+	got = summarise(p.Feed([]byte{0x3C, 0x64}, at))
+	if len(got) != 0 {
+		t.Errorf("Feed() after Flush() = %v; stray bytes should wait for the next status", got)
+	}
+}
@@ -0,0 +1,42 @@
+package midi
+
+// Latency probes are SysEx messages with the non-commercial manufacturer ID (7D) carrying
+// a session and a sequence number, so a round-trip test recognises its own probes among
+// other traffic and probes left over from earlier tests:
+//
+//	F0 7D 4C 50 <session MSB> <session LSB> <sequence MSB> <sequence LSB> F7
+const (
+	probeManufacturer = 0x7D
+	probeTag1         = 0x4C // 'L'
+	probeTag2         = 0x50 // 'P'
+)
+
+// MaxProbeSequence is the largest sequence number a latency probe can carry
+const MaxProbeSequence = 0x3FFF
+
+// LatencyProbe returns the probe SysEx for a session and sequence number (both 14-bit)
+func LatencyProbe(session uint16, seq int) []byte {
+	return []byte{
+		0xF0, probeManufacturer, probeTag1, probeTag2,
+		byte(session>>7) & 0x7F, byte(session) & 0x7F,
+		byte(seq>>7) & 0x7F, byte(seq) & 0x7F,
+		0xF7,
+	}
+}
+
+// ParseLatencyProbe returns the session and sequence number of a latency probe
+func ParseLatencyProbe(raw []byte) (session uint16, seq int, ok bool) {
+	if len(raw) != 9 || raw[0] != 0xF0 || raw[1] != probeManufacturer ||
+		raw[2] != probeTag1 || raw[3] != probeTag2 || raw[8] != 0xF7 {
+		return 0, 0, false
+	}
+	for _, b := range raw[4:8] {
+		if b > 0x7F {
+			return 0, 0, false
+		}
+	}
+
+	session = uint16(raw[4])<<7 | uint16(raw[5])
+	seq = int(raw[6])<<7 | int(raw[7])
+	return session, seq, true
+}
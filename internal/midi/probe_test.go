@@ -0,0 +1,28 @@
+package midi
+
+import "testing"
+
+func TestLatencyProbeRoundTrip(t *testing.T) {
+	probe := LatencyProbe(0x1234, 1000)
+	if len(probe) != 9 || probe[0] != 0xF0 || probe[8] != 0xF7 {
+		t.Fatalf("LatencyProbe() = % X; want a 9-byte SysEx", probe)
+	}
+
+	session, seq, ok := ParseLatencyProbe(probe)
+	if !ok || session != 0x1234 || seq != 1000 {
+		t.Errorf("ParseLatencyProbe() = %#x, %d, %v; want 0x1234, 1000, true", session, seq, ok)
+	}
+}
+
+func TestParseLatencyProbeRejectsOtherSysEx(t *testing.T) {
+	for _, raw := range [][]byte{
+		{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7},
+		{0xF0, 0x7D, 0x4C, 0x51, 0x00, 0x00, 0x00, 0x01, 0xF7},
+		{0xF0, 0x7D, 0x4C, 0x50, 0x00, 0x00, 0x00, 0x01},
+		{0x90, 60, 100},
+	} {
+		if _, _, ok := ParseLatencyProbe(raw); ok {
+			t.Errorf("ParseLatencyProbe(% X) = ok; want not a probe", raw)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package midi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// DefaultSerialBaud is the DIN MIDI baud rate, used by serial ports unless configured otherwise
+const DefaultSerialBaud = 31250
+
+// SerialBaudRates are the selectable serial speeds: DIN MIDI and common USB-serial bridge rates
+var SerialBaudRates = []int{31250, 38400, 57600, 115200}
+
+// serialPortPatterns match the USB-serial bridges MIDI boards usually show up as
+var serialPortPatterns = []string{
+	"/dev/ttyUSB*",
+	"/dev/ttyACM*",
+	"/dev/cu.usbserial*",
+	"/dev/cu.usbmodem*",
+}
+
+// SerialIn is a MIDI input read from a serial port, such as a USB-serial bridge
+// sending raw MIDI bytes. It implements drivers.In, so it can be used as a Device's Port.
+type SerialIn struct {
+	path   string
+	baud   int
+	number int
+	file   *os.File
+}
+
+// NewSerialIn creates a serial MIDI input for a tty path and baud rate
+func NewSerialIn(path string, baud, number int) *SerialIn {
+	return &SerialIn{path: path, baud: baud, number: number}
+}
+
+// NewSerialDevice creates a device reading MIDI from a serial port
+func NewSerialDevice(path string, baud, number int) Device {
+	in := NewSerialIn(path, baud, number)
+	return Device{
+		Name:   in.String(),
+		Number: number,
+		Port:   in,
+	}
+}
+
+// SerialPorts returns the tty paths of likely USB-serial bridges
+func SerialPorts() []string {
+	var paths []string
+	for _, pattern := range serialPortPatterns {
+		matches, _ := filepath.Glob(pattern)
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// SerialDevices returns a device for each likely USB-serial bridge at the default baud rate,
+// numbered from first
+func SerialDevices(first int) []Device {
+	var devices []Device
+	for i, path := range SerialPorts() {
+		devices = append(devices, NewSerialDevice(path, DefaultSerialBaud, first+i))
+	}
+	return devices
+}
+
+// NextSerialBaud returns the selectable baud rate after baud, wrapping around
+func NextSerialBaud(baud int) int {
+	for i, rate := range SerialBaudRates {
+		if rate == baud {
+			return SerialBaudRates[(i+1)%len(SerialBaudRates)]
+		}
+	}
+	return SerialBaudRates[0]
+}
+
+// ParseSerialSpec parses "path" or "path:baud" as entered by the user
+func ParseSerialSpec(spec string) (path string, baud int, err error) {
+	path, baud = strings.TrimSpace(spec), DefaultSerialBaud
+	if i := strings.LastIndex(path, ":"); i >= 0 {
+		baud, err = strconv.Atoi(path[i+1:])
+		if err != nil || baud <= 0 {
+			return "", 0, fmt.Errorf("invalid baud rate %q", path[i+1:])
+		}
+		path = path[:i]
+	}
+	if path == "" {
+		return "", 0, fmt.Errorf("no serial port given")
+	}
+	return path, baud, nil
+}
+
+// Path returns the tty path
+func (s *SerialIn) Path() string {
+	return s.path
+}
+
+// Baud returns the baud rate
+func (s *SerialIn) Baud() int {
+	return s.baud
+}
+
+// Open opens the tty and configures it for raw 8N1 at the baud rate
+func (s *SerialIn) Open() error {
+	if s.file != nil {
+		return nil
+	}
+	file, err := openSerial(s.path, s.baud)
+	if err != nil {
+		return fmt.Errorf("could not open serial port %s: %w", s.path, err)
+	}
+	s.file = file
+	return nil
+}
+
+// Close closes the tty
+func (s *SerialIn) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// IsOpen reports whether the tty is open
+func (s *SerialIn) IsOpen() bool {
+	return s.file != nil
+}
+
+// Number returns the device number
+func (s *SerialIn) Number() int {
+	return s.number
+}
+
+// String names the port, e.g. "Serial /dev/ttyUSB0 @ 31250"
+func (s *SerialIn) String() string {
+	return fmt.Sprintf("Serial %s @ %d", s.path, s.baud)
+}
+
+// Underlying returns the open tty file, or nil
+func (s *SerialIn) Underlying() interface{} {
+	return s.file
+}
+
+// Listen reads bytes from the tty, parses them with running status and calls onMsg with
+// each complete message, or with the bytes of malformed input. Timestamps are milliseconds
+// since Listen was called.
+func (s *SerialIn) Listen(onMsg func(msg []byte, milliseconds int32), config drivers.ListenConfig) (func(), error) {
+	start := time.Now()
+	parser := NewParser()
+	return s.ListenBytes(func(data []byte, at time.Time) {
+		ms := int32(at.Sub(start).Milliseconds())
+		for _, event := range parser.Feed(data, at) {
+			if listenWants(event, config) {
+				onMsg(event.RawBytes, ms)
+			}
+		}
+	}, config.OnErr)
+}
+
+// ListenBytes reads the tty and calls onData with the bytes of each read as they arrived,
+// so messages may span calls and use running status; feed them to a stream Assembler.
+// onErr, if set, is called with a read error that ends listening.
+func (s *SerialIn) ListenBytes(onData func(data []byte, at time.Time), onErr func(error)) (func(), error) {
+	if s.file == nil {
+		return nil, fmt.Errorf("serial port %s is not open", s.path)
+	}
+
+	file := s.file
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		buf := make([]byte, 1024)
+		for {
+			n, err := file.Read(buf)
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if n > 0 {
+				onData(buf[:n], time.Now())
+			}
+
+			if err != nil {
+				if onErr != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+					onErr(err)
+				}
+				return
+			}
+		}
+	}()
+
+	stop := func() {
+		close(done)
+		// Unblock the pending read, then make the port readable again
+		file.SetReadDeadline(time.Now())
+		<-stopped
+		file.SetReadDeadline(time.Time{})
+	}
+
+	return stop, nil
+}
+
+// listenWants reports whether an event passes the message classes enabled in a ListenConfig
+func listenWants(event Event, config drivers.ListenConfig) bool {
+	if len(event.RawBytes) == 0 || event.MessageType == ErrorMessageType {
+		return true
+	}
+	switch event.RawBytes[0] {
+	case 0xF0:
+		return config.SysEx
+	case 0xFE:
+		return config.ActiveSense
+	case 0xF1:
+		return config.TimeCode
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+//go:build linux && !ppc64 && !ppc64le
+
+package midi
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openSerial opens a tty for raw 8N1 reading at any baud rate, including the non-standard
+// 31250 of DIN MIDI, using termios2
+func openSerial(path string, baud int) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the raw connection so the file stays non-blocking and read deadlines keep working
+	conn, err := file.SyscallConn()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	var ioctlErr error
+	err = conn.Control(func(fd uintptr) {
+		t, err := unix.IoctlGetTermios(int(fd), unix.TCGETS2)
+		if err != nil {
+			ioctlErr = err
+			return
+		}
+
+		t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+			unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF | unix.IXANY
+		t.Oflag &^= unix.OPOST
+		t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+		t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB | unix.CRTSCTS | unix.CBAUD | unix.CIBAUD
+		t.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL | unix.BOTHER
+		t.Cc[unix.VMIN] = 1
+		t.Cc[unix.VTIME] = 0
+		t.Ispeed = uint32(baud)
+		t.Ospeed = uint32(baud)
+
+		ioctlErr = unix.IoctlSetTermios(int(fd), unix.TCSETS2, t)
+	})
+	if err == nil {
+		err = ioctlErr
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
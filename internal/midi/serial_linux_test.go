@@ -0,0 +1,120 @@
+//go:build linux && !ppc64 && !ppc64le
+
+package midi
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2/drivers"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal pair and returns the master and the slave's path
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminals: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	conn, err := master.SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	var ioctlErr error
+	conn.Control(func(fd uintptr) {
+		if ioctlErr = unix.IoctlSetPointerInt(int(fd), unix.TIOCSPTLCK, 0); ioctlErr == nil {
+			n, ioctlErr = unix.IoctlGetInt(int(fd), unix.TIOCGPTN)
+		}
+	})
+	if ioctlErr != nil {
+		t.Fatalf("could not unlock pseudo-terminal: %v", ioctlErr)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func TestSerialInListen(t *testing.T) {
+	master, path := openPTY(t)
+
+	in := NewSerialIn(path, DefaultSerialBaud, 3)
+	if err := in.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer in.Close()
+
+	received := make(chan []byte, 16)
+	stop, err := in.Listen(func(msg []byte, _ int32) {
+		received <- append([]byte(nil), msg...)
+	}, drivers.ListenConfig{SysEx: true})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	// Running status note, an active sense that is not wanted, and SysEx split across writes
+	master.Write([]byte{0x90, 0x3C, 0x64, 0x3E, 0x64, 0xFE, 0xF0, 0x7E})
+	master.Write([]byte{0x7F, 0x06, 0x01, 0xF7})
+
+	want := [][]byte{
+		{0x90, 0x3C, 0x64},
+		{0x90, 0x3E, 0x64},
+		{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7},
+	}
+	for _, w := range want {
+		select {
+		case got := <-received:
+			if !bytes.Equal(got, w) {
+				t.Errorf("Listen() delivered % X; want % X", got, w)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for % X", w)
+		}
+	}
+
+	stop()
+
+	// The port stays open and can be listened to again
+	if !in.IsOpen() {
+		t.Error("stop() should leave the port open")
+	}
+}
+
+func TestListenSerialRunningStatus(t *testing.T) {
+	master, path := openPTY(t)
+
+	in := NewSerialIn(path, DefaultSerialBaud, 3)
+	device := Device{Name: in.String(), Port: in}
+	defer in.Close()
+
+	received := make(chan []byte, 16)
+	stop, err := Listen(device, func(data []byte, _, _ time.Time) {
+		received <- data
+	})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer stop()
+
+	master.Write([]byte{0x90, 0x3C, 0x64, 0x3E, 0x64})
+
+	assembler := NewDeviceAssembler(device)
+	var events []Event
+	for len(events) < 2 {
+		select {
+		case data := <-received:
+			events = append(events, assembler.Feed(data, time.Now())...)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out after %d events", len(events))
+		}
+	}
+	if events[0].RunningStatus || !events[1].RunningStatus {
+		t.Errorf("RunningStatus = %v, %v; want false, true", events[0].RunningStatus, events[1].RunningStatus)
+	}
+}
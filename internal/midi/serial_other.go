@@ -0,0 +1,13 @@
+//go:build !linux || ppc64 || ppc64le
+
+package midi
+
+import (
+	"fmt"
+	"os"
+)
+
+// openSerial is only implemented on Linux, where termios2 allows the 31250 baud of DIN MIDI
+func openSerial(path string, baud int) (*os.File, error) {
+	return nil, fmt.Errorf("serial MIDI input is not supported on this platform")
+}
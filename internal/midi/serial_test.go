@@ -0,0 +1,43 @@
+package midi
+
+import "testing"
+
+func TestParseSerialSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		path    string
+		baud    int
+		wantErr bool
+	}{
+		{"/dev/ttyUSB0", "/dev/ttyUSB0", DefaultSerialBaud, false},
+		{" /dev/ttyACM1:115200 ", "/dev/ttyACM1", 115200, false},
+		{"/dev/ttyUSB0:fast", "", 0, true},
+		{":31250", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		path, baud, err := ParseSerialSpec(tt.spec)
+		if (err != nil) != tt.wantErr || path != tt.path || baud != tt.baud {
+			t.Errorf("ParseSerialSpec(%q) = %q, %d, %v; want %q, %d", tt.spec, path, baud, err, tt.path, tt.baud)
+		}
+	}
+}
+
+func TestNewSerialDevice(t *testing.T) {
+	device := NewSerialDevice("/dev/ttyUSB0", 115200, 2)
+	if device.Name != "Serial /dev/ttyUSB0 @ 115200" || device.Number != 2 || device.Out != nil {
+		t.Errorf("NewSerialDevice() = %+v", device)
+	}
+}
+
+func TestNextSerialBaud(t *testing.T) {
+	if got := NextSerialBaud(31250); got != 38400 {
+		t.Errorf("NextSerialBaud(31250) = %d; want 38400", got)
+	}
+	if got := NextSerialBaud(115200); got != 31250 {
+		t.Errorf("NextSerialBaud(115200) = %d; want 31250", got)
+	}
+	if got := NextSerialBaud(9600); got != 31250 {
+		t.Errorf("NextSerialBaud(9600) = %d; want 31250", got)
+	}
+}
@@ -0,0 +1,464 @@
+package midi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SysExInfo is the decoded form of a System Exclusive message
+type SysExInfo struct {
+	ManufacturerID []byte
+	Manufacturer   string
+	Universal      bool   // Universal Non-Realtime or Realtime message
+	DeviceID       uint8  // device ID of universal messages (0x7F = all devices)
+	Summary        string // one-line description, e.g. "Roland: DT1 addr 40 00 7F data 00"
+}
+
+// IdentityReply is the decoded form of a Universal Identity Reply (F0 7E dd 06 02 ... F7)
+type IdentityReply struct {
+	DeviceID       uint8
+	ManufacturerID []byte
+	Family         uint16
+	Model          uint16
+	Version        [4]byte
+}
+
+// String describes the identity, e.g. "Roland family 0042 model 0003 ver 01 00 00 00"
+func (r IdentityReply) String() string {
+	return fmt.Sprintf("%s family %04X model %04X ver % X",
+		ManufacturerName(r.ManufacturerID), r.Family, r.Model, r.Version[:])
+}
+
+// DecodeSysEx decodes the inner bytes of a SysEx message (without the F0 and F7)
+func DecodeSysEx(data []byte) SysExInfo {
+	id, n := ManufacturerID(data)
+	info := SysExInfo{
+		ManufacturerID: id,
+		Manufacturer:   ManufacturerName(id),
+	}
+
+	if n == 0 {
+		info.Summary = "Empty SysEx"
+		return info
+	}
+
+	body := data[n:]
+
+	switch {
+	case n == 1 && (id[0] == UniversalNonRealtime || id[0] == UniversalRealtime):
+		info.Universal = true
+		if len(body) > 0 {
+			info.DeviceID = body[0]
+		}
+		if id[0] == UniversalNonRealtime {
+			info.Summary = "Universal Non-RT: " + describeNonRealtime(body)
+		} else {
+			info.Summary = "Universal RT: " + describeRealtime(body)
+		}
+	case n == 1 && id[0] == 0x41:
+		info.Summary = "Roland: " + describeRoland(body)
+	case n == 1 && id[0] == 0x43:
+		info.Summary = "Yamaha: " + describeYamaha(body)
+	case n == 1 && id[0] == 0x42:
+		info.Summary = "Korg: " + describeKorg(body)
+	default:
+		info.Summary = fmt.Sprintf("%s: %s", info.Manufacturer, byteCount(len(body)))
+	}
+
+	return info
+}
+
+// ParseIdentityReply decodes a Universal Identity Reply from the inner bytes of a SysEx message
+func ParseIdentityReply(data []byte) (IdentityReply, bool) {
+	if len(data) < 4 || data[0] != UniversalNonRealtime || data[2] != 0x06 || data[3] != 0x02 {
+		return IdentityReply{}, false
+	}
+
+	reply := IdentityReply{DeviceID: data[1]}
+	rest := data[4:]
+
+	id, n := ManufacturerID(rest)
+	if n == 0 || len(rest) < n+8 {
+		return IdentityReply{}, false
+	}
+	reply.ManufacturerID = append([]byte(nil), id...)
+	rest = rest[n:]
+
+	reply.Family = uint16(rest[0]) | uint16(rest[1])<<7
+	reply.Model = uint16(rest[2]) | uint16(rest[3])<<7
+	copy(reply.Version[:], rest[4:8])
+
+	return reply, true
+}
+
+// IdentityRequest returns the inner bytes of a Universal Identity Request to all devices
+func IdentityRequest() []byte {
+	return []byte{UniversalNonRealtime, 0x7F, 0x06, 0x01}
+}
+
+func byteCount(n int) string {
+	if n == 1 {
+		return "1 byte"
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// hexBytes formats bytes as space-separated hex, e.g. "40 00 7F"
+func hexBytes(b []byte) string {
+	return fmt.Sprintf("% X", b)
+}
+
+// uint14 combines an LSB-first pair of 7-bit bytes
+func uint14(lsb, msb byte) int {
+	return int(lsb) | int(msb)<<7
+}
+
+// uint21 combines an LSB-first triple of 7-bit bytes
+func uint21(b []byte) int {
+	return int(b[0]) | int(b[1])<<7 | int(b[2])<<14
+}
+
+// describeNonRealtime describes a Universal Non-Realtime body starting at the device ID
+func describeNonRealtime(body []byte) string {
+	if len(body) < 2 {
+		return "truncated"
+	}
+	sub := body[1:]
+
+	switch sub[0] {
+	case 0x01:
+		// ss ss ee ff ff ff gg gg gg hh hh hh ii ii ii jj
+		if len(sub) < 17 {
+			return "Sample Dump Header (truncated)"
+		}
+		period := uint21(sub[4:7])
+		rate := 0
+		if period > 0 {
+			rate = int(1e9 / float64(period))
+		}
+		return fmt.Sprintf("Sample Dump Header #%d %d-bit %d Hz len %d loop %d-%d",
+			uint14(sub[1], sub[2]), sub[3], rate, uint21(sub[7:10]), uint21(sub[10:13]), uint21(sub[13:16]))
+	case 0x02:
+		if len(sub) < 2 {
+			return "Sample Data Packet"
+		}
+		return fmt.Sprintf("Sample Data Packet #%d", sub[1])
+	case 0x03:
+		if len(sub) < 3 {
+			return "Sample Dump Request"
+		}
+		return fmt.Sprintf("Sample Dump Request #%d", uint14(sub[1], sub[2]))
+	case 0x04:
+		return "MTC Cueing"
+	case 0x05:
+		return "Sample Dump Extension"
+	case 0x06:
+		if len(sub) >= 2 && sub[1] == 0x01 {
+			return "Identity Request"
+		}
+		if len(sub) >= 2 && sub[1] == 0x02 {
+			reply, ok := ParseIdentityReply(append([]byte{UniversalNonRealtime}, body...))
+			if !ok {
+				return "Identity Reply (truncated)"
+			}
+			return "Identity Reply " + reply.String()
+		}
+		return "General Information"
+	case 0x07:
+		return "File Dump"
+	case 0x08:
+		return describeTuning(sub[1:], false)
+	case 0x09:
+		if len(sub) < 2 {
+			return "General MIDI"
+		}
+		switch sub[1] {
+		case 0x01:
+			return "GM System On"
+		case 0x02:
+			return "GM System Off"
+		case 0x03:
+			return "GM2 System On"
+		}
+		return "General MIDI"
+	case 0x7B:
+		return "End of File"
+	case 0x7C:
+		return "Wait"
+	case 0x7D:
+		return "Cancel"
+	case 0x7E:
+		return handshake("NAK", sub)
+	case 0x7F:
+		return handshake("ACK", sub)
+	}
+
+	return fmt.Sprintf("sub-ID %02X", sub[0])
+}
+
+func handshake(name string, sub []byte) string {
+	if len(sub) < 2 {
+		return name
+	}
+	return fmt.Sprintf("%s packet %d", name, sub[1])
+}
+
+// describeRealtime describes a Universal Realtime body starting at the device ID
+func describeRealtime(body []byte) string {
+	if len(body) < 2 {
+		return "truncated"
+	}
+	sub := body[1:]
+
+	switch sub[0] {
+	case 0x01:
+		if len(sub) >= 6 && sub[1] == 0x01 {
+			return "MTC Full Frame " + timecode(sub[2:6])
+		}
+		if len(sub) >= 2 && sub[1] == 0x02 {
+			return "MTC User Bits"
+		}
+		return "MIDI Time Code"
+	case 0x03:
+		if len(sub) >= 2 {
+			switch sub[1] {
+			case 0x01:
+				if len(sub) >= 4 {
+					return fmt.Sprintf("Bar Number %d", int(int16(uint14(sub[2], sub[3])<<2)>>2))
+				}
+				return "Bar Number"
+			case 0x02, 0x42:
+				if len(sub) >= 5 {
+					return fmt.Sprintf("Time Signature %d/%d", sub[3], 1<<sub[4])
+				}
+				return "Time Signature"
+			}
+		}
+		return "Notation Information"
+	case 0x04:
+		return describeDeviceControl(sub[1:])
+	case 0x05:
+		return "Realtime MTC Cueing"
+	case 0x06:
+		return describeMMC(sub[1:])
+	case 0x07:
+		return "MMC Response"
+	case 0x08:
+		return describeTuning(sub[1:], true)
+	case 0x09:
+		return "Controller Destination Setting"
+	case 0x0A:
+		return "Key-Based Instrument Control"
+	case 0x0B:
+		return "Scalable Polyphony"
+	}
+
+	return fmt.Sprintf("sub-ID %02X", sub[0])
+}
+
+// timecode formats hr mn sc fr bytes as "hh:mm:ss:ff @rate"
+func timecode(b []byte) string {
+	rates := []string{"24fps", "25fps", "29.97fps drop", "30fps"}
+	return fmt.Sprintf("%02d:%02d:%02d:%02d @%s", b[0]&0x1F, b[1], b[2], b[3], rates[(b[0]>>5)&0x03])
+}
+
+func describeDeviceControl(sub []byte) string {
+	if len(sub) < 3 {
+		return "Device Control"
+	}
+
+	value := uint14(sub[1], sub[2])
+	switch sub[0] {
+	case 0x01:
+		return fmt.Sprintf("Master Volume %d (%.0f%%)", value, float64(value)/16383*100)
+	case 0x02:
+		return fmt.Sprintf("Master Balance %d", value-8192)
+	case 0x03:
+		return fmt.Sprintf("Master Fine Tuning %.1f cents", float64(value-8192)/8192*100)
+	case 0x04:
+		return fmt.Sprintf("Master Coarse Tuning %+d semitones", int(sub[2])-64)
+	}
+	return "Device Control"
+}
+
+// mmcCommands names the MIDI Machine Control commands
+var mmcCommands = map[byte]string{
+	0x01: "Stop",
+	0x02: "Play",
+	0x03: "Deferred Play",
+	0x04: "Fast Forward",
+	0x05: "Rewind",
+	0x06: "Record Strobe",
+	0x07: "Record Exit",
+	0x08: "Record Pause",
+	0x09: "Pause",
+	0x0A: "Eject",
+	0x0B: "Chase",
+	0x0C: "Command Error Reset",
+	0x0D: "MMC Reset",
+	0x40: "Write",
+	0x44: "Locate",
+	0x47: "Shuttle",
+}
+
+func describeMMC(sub []byte) string {
+	if len(sub) == 0 {
+		return "MMC"
+	}
+
+	name, ok := mmcCommands[sub[0]]
+	if !ok {
+		return fmt.Sprintf("MMC command %02X", sub[0])
+	}
+
+	// Locate carries a target time: 44 06 01 hr mn sc fr ff
+	if sub[0] == 0x44 && len(sub) >= 7 && sub[2] == 0x01 {
+		return "MMC Locate " + timecode(sub[3:7])
+	}
+
+	return "MMC " + name
+}
+
+// describeTuning describes a MIDI Tuning Standard message starting at its sub-ID 2
+func describeTuning(sub []byte, realtime bool) string {
+	if len(sub) == 0 {
+		return "MIDI Tuning"
+	}
+
+	switch sub[0] {
+	case 0x00:
+		if len(sub) >= 2 {
+			return fmt.Sprintf("MTS Bulk Dump Request program %d", sub[1])
+		}
+	case 0x01:
+		if len(sub) >= 18 {
+			name := strings.TrimRight(string(sub[2:18]), " \x00")
+			return fmt.Sprintf("MTS Bulk Dump Reply program %d %q", sub[1], name)
+		}
+	case 0x02:
+		if len(sub) >= 3 {
+			return fmt.Sprintf("MTS Single Note Tuning program %d (%d changes)", sub[1], sub[2])
+		}
+	case 0x03:
+		if len(sub) >= 3 {
+			return fmt.Sprintf("MTS Tuning Dump Request bank %d program %d", sub[1], sub[2])
+		}
+	case 0x04:
+		if len(sub) >= 3 {
+			return fmt.Sprintf("MTS Key-Based Tuning Dump bank %d program %d", sub[1], sub[2])
+		}
+	case 0x05, 0x06:
+		if len(sub) >= 3 {
+			return fmt.Sprintf("MTS Scale/Octave Tuning Dump bank %d program %d", sub[1], sub[2])
+		}
+	case 0x07:
+		if len(sub) >= 4 {
+			return fmt.Sprintf("MTS Single Note Tuning bank %d program %d (%d changes)", sub[1], sub[2], sub[3])
+		}
+	case 0x08:
+		return "MTS Scale/Octave Tuning (1-byte)"
+	case 0x09:
+		return "MTS Scale/Octave Tuning (2-byte)"
+	}
+
+	if realtime {
+		return fmt.Sprintf("MIDI Tuning sub-ID %02X", sub[0])
+	}
+	return fmt.Sprintf("MIDI Tuning dump sub-ID %02X", sub[0])
+}
+
+// rolandModelLength returns the length of a Roland model ID: leading 00 bytes extend it
+func rolandModelLength(b []byte) int {
+	n := 0
+	for n < len(b) && b[n] == 0x00 {
+		n++
+	}
+	if n < len(b) {
+		n++
+	}
+	return n
+}
+
+// describeRoland describes a Roland body starting at the device ID
+func describeRoland(body []byte) string {
+	if len(body) < 3 {
+		return byteCount(len(body))
+	}
+
+	modelLen := rolandModelLength(body[1:])
+	rest := body[1+modelLen:]
+	if len(rest) == 0 {
+		return byteCount(len(body))
+	}
+
+	// Extended model IDs use 4-byte addresses, single-byte models use 3
+	addrLen := 3
+	if modelLen > 1 {
+		addrLen = 4
+	}
+
+	cmd, payload := rest[0], rest[1:]
+	var name string
+	switch cmd {
+	case 0x11:
+		name = "RQ1"
+	case 0x12:
+		name = "DT1"
+	default:
+		return fmt.Sprintf("command %02X %s", cmd, byteCount(len(payload)))
+	}
+
+	if len(payload) < addrLen+1 {
+		return name + " (truncated)"
+	}
+
+	addr := payload[:addrLen]
+	data := payload[addrLen : len(payload)-1] // last byte is the checksum
+
+	if cmd == 0x11 {
+		return fmt.Sprintf("%s addr %s size %s", name, hexBytes(addr), hexBytes(data))
+	}
+	if len(data) <= 4 {
+		return fmt.Sprintf("%s addr %s data %s", name, hexBytes(addr), hexBytes(data))
+	}
+	return fmt.Sprintf("%s addr %s (%s)", name, hexBytes(addr), byteCount(len(data)))
+}
+
+// describeYamaha describes a Yamaha body starting at the sub-status/device byte
+func describeYamaha(body []byte) string {
+	if len(body) == 0 {
+		return "empty"
+	}
+
+	var name string
+	switch body[0] >> 4 {
+	case 0x0:
+		name = "Bulk Dump"
+	case 0x1:
+		name = "Param Change"
+	case 0x2:
+		name = "Dump Request"
+	case 0x3:
+		name = "Param Request"
+	default:
+		return byteCount(len(body))
+	}
+
+	// XG messages: 43 1n 4C hh mm ll data
+	if len(body) >= 5 && body[1] == 0x4C {
+		if body[0]>>4 == 0x1 {
+			return fmt.Sprintf("XG %s addr %s data %s", name, hexBytes(body[2:5]), hexBytes(body[5:]))
+		}
+		return fmt.Sprintf("XG %s (%s)", name, byteCount(len(body)))
+	}
+
+	return fmt.Sprintf("%s (%s)", name, byteCount(len(body)))
+}
+
+// describeKorg describes a Korg body starting at the format/channel byte
+func describeKorg(body []byte) string {
+	if len(body) < 3 || body[0]>>4 != 0x3 {
+		return byteCount(len(body))
+	}
+	return fmt.Sprintf("model %02X func %02X (%s)", body[1], body[2], byteCount(len(body)))
+}
@@ -0,0 +1,78 @@
+package midi
+
+import (
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+func TestDecodeSysExSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"identity request", []byte{0x7E, 0x7F, 0x06, 0x01}, "Universal Non-RT: Identity Request"},
+		{"identity reply", []byte{0x7E, 0x10, 0x06, 0x02, 0x41, 0x42, 0x00, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00},
+			"Universal Non-RT: Identity Reply Roland family 0042 model 0003 ver 01 00 00 00"},
+		{"GM on", []byte{0x7E, 0x7F, 0x09, 0x01}, "Universal Non-RT: GM System On"},
+		{"GM off", []byte{0x7E, 0x7F, 0x09, 0x02}, "Universal Non-RT: GM System Off"},
+		{"master volume", []byte{0x7F, 0x7F, 0x04, 0x01, 0x7F, 0x7F}, "Universal RT: Master Volume 16383 (100%)"},
+		{"MTC full frame", []byte{0x7F, 0x7F, 0x01, 0x01, 0x21, 0x02, 0x03, 0x04}, "Universal RT: MTC Full Frame 01:02:03:04 @25fps"},
+		{"MMC play", []byte{0x7F, 0x7F, 0x06, 0x02}, "Universal RT: MMC Play"},
+		{"MMC locate", []byte{0x7F, 0x7F, 0x06, 0x44, 0x06, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00}, "Universal RT: MMC Locate 01:00:00:00 @24fps"},
+		{"sample dump header", []byte{0x7E, 0x00, 0x01, 0x05, 0x00, 0x10, 0x14, 0x31, 0x01, 0x68, 0x07, 0x00, 0x00, 0x00, 0x00, 0x67, 0x07, 0x00, 0x00},
+			"Universal Non-RT: Sample Dump Header #5 16-bit 44099 Hz len 1000 loop 0-999"},
+		{"MTS single note", []byte{0x7F, 0x7F, 0x08, 0x02, 0x00, 0x01, 0x45, 0x45, 0x00, 0x00}, "Universal RT: MTS Single Note Tuning program 0 (1 changes)"},
+		{"Roland DT1", []byte{0x41, 0x10, 0x42, 0x12, 0x40, 0x00, 0x7F, 0x00, 0x41}, "Roland: DT1 addr 40 00 7F data 00"},
+		{"Roland RQ1 extended model", []byte{0x41, 0x10, 0x00, 0x00, 0x3A, 0x11, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x6F},
+			"Roland: RQ1 addr 01 00 00 00 size 00 00 00 10"},
+		{"Yamaha XG", []byte{0x43, 0x10, 0x4C, 0x00, 0x00, 0x7E, 0x00}, "Yamaha: XG Param Change addr 00 00 7E data 00"},
+		{"Korg", []byte{0x42, 0x30, 0x58, 0x41, 0x00}, "Korg: model 58 func 41 (4 bytes)"},
+		{"three byte ID", []byte{0x00, 0x20, 0x29, 0x01, 0x02}, "Focusrite/Novation: 2 bytes"},
+		{"unknown ID", []byte{0x60, 0x01}, "Manufacturer 60: 1 byte"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeSysEx(tt.data).Summary; got != tt.expected {
+				t.Errorf("DecodeSysEx() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeSysExUniversal(t *testing.T) {
+	info := DecodeSysEx([]byte{0x7E, 0x10, 0x06, 0x01})
+	if !info.Universal || info.DeviceID != 0x10 {
+		t.Errorf("DecodeSysEx() = %+v; want universal message for device 0x10", info)
+	}
+}
+
+func TestParseIdentityReply(t *testing.T) {
+	data := []byte{0x7E, 0x00, 0x06, 0x02, 0x00, 0x20, 0x29, 0x33, 0x01, 0x02, 0x00, 0x01, 0x02, 0x03, 0x04}
+	reply, ok := ParseIdentityReply(data)
+	if !ok {
+		t.Fatal("ParseIdentityReply() failed")
+	}
+	if ManufacturerName(reply.ManufacturerID) != "Focusrite/Novation" {
+		t.Errorf("manufacturer = %s", ManufacturerName(reply.ManufacturerID))
+	}
+	if reply.Family != 0x00B3 || reply.Model != 0x0002 {
+		t.Errorf("family/model = %04X/%04X; want 00B3/0002", reply.Family, reply.Model)
+	}
+	if reply.Version != [4]byte{1, 2, 3, 4} {
+		t.Errorf("version = %v", reply.Version)
+	}
+
+	if _, ok := ParseIdentityReply([]byte{0x7E, 0x00, 0x06, 0x02, 0x41}); ok {
+		t.Error("ParseIdentityReply() should reject a truncated reply")
+	}
+}
+
+func TestParseMessageSysExData(t *testing.T) {
+	event := ParseMessage(midi.SysEx([]byte{0x7E, 0x7F, 0x09, 0x01}))
+	if event.MessageType != "SysEx" || event.Data != "Universal Non-RT: GM System On" {
+		t.Errorf("ParseMessage() = %s %q", event.MessageType, event.Data)
+	}
+}
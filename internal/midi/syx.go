@@ -0,0 +1,89 @@
+package midi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// ReadSyx reads the SysEx messages in a standard .syx stream, each including its F0 and F7.
+// Bytes outside F0 ... F7 are ignored; an unterminated message is an error.
+func ReadSyx(r io.Reader) ([][]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs [][]byte
+	for {
+		start := bytes.IndexByte(data, 0xF0)
+		if start < 0 {
+			return msgs, nil
+		}
+		end := bytes.IndexByte(data[start:], 0xF7)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated SysEx message at byte %d", start)
+		}
+		msgs = append(msgs, append([]byte(nil), data[start:start+end+1]...))
+		data = data[start+end+1:]
+	}
+}
+
+// LoadSyx loads the SysEx messages in a .syx file
+func LoadSyx(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open SysEx file: %w", err)
+	}
+	defer f.Close()
+
+	msgs, err := ReadSyx(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("no SysEx messages in %s", path)
+	}
+	return msgs, nil
+}
+
+// WriteSyx writes SysEx messages back to back, as in a standard .syx file
+func WriteSyx(w io.Writer, msgs [][]byte) error {
+	for _, msg := range msgs {
+		if _, err := w.Write(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveSyx saves SysEx messages to a .syx file
+func SaveSyx(path string, msgs [][]byte) error {
+	var buf bytes.Buffer
+	if err := WriteSyx(&buf, msgs); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("could not save SysEx file: %w", err)
+	}
+	return nil
+}
+
+// SendSysEx sends SysEx messages to an output, pausing between them for devices
+// with small receive buffers
+func SendSysEx(out drivers.Out, msgs [][]byte, delay time.Duration) error {
+	for i, msg := range msgs {
+		if i > 0 && delay > 0 {
+			time.Sleep(delay)
+		}
+		if err := SendMessages(out, midi.Message(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
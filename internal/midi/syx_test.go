@@ -0,0 +1,69 @@
+package midi
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadSyx(t *testing.T) {
+	data := []byte{
+		0xF0, 0x41, 0x10, 0x42, 0x12, 0x40, 0x00, 0x7F, 0x00, 0x41, 0xF7,
+		0x00, // junk between messages is skipped
+		0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7,
+	}
+
+	msgs, err := ReadSyx(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadSyx() error = %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("ReadSyx() returned %d messages; want 2", len(msgs))
+	}
+	if !bytes.Equal(msgs[1], []byte{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7}) {
+		t.Errorf("ReadSyx()[1] = % X", msgs[1])
+	}
+
+	if _, err := ReadSyx(bytes.NewReader([]byte{0xF0, 0x43, 0x10})); err == nil {
+		t.Error("ReadSyx() should fail on an unterminated message")
+	}
+}
+
+func TestSaveLoadSyx(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.syx")
+	msgs := [][]byte{
+		{0xF0, 0x43, 0x10, 0x4C, 0x00, 0x00, 0x7E, 0x00, 0xF7},
+		{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7},
+	}
+
+	if err := SaveSyx(path, msgs); err != nil {
+		t.Fatalf("SaveSyx() error = %v", err)
+	}
+	loaded, err := LoadSyx(path)
+	if err != nil {
+		t.Fatalf("LoadSyx() error = %v", err)
+	}
+	if len(loaded) != len(msgs) || !bytes.Equal(loaded[0], msgs[0]) || !bytes.Equal(loaded[1], msgs[1]) {
+		t.Errorf("LoadSyx() = % X; want % X", loaded, msgs)
+	}
+}
+
+func TestSendSysEx(t *testing.T) {
+	out := &fakeOut{name: "Synth"}
+	msgs := [][]byte{
+		{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7},
+		{0xF0, 0x7E, 0x7F, 0x09, 0x01, 0xF7},
+	}
+
+	start := time.Now()
+	if err := SendSysEx(out, msgs, 5*time.Millisecond); err != nil {
+		t.Fatalf("SendSysEx() error = %v", err)
+	}
+	if len(out.sent) != 2 || !bytes.Equal(out.sent[1], msgs[1]) {
+		t.Errorf("SendSysEx() sent % X", out.sent)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Errorf("SendSysEx() took %v; want at least the inter-message delay", elapsed)
+	}
+}
@@ -0,0 +1,591 @@
+package midi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// UMP message types (the top nibble of a packet's first word)
+const (
+	UMPUtility    uint8 = 0x0
+	UMPSystem     uint8 = 0x1
+	UMPMIDI1Voice uint8 = 0x2
+	UMPData64     uint8 = 0x3
+	UMPMIDI2Voice uint8 = 0x4
+	UMPData128    uint8 = 0x5
+	UMPFlexData   uint8 = 0xD
+	UMPStream     uint8 = 0xF
+)
+
+// UMPSize returns the number of 32-bit words in a Universal MIDI Packet of the given message type
+func UMPSize(messageType uint8) int {
+	switch messageType & 0xF {
+	case 0x0, 0x1, 0x2, 0x6, 0x7:
+		return 1
+	case 0x3, 0x4, 0x8, 0x9, 0xA:
+		return 2
+	case 0xB, 0xC:
+		return 3
+	}
+	return 4
+}
+
+// UMPParser turns a stream of Universal MIDI Packets into events. Packets are read as
+// big-endian 32-bit words and may span calls to Feed. SysEx carried in Data 64 and
+// Data 128 packets is reassembled per group (and stream) into a single event.
+type UMPParser struct {
+	buf        []byte              // bytes of an incomplete packet
+	sysex7     [16][]byte          // SysEx7 data collected per group
+	sysex7Open [16]bool            // a SysEx7 start has been received on the group
+	sysex8     map[[2]uint8][]byte // SysEx8 data collected per group and stream ID
+}
+
+// NewUMPParser creates a parser at the start of a UMP stream
+func NewUMPParser() UMPParser {
+	return UMPParser{sysex8: make(map[[2]uint8][]byte)}
+}
+
+// ParseUMP parses a complete UMP byte stream received at the given time
+func ParseUMP(data []byte, at time.Time) []Event {
+	p := NewUMPParser()
+	events := p.Feed(data, at)
+	return append(events, p.Flush(at)...)
+}
+
+// Feed parses the next bytes of a UMP stream and returns the events completed by them
+func (p *UMPParser) Feed(data []byte, at time.Time) []Event {
+	p.buf = append(p.buf, data...)
+
+	var events []Event
+	for len(p.buf) >= 4 {
+		size := UMPSize(p.buf[0] >> 4)
+		if len(p.buf) < size*4 {
+			break
+		}
+
+		words := make([]uint32, size)
+		for i := range words {
+			words[i] = binary.BigEndian.Uint32(p.buf[i*4:])
+		}
+		p.buf = p.buf[size*4:]
+
+		events = append(events, p.FeedPacket(words, at)...)
+	}
+
+	return events
+}
+
+// FeedPacket parses one packet given as words and returns the events it completes.
+// Utility NOOPs and the start and middle of multi-packet SysEx produce no events.
+func (p *UMPParser) FeedPacket(words []uint32, at time.Time) []Event {
+	if len(words) == 0 {
+		return nil
+	}
+	mt := uint8(words[0] >> 28)
+	group := uint8(words[0]>>24) & 0xF
+
+	switch {
+	case len(words) < UMPSize(mt):
+		return []Event{umpErrorEvent(fmt.Sprintf("UMP packet too short: %d of %d words", len(words), UMPSize(mt)), words, at)}
+	case mt == UMPUtility && (words[0]>>20)&0xF == 0:
+		return nil
+	case mt == UMPData64:
+		return p.feedSysEx7(words, group, at)
+	case mt == UMPData128 && (words[0]>>20)&0xF < 4:
+		return p.feedSysEx8(words, group, at)
+	}
+
+	event := DecodeUMP(words)
+	event.Timestamp = at
+	return []Event{event}
+}
+
+// Flush ends the stream: an incomplete packet or unfinished SysEx is reported as an error
+func (p *UMPParser) Flush(at time.Time) []Event {
+	var events []Event
+	if len(p.buf) > 0 {
+		size := UMPSize(p.buf[0] >> 4)
+		events = append(events, errorEvent(
+			fmt.Sprintf("incomplete UMP packet: got %d of %d bytes", len(p.buf), size*4), p.buf, at))
+	}
+	for group, open := range p.sysex7Open {
+		if open {
+			events = append(events, errorEvent(
+				fmt.Sprintf("SysEx7 on group %d missing its end packet after %s", group+1, byteCount(len(p.sysex7[group]))),
+				p.sysex7[group], at))
+		}
+	}
+	for key, data := range p.sysex8 {
+		events = append(events, errorEvent(
+			fmt.Sprintf("SysEx8 stream %d on group %d missing its end packet after %s", key[1], key[0]+1, byteCount(len(data))),
+			data, at))
+	}
+
+	*p = NewUMPParser()
+	return events
+}
+
+// feedSysEx7 collects a Data 64 packet: status 0 complete, 1 start, 2 continue, 3 end
+func (p *UMPParser) feedSysEx7(words []uint32, group uint8, at time.Time) []Event {
+	status := (words[0] >> 20) & 0xF
+	count := int(words[0]>>16) & 0xF
+	if count > 6 {
+		return []Event{umpErrorEvent(fmt.Sprintf("SysEx7 packet claims %d bytes; at most 6 fit", count), words, at)}
+	}
+	payload := umpBytes(words)[2 : 2+count]
+
+	var events []Event
+	switch status {
+	case 0x0, 0x1:
+		if p.sysex7Open[group] {
+			events = append(events, umpErrorEvent(
+				fmt.Sprintf("SysEx7 on group %d interrupted by a new start after %s", group+1, byteCount(len(p.sysex7[group]))),
+				words, at))
+		}
+		p.sysex7[group] = append([]byte(nil), payload...)
+		p.sysex7Open[group] = status == 0x1
+	case 0x2, 0x3:
+		if !p.sysex7Open[group] {
+			return []Event{umpErrorEvent(fmt.Sprintf("SysEx7 continue/end on group %d with no start", group+1), words, at)}
+		}
+		p.sysex7[group] = append(p.sysex7[group], payload...)
+		p.sysex7Open[group] = status == 0x2
+	default:
+		return []Event{umpErrorEvent(fmt.Sprintf("SysEx7 packet with reserved status %X", status), words, at)}
+	}
+
+	if p.sysex7Open[group] {
+		return events
+	}
+
+	msg := append(append([]byte{0xF0}, p.sysex7[group]...), 0xF7)
+	p.sysex7[group] = nil
+
+	event := ParseMessage(midi.Message(msg))
+	event.Timestamp = at
+	event.Group = group
+	event.Packet = words
+	return append(events, event)
+}
+
+// feedSysEx8 collects a Data 128 SysEx8 packet: status 0 complete, 1 start, 2 continue, 3 end
+func (p *UMPParser) feedSysEx8(words []uint32, group uint8, at time.Time) []Event {
+	status := (words[0] >> 20) & 0xF
+	count := int(words[0]>>16) & 0xF
+	if count < 1 || count > 14 {
+		return []Event{umpErrorEvent(fmt.Sprintf("SysEx8 packet with invalid byte count %d", count), words, at)}
+	}
+	bytes := umpBytes(words)
+	stream := bytes[2]
+	payload := bytes[3 : 3+count-1] // the count includes the stream ID
+	key := [2]uint8{group, stream}
+
+	var events []Event
+	data, open := p.sysex8[key]
+	switch status {
+	case 0x0, 0x1:
+		if open {
+			events = append(events, umpErrorEvent(
+				fmt.Sprintf("SysEx8 stream %d on group %d interrupted by a new start", stream, group+1), words, at))
+		}
+		data = append([]byte(nil), payload...)
+	case 0x2, 0x3:
+		if !open {
+			return []Event{umpErrorEvent(
+				fmt.Sprintf("SysEx8 continue/end for stream %d on group %d with no start", stream, group+1), words, at)}
+		}
+		data = append(data, payload...)
+	}
+
+	if status == 0x1 || status == 0x2 {
+		p.sysex8[key] = data
+		return events
+	}
+	delete(p.sysex8, key)
+
+	event := umpEvent(words, "SysEx8", fmt.Sprintf("stream %d, %s", stream, sysEx8Summary(data)))
+	event.Timestamp = at
+	return append(events, event)
+}
+
+// sysEx8Summary names the 16-bit manufacturer ID at the start of SysEx8 data
+func sysEx8Summary(data []byte) string {
+	if len(data) < 2 {
+		return byteCount(len(data))
+	}
+	// A single-byte MIDI 1.0 ID is sent as 00 id; an extended 00 xx yy ID as 80|xx yy
+	var id []byte
+	if data[0] == 0x00 {
+		id = []byte{data[1]}
+	} else {
+		id = []byte{0x00, data[0] & 0x7F, data[1]}
+	}
+	return fmt.Sprintf("%s (%s)", ManufacturerName(id), byteCount(len(data)-2))
+}
+
+// DecodeUMP decodes a single Universal MIDI Packet. MIDI 1.0 messages carried in UMP, and
+// MIDI 2.0 channel voice messages with a MIDI 1.0 equivalent, get a Message scaled down to
+// MIDI 1.0 so the rest of the viewer treats them like any other event; the full-resolution
+// value is kept in HighRes. Multi-packet SysEx is reassembled by UMPParser instead.
+func DecodeUMP(words []uint32) Event {
+	if len(words) == 0 {
+		return umpErrorEvent("empty UMP packet", words, time.Time{})
+	}
+
+	mt := uint8(words[0] >> 28)
+	if len(words) < UMPSize(mt) {
+		return umpErrorEvent(fmt.Sprintf("UMP packet too short: %d of %d words", len(words), UMPSize(mt)), words, time.Time{})
+	}
+
+	switch mt {
+	case UMPUtility:
+		return decodeUtility(words)
+	case UMPSystem, UMPMIDI1Voice:
+		return decodeMIDI1(words)
+	case UMPData64:
+		return umpEvent(words, "SysEx7", fmt.Sprintf("packet status %X (%s)", (words[0]>>20)&0xF, byteCount(int(words[0]>>16)&0xF)))
+	case UMPMIDI2Voice:
+		return decodeMIDI2Voice(words)
+	case UMPData128:
+		return decodeData128(words)
+	case UMPFlexData:
+		return decodeFlexData(words)
+	case UMPStream:
+		return decodeStream(words)
+	}
+
+	return umpEvent(words, "Unknown", fmt.Sprintf("reserved UMP message type %X (%d words)", mt, UMPSize(mt)))
+}
+
+// umpEvent creates an event for a packet with no MIDI 1.0 equivalent
+func umpEvent(words []uint32, messageType, data string) Event {
+	return Event{
+		MessageType: messageType,
+		Data:        data,
+		RawBytes:    umpBytes(words),
+		Group:       uint8(words[0]>>24) & 0xF,
+		Packet:      append([]uint32(nil), words...),
+	}
+}
+
+// umpErrorEvent creates an error event for a malformed packet
+func umpErrorEvent(explanation string, words []uint32, at time.Time) Event {
+	event := errorEvent(explanation, umpBytes(words), at)
+	event.Packet = append([]uint32(nil), words...)
+	if len(words) > 0 {
+		event.Group = uint8(words[0]>>24) & 0xF
+	}
+	return event
+}
+
+// umpBytes returns the big-endian bytes of packet words
+func umpBytes(words []uint32) []byte {
+	b := make([]byte, len(words)*4)
+	for i, w := range words {
+		binary.BigEndian.PutUint32(b[i*4:], w)
+	}
+	return b
+}
+
+// withMIDI1 turns a MIDI 1.0 message into an event decoded from a packet
+func withMIDI1(msg midi.Message, words []uint32) Event {
+	event := ParseMessage(msg)
+	event.Timestamp = time.Time{}
+	event.RawBytes = umpBytes(words)
+	event.Group = uint8(words[0]>>24) & 0xF
+	event.Packet = append([]uint32(nil), words...)
+	return event
+}
+
+func decodeUtility(words []uint32) Event {
+	status := (words[0] >> 20) & 0xF
+	value := words[0] & 0xFFFF
+	switch status {
+	case 0x0:
+		return umpEvent(words, "NOOP", "")
+	case 0x1:
+		return umpEvent(words, "JR Clock", fmt.Sprintf("time %d", value))
+	case 0x2:
+		return umpEvent(words, "JR Timestamp", fmt.Sprintf("time %d", value))
+	case 0x3:
+		return umpEvent(words, "Delta Clockstamp TPQ", fmt.Sprintf("%d ticks per quarter note", value))
+	case 0x4:
+		return umpEvent(words, "Delta Clockstamp", fmt.Sprintf("%d ticks", words[0]&0xFFFFF))
+	}
+	return umpEvent(words, "Utility", fmt.Sprintf("reserved status %X", status))
+}
+
+// decodeMIDI1 decodes system messages and MIDI 1.0 channel voice messages carried in UMP
+func decodeMIDI1(words []uint32) Event {
+	b := umpBytes(words)
+	status := b[1]
+
+	length := 0
+	switch {
+	case status < 0x80:
+		return umpEvent(words, "Unknown", fmt.Sprintf("invalid status byte %02X", status))
+	case status < 0xF0, status == 0xF1, status == 0xF2, status == 0xF3:
+		length = dataLength(status)
+	}
+
+	return withMIDI1(midi.Message(append([]byte{status}, b[2:2+length]...)), words)
+}
+
+// decodeMIDI2Voice decodes a MIDI 2.0 channel voice message
+func decodeMIDI2Voice(words []uint32) Event {
+	b := umpBytes(words)
+	opcode := b[1] >> 4
+	ch := b[1] & 0xF
+	index1, index2 := b[2], b[3]
+	value := words[1]
+
+	var event Event
+	switch opcode {
+	case 0x8, 0x9:
+		velocity := uint16(value >> 16)
+		attrType, attr := index2, uint16(value)
+		if opcode == 0x9 {
+			// A MIDI 2.0 Note On never means Note Off, so its 7-bit velocity is at least 1
+			event = withMIDI1(midi.NoteOn(ch, index1, max(uint8(velocity>>9), 1)), words)
+		} else {
+			event = withMIDI1(midi.NoteOff(ch, index1), words)
+		}
+		event.HighRes, event.HighResBits = uint32(velocity), 16
+		event.Data = fmt.Sprintf("Note: %d, Velocity: %d/65535", index1, velocity)
+		if attrType != 0 {
+			event.Data += fmt.Sprintf(", %s: %d", noteAttributeName(attrType), attr)
+		}
+		return event
+
+	case 0xA:
+		event = withMIDI1(midi.PolyAfterTouch(ch, index1, uint8(value>>25)), words)
+		event.Data = fmt.Sprintf("Note: %d, Pressure: %s", index1, value32(value))
+	case 0xB:
+		event = withMIDI1(midi.ControlChange(ch, index1, uint8(value>>25)), words)
+		event.Data = fmt.Sprintf("Controller: %d, Value: %s", index1, value32(value))
+	case 0xC:
+		event = withMIDI1(midi.ProgramChange(ch, b[4]), words)
+		event.Data = fmt.Sprintf("Program: %d", b[4])
+		if index2&0x01 != 0 {
+			event.Data += fmt.Sprintf(", Bank: %d/%d", b[6], b[7])
+		}
+		return event
+	case 0xD:
+		event = withMIDI1(midi.AfterTouch(ch, uint8(value>>25)), words)
+		event.Data = fmt.Sprintf("Pressure: %s", value32(value))
+	case 0xE:
+		event = withMIDI1(midi.Pitchbend(ch, int16(int32(value>>18)-8192)), words)
+		event.Data = fmt.Sprintf("Pitch Bend: %s", bend32(value))
+
+	default:
+		var name, data string
+		switch opcode {
+		case 0x0:
+			name, data = "Per-Note RPN", fmt.Sprintf("Note: %d, Controller: %d, Value: %s", index1, index2, value32(value))
+		case 0x1:
+			name, data = "Per-Note NRPN", fmt.Sprintf("Note: %d, Controller: %d, Value: %s", index1, index2, value32(value))
+		case 0x2:
+			name, data = "RPN", fmt.Sprintf("Bank: %d, Index: %d, Value: %s", index1, index2, value32(value))
+		case 0x3:
+			name, data = "NRPN", fmt.Sprintf("Bank: %d, Index: %d, Value: %s", index1, index2, value32(value))
+		case 0x4:
+			name, data = "Relative RPN", fmt.Sprintf("Bank: %d, Index: %d, Change: %+d", index1, index2, int32(value))
+		case 0x5:
+			name, data = "Relative NRPN", fmt.Sprintf("Bank: %d, Index: %d, Change: %+d", index1, index2, int32(value))
+		case 0x6:
+			name, data = "Per-Note Pitch Bend", fmt.Sprintf("Note: %d, Pitch Bend: %s", index1, bend32(value))
+		case 0xF:
+			var flags []string
+			if index2&0x02 != 0 {
+				flags = append(flags, "detach controllers")
+			}
+			if index2&0x01 != 0 {
+				flags = append(flags, "reset controllers")
+			}
+			if len(flags) == 0 {
+				flags = append(flags, "no action")
+			}
+			name, data = "Per-Note Management", fmt.Sprintf("Note: %d, %s", index1, strings.Join(flags, ", "))
+		default:
+			name, data = "Unknown", fmt.Sprintf("reserved MIDI 2.0 opcode %X", opcode)
+		}
+		event = umpEvent(words, name, data)
+		event.Channel = ch
+		if opcode == 0xF || name == "Unknown" {
+			return event
+		}
+	}
+
+	event.HighRes, event.HighResBits = value, 32
+	return event
+}
+
+// noteAttributeName names a MIDI 2.0 Note On/Off attribute type
+func noteAttributeName(attrType uint8) string {
+	switch attrType {
+	case 0x01:
+		return "Manufacturer Attribute"
+	case 0x02:
+		return "Profile Attribute"
+	case 0x03:
+		return "Pitch 7.9"
+	}
+	return fmt.Sprintf("Attribute %02X", attrType)
+}
+
+// value32 formats a 32-bit controller value with its percentage of full scale
+func value32(v uint32) string {
+	return fmt.Sprintf("%d (%.1f%%)", v, float64(v)*100/0xFFFFFFFF)
+}
+
+// bend32 formats a 32-bit pitch bend relative to its centre
+func bend32(v uint32) string {
+	return fmt.Sprintf("%+d", int64(v)-0x80000000)
+}
+
+func decodeData128(words []uint32) Event {
+	b := umpBytes(words)
+	status := b[1] >> 4
+	switch status {
+	case 0x8:
+		return umpEvent(words, "Mixed Data Set", fmt.Sprintf("header, MDS ID %d", b[1]&0xF))
+	case 0x9:
+		return umpEvent(words, "Mixed Data Set", fmt.Sprintf("payload, MDS ID %d", b[1]&0xF))
+	case 0x0, 0x1, 0x2, 0x3:
+		return umpEvent(words, "SysEx8", fmt.Sprintf("stream %d packet status %X (%s)", b[2], status, byteCount(int(b[1]&0xF))))
+	}
+	return umpEvent(words, "Unknown", fmt.Sprintf("reserved Data 128 status %X", status))
+}
+
+// decodeFlexData decodes the Flex Data messages for tempo, time and key signature, chords and text
+func decodeFlexData(words []uint32) Event {
+	b := umpBytes(words)
+	bank, status := b[2], b[3]
+
+	switch bank {
+	case 0x00:
+		switch status {
+		case 0x00:
+			// Tempo is in units of 10ns per quarter note
+			if words[1] == 0 {
+				return umpEvent(words, "Set Tempo", "0")
+			}
+			return umpEvent(words, "Set Tempo", fmt.Sprintf("%.2f BPM", 6e9/float64(words[1])))
+		case 0x01:
+			return umpEvent(words, "Set Time Signature", fmt.Sprintf("%d/%d", b[4], 1<<b[5]))
+		case 0x02:
+			return umpEvent(words, "Set Metronome", fmt.Sprintf("%d clocks per primary click", b[4]))
+		case 0x05:
+			return umpEvent(words, "Set Key Signature", fmt.Sprintf("%+d sharps, tonic %d", int8(b[4])>>4, b[4]&0xF))
+		case 0x06:
+			return umpEvent(words, "Set Chord Name", fmt.Sprintf("tonic %d, type %d", b[4]&0xF, b[5]))
+		}
+	case 0x01:
+		return umpEvent(words, "Performance Text", fmt.Sprintf("%q", umpText(b[4:])))
+	case 0x02:
+		return umpEvent(words, "Lyric", fmt.Sprintf("%q", umpText(b[4:])))
+	}
+	return umpEvent(words, "Flex Data", fmt.Sprintf("bank %02X status %02X", bank, status))
+}
+
+// decodeStream decodes UMP Stream messages: endpoint and function block discovery and notification
+func decodeStream(words []uint32) Event {
+	b := umpBytes(words)
+	status := uint16(words[0]>>16) & 0x3FF
+
+	switch status {
+	case 0x00:
+		return umpEvent(words, "Endpoint Discovery", fmt.Sprintf("UMP %d.%d, filter %08b", b[2], b[3], b[7]))
+	case 0x01:
+		var protocols []string
+		if b[6]&0x02 != 0 {
+			protocols = append(protocols, "MIDI 2.0")
+		}
+		if b[6]&0x01 != 0 {
+			protocols = append(protocols, "MIDI 1.0")
+		}
+		return umpEvent(words, "Endpoint Info", fmt.Sprintf("UMP %d.%d, %d function blocks, %s",
+			b[2], b[3], b[4]&0x7F, strings.Join(protocols, " + ")))
+	case 0x02:
+		reply := IdentityReply{
+			ManufacturerID: manufacturerIDBytes(b[5:8]),
+			Family:         uint16(uint14(b[8], b[9])),
+			Model:          uint16(uint14(b[10], b[11])),
+		}
+		copy(reply.Version[:], b[12:16])
+		return umpEvent(words, "Device Identity", reply.String())
+	case 0x03:
+		return umpEvent(words, "Endpoint Name", streamText(words[0], b[2:]))
+	case 0x04:
+		return umpEvent(words, "Product Instance ID", streamText(words[0], b[2:]))
+	case 0x05, 0x06:
+		name := "Stream Config Request"
+		if status == 0x06 {
+			name = "Stream Config"
+		}
+		protocol := fmt.Sprintf("protocol %02X", b[2])
+		switch b[2] {
+		case 0x01:
+			protocol = "MIDI 1.0"
+		case 0x02:
+			protocol = "MIDI 2.0"
+		}
+		return umpEvent(words, name, protocol)
+	case 0x10:
+		block := "all"
+		if b[2] != 0xFF {
+			block = fmt.Sprintf("%d", b[2])
+		}
+		return umpEvent(words, "Function Block Discovery", fmt.Sprintf("block %s, filter %02b", block, b[3]&0x3))
+	case 0x11:
+		active := "inactive"
+		if b[2]&0x80 != 0 {
+			active = "active"
+		}
+		direction := [...]string{"reserved", "input", "output", "bidirectional"}[b[3]&0x3]
+		return umpEvent(words, "Function Block Info", fmt.Sprintf("block %d %s %s, groups %d-%d",
+			b[2]&0x7F, active, direction, b[4]+1, int(b[4])+int(b[5])))
+	case 0x12:
+		return umpEvent(words, "Function Block Name", fmt.Sprintf("block %d: %s", b[2], streamText(words[0], b[3:])))
+	case 0x20:
+		return umpEvent(words, "Start of Clip", "")
+	case 0x21:
+		return umpEvent(words, "End of Clip", "")
+	}
+	return umpEvent(words, "UMP Stream", fmt.Sprintf("status %03X", status))
+}
+
+// manufacturerIDBytes turns the 3-byte manufacturer field of a UMP Device Identity into a SysEx ID
+func manufacturerIDBytes(b []byte) []byte {
+	if b[0] == 0x00 && b[1] == 0x00 {
+		return []byte{b[2]}
+	}
+	return []byte{b[0], b[1], b[2]}
+}
+
+// streamText formats a text fragment of a UMP Stream message, marking fragments of longer text
+func streamText(word0 uint32, b []byte) string {
+	text := fmt.Sprintf("%q", umpText(b))
+	switch word0 >> 26 & 0x3 {
+	case 0x1:
+		return text + "..."
+	case 0x2:
+		return "..." + text + "..."
+	case 0x3:
+		return "..." + text
+	}
+	return text
+}
+
+// umpText returns the text in packet bytes, which is padded with zeros
+func umpText(b []byte) string {
+	end := len(b)
+	for end > 0 && b[end-1] == 0 {
+		end--
+	}
+	return string(b[:end])
+}
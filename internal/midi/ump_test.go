@@ -0,0 +1,238 @@
+package midi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUMPSize(t *testing.T) {
+	sizes := map[uint8]int{0x0: 1, 0x2: 1, 0x3: 2, 0x4: 2, 0x5: 4, 0xB: 3, 0xD: 4, 0xF: 4}
+	for mt, want := range sizes {
+		if got := UMPSize(mt); got != want {
+			t.Errorf("UMPSize(%X) = %d; want %d", mt, got, want)
+		}
+	}
+}
+
+func TestDecodeUMPMIDI1(t *testing.T) {
+	// MIDI 1.0 Note On, group 3, channel 2, note 60, velocity 100
+	event := DecodeUMP([]uint32{0x23913C64})
+	if event.MessageType != "Note On" || event.Group != 3 || event.Channel != 1 {
+		t.Errorf("DecodeUMP() = %s group %d ch %d; want Note On group 3 ch 1", event.MessageType, event.Group, event.Channel)
+	}
+	if !bytes.Equal(event.Message, []byte{0x91, 0x3C, 0x64}) {
+		t.Errorf("DecodeUMP() Message = % X; want 91 3C 64", event.Message)
+	}
+	if !bytes.Equal(event.RawBytes, []byte{0x23, 0x91, 0x3C, 0x64}) {
+		t.Errorf("DecodeUMP() RawBytes = % X; want the packet", event.RawBytes)
+	}
+
+	// System realtime clock
+	if event := DecodeUMP([]uint32{0x10F80000}); event.MessageType != "Clock" {
+		t.Errorf("DecodeUMP() = %s; want Clock", event.MessageType)
+	}
+}
+
+func TestDecodeUMPMIDI2Voice(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    []uint32
+		wantType string
+		wantMsg  []byte
+		wantHigh uint32
+		wantBits uint8
+		wantData string
+	}{
+		{
+			"Note On with 16-bit velocity",
+			[]uint32{0x40903C00, 0xC8000000},
+			"Note On", []byte{0x90, 0x3C, 0x64}, 0xC800, 16,
+			"Note: 60, Velocity: 51200/65535",
+		},
+		{
+			"Note On with tiny velocity is still a Note On",
+			[]uint32{0x40903C00, 0x00010000},
+			"Note On", []byte{0x90, 0x3C, 0x01}, 1, 16,
+			"Note: 60, Velocity: 1/65535",
+		},
+		{
+			"Note Off with pitch attribute",
+			[]uint32{0x40803C03, 0x80007800},
+			"Note Off", []byte{0x80, 0x3C, 0x00}, 0x8000, 16,
+			"Note: 60, Velocity: 32768/65535, Pitch 7.9: 30720",
+		},
+		{
+			"32-bit CC",
+			[]uint32{0x40B50700, 0xFFFFFFFF},
+			"CC", []byte{0xB5, 0x07, 0x7F}, 0xFFFFFFFF, 32,
+			"Controller: 7, Value: 4294967295 (100.0%)",
+		},
+		{
+			"Pitch bend centre",
+			[]uint32{0x40E00000, 0x80000000},
+			"Pitch Bend", []byte{0xE0, 0x00, 0x40}, 0x80000000, 32,
+			"Pitch Bend: +0",
+		},
+		{
+			"Program change with bank",
+			[]uint32{0x40C00001, 0x05000102},
+			"Program Change", []byte{0xC0, 0x05}, 0, 0,
+			"Program: 5, Bank: 1/2",
+		},
+		{
+			"Per-note pitch bend",
+			[]uint32{0x40603C00, 0x80001000},
+			"Per-Note Pitch Bend", nil, 0x80001000, 32,
+			"Note: 60, Pitch Bend: +4096",
+		},
+		{
+			"Registered per-note controller",
+			[]uint32{0x40003C07, 0x40000000},
+			"Per-Note RPN", nil, 0x40000000, 32,
+			"Note: 60, Controller: 7, Value: 1073741824 (25.0%)",
+		},
+		{
+			"RPN",
+			[]uint32{0x40200000, 0x10000000},
+			"RPN", nil, 0x10000000, 32,
+			"Bank: 0, Index: 0, Value: 268435456 (6.3%)",
+		},
+		{
+			"Per-note management",
+			[]uint32{0x40F03C03, 0x00000000},
+			"Per-Note Management", nil, 0, 0,
+			"Note: 60, detach controllers, reset controllers",
+		},
+	}
+
+	for _, tt := range tests {
+		event := DecodeUMP(tt.words)
+		if event.MessageType != tt.wantType || event.Data != tt.wantData {
+			t.Errorf("%s: DecodeUMP() = %s %q; want %s %q", tt.name, event.MessageType, event.Data, tt.wantType, tt.wantData)
+		}
+		if !bytes.Equal(event.Message, tt.wantMsg) {
+			t.Errorf("%s: Message = % X; want % X", tt.name, event.Message, tt.wantMsg)
+		}
+		if event.HighRes != tt.wantHigh || event.HighResBits != tt.wantBits {
+			t.Errorf("%s: HighRes = %d (%d bits); want %d (%d bits)", tt.name, event.HighRes, event.HighResBits, tt.wantHigh, tt.wantBits)
+		}
+	}
+}
+
+func TestDecodeUMPStream(t *testing.T) {
+	tests := []struct {
+		words    []uint32
+		wantType string
+		wantData string
+	}{
+		{[]uint32{0xF0000101, 0x0000001F, 0, 0}, "Endpoint Discovery", "UMP 1.1, filter 00011111"},
+		{[]uint32{0xF0010101, 0x83000300, 0, 0}, "Endpoint Info", "UMP 1.1, 3 function blocks, MIDI 2.0 + MIDI 1.0"},
+		{[]uint32{0xF0020000, 0x00000041, 0x42000300, 0x01000000}, "Device Identity", "Roland family 0042 model 0003 ver 01 00 00 00"},
+		{[]uint32{0xF0034B65, 0x79626F61, 0x72640000, 0}, "Endpoint Name", `"Keyboard"`},
+		{[]uint32{0xF4034D79, 0x20566572, 0x79204C6F, 0x6E672053}, "Endpoint Name", `"My Very Long S"...`},
+		{[]uint32{0xF0060200, 0, 0, 0}, "Stream Config", "MIDI 2.0"},
+		{[]uint32{0xF010FF03, 0, 0, 0}, "Function Block Discovery", "block all, filter 11"},
+		{[]uint32{0xF0118202, 0x00020100, 0, 0}, "Function Block Info", "block 2 active output, groups 1-2"},
+		{[]uint32{0xF0120150, 0x6164732E, 0, 0}, "Function Block Name", `block 1: "Pads."`},
+	}
+
+	for _, tt := range tests {
+		event := DecodeUMP(tt.words)
+		if event.MessageType != tt.wantType || event.Data != tt.wantData {
+			t.Errorf("DecodeUMP(%08X) = %s %q; want %s %q", tt.words, event.MessageType, event.Data, tt.wantType, tt.wantData)
+		}
+	}
+}
+
+func TestDecodeUMPFlexData(t *testing.T) {
+	// 120 BPM is 50,000,000 units of 10ns per quarter note
+	if event := DecodeUMP([]uint32{0xD0100000, 50000000, 0, 0}); event.Data != "120.00 BPM" {
+		t.Errorf("Set Tempo Data = %q; want 120.00 BPM", event.Data)
+	}
+	if event := DecodeUMP([]uint32{0xD0100001, 0x06030800, 0, 0}); event.Data != "6/8" {
+		t.Errorf("Set Time Signature Data = %q; want 6/8", event.Data)
+	}
+	if event := DecodeUMP([]uint32{0xD0100201, 0x48690000, 0, 0}); event.MessageType != "Lyric" || event.Data != `"Hi"` {
+		t.Errorf("Lyric = %s %q", event.MessageType, event.Data)
+	}
+}
+
+func TestParseUMPSysEx7(t *testing.T) {
+	// Identity Request (7E 7F 06 01) split over a start and end packet, on group 2,
+	// with a NOOP and a MIDI 2.0 Note On between them
+	data := []byte{
+		0x32, 0x13, 0x7E, 0x7F, 0x06, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+		0x40, 0x90, 0x3C, 0x00, 0xC8, 0x00, 0x00, 0x00,
+		0x32, 0x31, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	p := NewUMPParser()
+	at := time.Now()
+	var events []Event
+	// Feed a byte at a time: packets may span calls
+	for _, b := range data {
+		events = append(events, p.Feed([]byte{b}, at)...)
+	}
+	events = append(events, p.Flush(at)...)
+
+	got := summarise(events)
+	if strings.Join(got, ", ") != "Note On, SysEx" {
+		t.Fatalf("ParseUMP() = %v; want [Note On SysEx]", got)
+	}
+	sysex := events[1]
+	if !bytes.Equal(sysex.RawBytes, []byte{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7}) || sysex.Group != 2 {
+		t.Errorf("SysEx = % X group %d; want F0 7E 7F 06 01 F7 group 2", sysex.RawBytes, sysex.Group)
+	}
+	if sysex.Data != "Universal Non-RT: Identity Request" {
+		t.Errorf("SysEx Data = %q", sysex.Data)
+	}
+	if !sysex.Timestamp.Equal(at) {
+		t.Errorf("SysEx Timestamp = %v; want %v", sysex.Timestamp, at)
+	}
+}
+
+func TestParseUMPSysEx8(t *testing.T) {
+	// Complete SysEx8 on stream 5: 16-bit manufacturer 00 41 (Roland) and two data bytes
+	data := []byte{
+		0x50, 0x05, 0x05, 0x00, 0x41, 0x12, 0x34, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	events := ParseUMP(data, time.Now())
+	if len(events) != 1 || events[0].MessageType != "SysEx8" || events[0].Data != "stream 5, Roland (2 bytes)" {
+		t.Errorf("ParseUMP() = %v %q", summarise(events), events[0].Data)
+	}
+}
+
+func TestParseUMPErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{
+			"incomplete packet",
+			[]byte{0x40, 0x90, 0x3C, 0x00, 0xC8},
+			[]string{"Error: incomplete UMP packet: got 5 of 8 bytes"},
+		},
+		{
+			"SysEx7 end without start",
+			[]byte{0x30, 0x31, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]string{"Error: SysEx7 continue/end on group 1 with no start"},
+		},
+		{
+			"SysEx7 never ended",
+			[]byte{0x30, 0x12, 0x7E, 0x7F, 0x00, 0x00, 0x00, 0x00},
+			[]string{"Error: SysEx7 on group 1 missing its end packet after 2 bytes"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := summarise(ParseUMP(tt.data, time.Now()))
+		if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+			t.Errorf("%s: ParseUMP() = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/smf"
+	"midi-viewer/internal/midi"
+)
+
+// LoadCapture reads a saved capture, oldest event first. The format comes from the extension:
+// a Standard MIDI File (.mid, .midi, .smf), SysEx (.syx), Universal MIDI Packets (.ump) or
+// JSON Lines (.jsonl, .json, .ndjson); anything else is read as JSON Lines if it starts with
+// "{" and as a hex dump otherwise. .syx and .ump files and hex dumps carry no timing, so their
+// events are all stamped with the file's modification time.
+func LoadCapture(path string) ([]midi.Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open capture: %w", err)
+	}
+
+	var modified time.Time
+	if info, err := os.Stat(path); err == nil {
+		modified = info.ModTime()
+	}
+
+	var events []midi.Event
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mid", ".midi", ".smf":
+		events, err = readSMF(bytes.NewReader(data))
+	case ".syx":
+		events, err = readSyxCapture(bytes.NewReader(data), modified)
+	case ".ump":
+		events = midi.ParseUMP(data, modified)
+	case ".jsonl", ".json", ".ndjson":
+		events, err = ReadEvents(bytes.NewReader(data))
+	default:
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			events, err = ReadEvents(bytes.NewReader(data))
+		} else {
+			events, err = ReadHexDump(bytes.NewReader(data), modified)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", filepath.Base(path), err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no MIDI messages in %s", filepath.Base(path))
+	}
+	return events, nil
+}
+
+// readSMF reads the playable messages of all tracks of a Standard MIDI File, merged
+// in time order with tempo changes applied. Times count from the zero time.
+func readSMF(r io.Reader) ([]midi.Event, error) {
+	tracks := smf.ReadTracksFrom(r)
+	var events []midi.Event
+	tracks.Do(func(te smf.TrackEvent) {
+		if !te.Message.IsPlayable() {
+			return
+		}
+		event := midi.ParseMessage(gomidi.Message(te.Message.Bytes()))
+		event.Timestamp = time.Time{}.Add(time.Duration(te.AbsMicroSeconds) * time.Microsecond)
+		events = append(events, event)
+	})
+	if err := tracks.Error(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	return events, nil
+}
+
+// readSyxCapture reads the SysEx messages of a .syx stream as events stamped with at, the
+// file's modification time, since .syx files carry no timing
+func readSyxCapture(r io.Reader, at time.Time) ([]midi.Event, error) {
+	msgs, err := midi.ReadSyx(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]midi.Event, len(msgs))
+	for i, msg := range msgs {
+		events[i] = midi.ParseMessage(gomidi.Message(msg))
+		events[i].Timestamp = at
+	}
+	return events, nil
+}
+
+// ReadHexDump reads a MIDI byte stream written as hex, such as bytes pasted from a log or a
+// bug report: pairs of hex digits separated by spaces, commas or new lines, optionally with
+// 0x prefixes. Text after a # is a comment. The bytes are parsed like a serial stream, so
+// running status, SysEx and malformed input are handled. Hex dumps carry no timing, so all
+// events are stamped with at, such as the file's modification time.
+func ReadHexDump(r io.Reader, at time.Time) ([]midi.Event, error) {
+	var data []byte
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		})
+		for _, field := range fields {
+			field = strings.TrimPrefix(strings.TrimPrefix(field, "0x"), "0X")
+			b, err := strconv.ParseUint(field, 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q is not a hex byte", line, field)
+			}
+			data = append(data, byte(b))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return midi.Parse(data, at), nil
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/smf"
+	"midi-viewer/internal/midi"
+)
+
+func TestLoadCaptureSMF(t *testing.T) {
+	s := smf.New()
+	var track smf.Track
+	track.Add(0, smf.MetaTempo(120))
+	track.Add(0, gomidi.NoteOn(0, 60, 100))
+	track.Add(960, gomidi.NoteOff(0, 60)) // one beat at 120 bpm with 960 ticks per beat
+	track.Close(0)
+	s.Add(track)
+
+	path := filepath.Join(t.TempDir(), "capture.mid")
+	if err := s.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	events, err := LoadCapture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("loaded %d events; want 2 (meta events are not playable)", len(events))
+	}
+	if gap := events[1].Timestamp.Sub(events[0].Timestamp); gap != 500*time.Millisecond {
+		t.Errorf("Note Off is %v after the Note On; want 500ms", gap)
+	}
+}
+
+func TestLoadCaptureSMFExportRoundTrip(t *testing.T) {
+	s := smf.New()
+	var track smf.Track
+	track.Add(0, gomidi.NoteOn(0, 60, 100)) // at the zero time
+	track.Add(960, gomidi.NoteOff(0, 60))
+	track.Close(0)
+	s.Add(track)
+
+	path := filepath.Join(t.TempDir(), "capture.mid")
+	if err := s.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	events, err := LoadCapture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := WriteEvents(&out, events, ExportJSONL); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadEvents(&out)
+	if err != nil {
+		t.Fatalf("ReadEvents() of the export error = %v", err)
+	}
+	if len(read) != len(events) {
+		t.Fatalf("read back %d events; want %d", len(read), len(events))
+	}
+	for i := range events {
+		if !read[i].Timestamp.Equal(events[i].Timestamp) || read[i].MessageType != events[i].MessageType {
+			t.Errorf("event %d read back as %s at %v; want %s at %v", i,
+				read[i].MessageType, read[i].Timestamp, events[i].MessageType, events[i].Timestamp)
+		}
+	}
+}
+
+func TestLoadCaptureSyx(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patch.syx")
+	if err := midi.SaveSyx(path, [][]byte{{0xF0, 0x41, 0x10, 0xF7}, {0xF0, 0x43, 0x00, 0xF7}}); err != nil {
+		t.Fatal(err)
+	}
+	events, err := LoadCapture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].MessageType != "SysEx" || events[0].Timestamp.IsZero() {
+		t.Errorf("loaded %+v; want 2 SysEx events stamped with the file time", events)
+	}
+}
+
+func TestLoadCaptureUMP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.ump")
+	packets := []byte{
+		0x20, 0x90, 0x3C, 0x64, // MIDI 1.0 Note On, group 0
+		0x41, 0x90, 0x3C, 0x00, 0xFF, 0xFF, 0x00, 0x00, // MIDI 2.0 Note On, group 1
+	}
+	if err := os.WriteFile(path, packets, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	events, err := LoadCapture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].MessageType != "Note On" || events[1].MessageType != "Note On" {
+		t.Fatalf("loaded %+v; want 2 Note On events", events)
+	}
+	if events[1].Group != 1 || events[1].HighResBits != 16 || events[0].Timestamp.IsZero() {
+		t.Errorf("loaded %+v; want the MIDI 2.0 note in group 1 at full resolution, stamped with the file time", events[1])
+	}
+}
+
+func TestLoadCaptureByContent(t *testing.T) {
+	dir := t.TempDir()
+
+	hexPath := filepath.Join(dir, "from-customer.txt")
+	os.WriteFile(hexPath, []byte("# Note On, running status Note On\n90 3C 64 3E 64\n0xB0,0x07,0x5A\n"), 0o644)
+	events, err := LoadCapture(hexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || !events[1].RunningStatus || events[2].MessageType != "CC" {
+		t.Errorf("hex dump = %d events; want 2 Note Ons (the second with running status) and a CC", len(events))
+	}
+
+	jsonPath := filepath.Join(dir, "capture.log")
+	os.WriteFile(jsonPath, []byte(`{"time":"2024-05-01T12:00:00Z","type":"CC","data":"","raw":"B0 07 5A"}`+"\n"), 0o644)
+	events, err = LoadCapture(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].MessageType != "CC" {
+		t.Errorf("JSON Lines without extension = %+v; want 1 CC", events)
+	}
+}
+
+func TestLoadCaptureErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadCapture(filepath.Join(dir, "missing.jsonl")); err == nil {
+		t.Error("a missing file should be an error")
+	}
+
+	bad := filepath.Join(dir, "bad.hex")
+	os.WriteFile(bad, []byte("90 3C zz\n"), 0o644)
+	if _, err := LoadCapture(bad); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("bad hex error = %v; want one naming line 1", err)
+	}
+
+	empty := filepath.Join(dir, "empty.hex")
+	os.WriteFile(empty, []byte("# nothing\n"), 0o644)
+	if _, err := LoadCapture(empty); err == nil {
+		t.Error("a capture without messages should be an error")
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"sort"
+
+	"midi-viewer/internal/midi"
+)
+
+// ControllerState holds the last-seen controller, pitch bend and aftertouch values per channel
+type ControllerState struct {
+	cc         map[uint8]map[uint8]uint8 // channel -> controller -> value
+	pitchBend  map[uint8]int16           // channel -> relative pitch bend (-8192..8191)
+	aftertouch map[uint8]uint8           // channel -> channel pressure
+}
+
+// NewControllerState creates an empty controller state
+func NewControllerState() ControllerState {
+	return ControllerState{
+		cc:         make(map[uint8]map[uint8]uint8),
+		pitchBend:  make(map[uint8]int16),
+		aftertouch: make(map[uint8]uint8),
+	}
+}
+
+// Track records the value carried by a CC, Pitch Bend or Aftertouch event
+func (c *ControllerState) Track(event midi.Event) {
+	var ch, controller, value, pressure uint8
+	var rel int16
+	var abs uint16
+
+	switch {
+	case event.Message.GetControlChange(&ch, &controller, &value):
+		if c.cc[ch] == nil {
+			c.cc[ch] = make(map[uint8]uint8)
+		}
+		c.cc[ch][controller] = value
+	case event.Message.GetPitchBend(&ch, &rel, &abs):
+		c.pitchBend[ch] = rel
+	case event.Message.GetAfterTouch(&ch, &pressure):
+		c.aftertouch[ch] = pressure
+	}
+}
+
+// Reset forgets all tracked values
+func (c *ControllerState) Reset() {
+	*c = NewControllerState()
+}
+
+// Channels returns the channels that have sent any tracked value, in ascending order
+func (c ControllerState) Channels() []uint8 {
+	seen := make(map[uint8]bool)
+	for ch := range c.cc {
+		seen[ch] = true
+	}
+	for ch := range c.pitchBend {
+		seen[ch] = true
+	}
+	for ch := range c.aftertouch {
+		seen[ch] = true
+	}
+
+	channels := make([]uint8, 0, len(seen))
+	for ch := range seen {
+		channels = append(channels, ch)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i] < channels[j] })
+
+	return channels
+}
+
+// Controllers returns the controllers seen on a channel, in ascending order
+func (c ControllerState) Controllers(ch uint8) []uint8 {
+	controllers := make([]uint8, 0, len(c.cc[ch]))
+	for controller := range c.cc[ch] {
+		controllers = append(controllers, controller)
+	}
+	sort.Slice(controllers, func(i, j int) bool { return controllers[i] < controllers[j] })
+
+	return controllers
+}
+
+// Value returns the last value of a controller on a channel
+func (c ControllerState) Value(ch, controller uint8) (uint8, bool) {
+	value, ok := c.cc[ch][controller]
+	return value, ok
+}
+
+// PitchBend returns the last pitch bend value on a channel
+func (c ControllerState) PitchBend(ch uint8) (int16, bool) {
+	value, ok := c.pitchBend[ch]
+	return value, ok
+}
+
+// Aftertouch returns the last channel pressure value on a channel
+func (c ControllerState) Aftertouch(ch uint8) (uint8, bool) {
+	value, ok := c.aftertouch[ch]
+	return value, ok
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+func TestControllerStateTrack(t *testing.T) {
+	state := NewControllerState()
+
+	state.Track(midi.ParseMessage(gomidi.ControlChange(0, 7, 100)))
+	state.Track(midi.ParseMessage(gomidi.ControlChange(0, 1, 20)))
+	state.Track(midi.ParseMessage(gomidi.ControlChange(0, 7, 90)))
+	state.Track(midi.ParseMessage(gomidi.Pitchbend(2, -100)))
+	state.Track(midi.ParseMessage(gomidi.AfterTouch(3, 64)))
+	state.Track(midi.ParseMessage(gomidi.NoteOn(4, 60, 100)))
+
+	if value, ok := state.Value(0, 7); !ok || value != 90 {
+		t.Errorf("Value(0, 7) = %d, %v; want 90, true", value, ok)
+	}
+
+	controllers := state.Controllers(0)
+	if len(controllers) != 2 || controllers[0] != 1 || controllers[1] != 7 {
+		t.Errorf("Controllers(0) = %v; want [1 7]", controllers)
+	}
+
+	if bend, ok := state.PitchBend(2); !ok || bend != -100 {
+		t.Errorf("PitchBend(2) = %d, %v; want -100, true", bend, ok)
+	}
+
+	if pressure, ok := state.Aftertouch(3); !ok || pressure != 64 {
+		t.Errorf("Aftertouch(3) = %d, %v; want 64, true", pressure, ok)
+	}
+
+	channels := state.Channels()
+	if len(channels) != 3 || channels[0] != 0 || channels[1] != 2 || channels[2] != 3 {
+		t.Errorf("Channels() = %v; want [0 2 3]", channels)
+	}
+}
+
+func TestControllerStateReset(t *testing.T) {
+	state := NewControllerState()
+	state.Track(midi.ParseMessage(gomidi.ControlChange(0, 7, 100)))
+
+	state.Reset()
+
+	if len(state.Channels()) != 0 {
+		t.Errorf("Channels() after Reset = %v; want none", state.Channels())
+	}
+}
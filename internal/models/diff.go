@@ -0,0 +1,277 @@
+package models
+
+import (
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// DiffTolerances are the selectable timing tolerances of a capture diff, in cycling order;
+// 0 ignores timing
+var DiffTolerances = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	0,
+}
+
+// DefaultDiffTolerance is the timing tolerance a diff starts with
+const DefaultDiffTolerance = 10 * time.Millisecond
+
+// DiffKind says how an aligned line of two captures differs
+type DiffKind int
+
+const (
+	DiffSame     DiffKind = iota // the same message in both
+	DiffChanged                  // a message of the same type and channel with different data
+	DiffMissing                  // only in capture A: missing from B
+	DiffInserted                 // only in capture B: inserted since A
+)
+
+// DiffLine is one aligned line of two captures
+type DiffLine struct {
+	Kind  DiffKind
+	A, B  int           // indexes into the compared events (-1 = none)
+	Delta time.Duration // B's offset from its first event minus A's, for same and changed lines
+	Late  bool          // Delta is beyond the tolerance
+}
+
+// CaptureDiff compares two captures message by message, e.g. the output of two firmware builds
+// for the same test sequence. Events are aligned by their bytes, so timing differences do not
+// affect the alignment; they are reported separately when beyond the tolerance.
+type CaptureDiff struct {
+	NameA, NameB       string
+	CaptureA, CaptureB []midi.Event
+	Tolerance          time.Duration
+
+	A, B    []midi.Event // the compared events: those of each capture the filter shows
+	Lines   []DiffLine
+	Untimed bool // a capture has no timing (e.g. a hex dump), so timing is not compared
+	// The captures differ too much to align: after their common start and end, events
+	// are paired by position
+	Positional bool
+
+	Same, Changed, Missing, Inserted, Late int
+}
+
+// NewCaptureDiff creates a diff of two captures; call Compare to align them
+func NewCaptureDiff(nameA string, a []midi.Event, nameB string, b []midi.Event) CaptureDiff {
+	return CaptureDiff{NameA: nameA, CaptureA: a, NameB: nameB, CaptureB: b, Tolerance: DefaultDiffTolerance}
+}
+
+// CycleTolerance advances the timing tolerance to the next selectable value and re-checks timing
+func (d *CaptureDiff) CycleTolerance() {
+	next := DiffTolerances[0]
+	for i, tolerance := range DiffTolerances {
+		if tolerance == d.Tolerance {
+			next = DiffTolerances[(i+1)%len(DiffTolerances)]
+			break
+		}
+	}
+	d.Tolerance = next
+	d.checkTiming()
+}
+
+// Compare aligns the events of both captures that the filter shows
+func (d *CaptureDiff) Compare(filter Filter) {
+	d.A, d.B = filterEvents(d.CaptureA, filter), filterEvents(d.CaptureB, filter)
+	d.Lines = nil
+
+	keysA, keysB := diffKeys(d.A), diffKeys(d.B)
+	matches, ok := alignSequences(keysA, keysB)
+	d.Positional = !ok
+	if !ok {
+		matches = alignEnds(keysA, keysB)
+	}
+	i, j := 0, 0
+	for _, match := range matches {
+		d.addUnmatched(i, match[0], j, match[1])
+		d.Lines = append(d.Lines, DiffLine{Kind: DiffSame, A: match[0], B: match[1]})
+		i, j = match[0]+1, match[1]+1
+	}
+	d.addUnmatched(i, len(d.A), j, len(d.B))
+
+	d.checkTiming()
+}
+
+// addUnmatched adds the lines for events A[i:endA] and B[j:endB] between two matches.
+// Events at the same position of the same type and channel are paired as changed.
+func (d *CaptureDiff) addUnmatched(i, endA, j, endB int) {
+	for ; i < endA || j < endB; i, j = i+1, j+1 {
+		switch {
+		case i < endA && j < endB && d.A[i].MessageType == d.B[j].MessageType && d.A[i].Channel == d.B[j].Channel:
+			d.Lines = append(d.Lines, DiffLine{Kind: DiffChanged, A: i, B: j})
+		case i < endA && j < endB:
+			d.Lines = append(d.Lines, DiffLine{Kind: DiffMissing, A: i, B: -1}, DiffLine{Kind: DiffInserted, A: -1, B: j})
+		case i < endA:
+			d.Lines = append(d.Lines, DiffLine{Kind: DiffMissing, A: i, B: -1})
+		default:
+			d.Lines = append(d.Lines, DiffLine{Kind: DiffInserted, A: -1, B: j})
+		}
+	}
+}
+
+// checkTiming compares the offsets of paired events with the tolerance and recounts the lines
+func (d *CaptureDiff) checkTiming() {
+	d.Same, d.Changed, d.Missing, d.Inserted, d.Late = 0, 0, 0, 0, 0
+	d.Untimed = untimed(d.A) || untimed(d.B)
+	for i := range d.Lines {
+		line := &d.Lines[i]
+		switch line.Kind {
+		case DiffSame, DiffChanged:
+			line.Delta = d.B[line.B].Timestamp.Sub(d.B[0].Timestamp) - d.A[line.A].Timestamp.Sub(d.A[0].Timestamp)
+			line.Late = !d.Untimed && d.Tolerance > 0 && (line.Delta > d.Tolerance || line.Delta < -d.Tolerance)
+			if line.Late {
+				d.Late++
+			}
+			if line.Kind == DiffSame {
+				d.Same++
+			} else {
+				d.Changed++
+			}
+		case DiffMissing:
+			d.Missing++
+		case DiffInserted:
+			d.Inserted++
+		}
+	}
+}
+
+// Identical reports whether both captures have the same messages within the timing tolerance
+func (d CaptureDiff) Identical() bool {
+	return d.Changed == 0 && d.Missing == 0 && d.Inserted == 0 && d.Late == 0
+}
+
+// NextDifference returns the index of the next line after from (or before it, if backwards)
+// that is not the same within the tolerance, or -1 if there is none
+func (d CaptureDiff) NextDifference(from int, backwards bool) int {
+	step := 1
+	if backwards {
+		step = -1
+	}
+	for i := from + step; i >= 0 && i < len(d.Lines); i += step {
+		if d.Lines[i].Kind != DiffSame || d.Lines[i].Late {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterEvents returns the MIDI events the filter shows; markers are notes about a capture,
+// not part of what was sent, so they are left out
+func filterEvents(events []midi.Event, filter Filter) []midi.Event {
+	var shown []midi.Event
+	for _, event := range events {
+		if event.MessageType != midi.MarkerMessageType && filter.ShouldShow(event) {
+			shown = append(shown, event)
+		}
+	}
+	return shown
+}
+
+// untimed reports whether events carry no timing: several events all stamped at the same time
+func untimed(events []midi.Event) bool {
+	return len(events) > 1 && events[len(events)-1].Timestamp.Equal(events[0].Timestamp)
+}
+
+// diffKeys returns the bytes of each event as a comparable key
+func diffKeys(events []midi.Event) []string {
+	keys := make([]string, len(events))
+	for i, event := range events {
+		keys[i] = string(event.RawBytes)
+	}
+	return keys
+}
+
+// maxAlignEdits limits the differences alignSequences looks through. Backtracking keeps a
+// row per difference, so memory grows with the square of the differences: about 8MB here.
+const maxAlignEdits = 1000
+
+// alignSequences returns the index pairs of a longest common subsequence of a and b, in order,
+// using Myers' O((N+M)D) algorithm, which is fast when the sequences are mostly the same.
+// It gives up, returning false, when they differ by more than maxAlignEdits.
+func alignSequences(a, b []string) ([][2]int, bool) {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+
+	// trace[d] holds v for diagonals -d..d before round d, for backtracking
+	var trace [][]int
+	found := -1
+	for d := 0; d <= n+m && found < 0; d++ {
+		if d > maxAlignEdits {
+			return nil, false
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down: an insertion
+			} else {
+				x = v[offset+k-1] + 1 // right: a deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = d
+				break
+			}
+		}
+	}
+
+	var pairs [][2]int
+	x, y := n, m
+	for d := found; d > 0; d-- {
+		prev := trace[d] // diagonal k is at index k+d
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[k-1+d] < prev[k+1+d]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[prevK+d]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			pairs = append(pairs, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		pairs = append(pairs, [2]int{x, y})
+	}
+
+	for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	}
+	return pairs, true
+}
+
+// alignEnds returns the index pairs of the events a and b start and end with in common,
+// for sequences too different to align
+func alignEnds(a, b []string) [][2]int {
+	var pairs [][2]int
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		pairs = append(pairs, [2]int{start, start})
+		start++
+	}
+	end := 0
+	for end < len(a)-start && end < len(b)-start && a[len(a)-1-end] == b[len(b)-1-end] {
+		end++
+	}
+	for k := end; k > 0; k-- {
+		pairs = append(pairs, [2]int{len(a) - k, len(b) - k})
+	}
+	return pairs
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+// diffCapture stamps messages 10ms apart, starting at start
+func diffCapture(start time.Time, msgs ...gomidi.Message) []midi.Event {
+	events := make([]midi.Event, len(msgs))
+	for i, msg := range msgs {
+		events[i] = noteEvent(msg, start.Add(time.Duration(i)*10*time.Millisecond))
+	}
+	return events
+}
+
+func TestCaptureDiffIdentical(t *testing.T) {
+	msgs := []gomidi.Message{gomidi.NoteOn(0, 60, 100), gomidi.ControlChange(0, 7, 90), gomidi.NoteOff(0, 60)}
+	// Captured at different times of day: only the timing within each capture matters
+	d := NewCaptureDiff("a", diffCapture(time.Now(), msgs...), "b", diffCapture(time.Now().Add(time.Hour), msgs...))
+	d.Compare(NewFilter())
+
+	if !d.Identical() || d.Same != 3 || len(d.Lines) != 3 {
+		t.Errorf("diff = %d same, %d changed, %d missing, %d inserted, %d late; want 3 same", d.Same, d.Changed, d.Missing, d.Inserted, d.Late)
+	}
+	if d.NextDifference(-1, false) != -1 {
+		t.Error("identical captures should have no differences")
+	}
+}
+
+func TestCaptureDiffAlignment(t *testing.T) {
+	start := time.Now()
+	a := diffCapture(start,
+		gomidi.NoteOn(0, 60, 100),
+		gomidi.ControlChange(0, 7, 90),
+		gomidi.ProgramChange(0, 5),
+		gomidi.NoteOff(0, 60),
+	)
+	b := diffCapture(start,
+		gomidi.NoteOn(0, 60, 100),
+		gomidi.ControlChange(0, 7, 91), // changed value
+		gomidi.NoteOff(0, 60),          // program change missing
+		gomidi.NoteOn(1, 64, 80),       // inserted
+	)
+	b[2].Timestamp = a[3].Timestamp // keep the Note Off on time
+
+	d := NewCaptureDiff("a", a, "b", b)
+	d.Compare(NewFilter())
+
+	want := []DiffKind{DiffSame, DiffChanged, DiffMissing, DiffSame, DiffInserted}
+	if len(d.Lines) != len(want) {
+		t.Fatalf("diff has %d lines; want %d: %+v", len(d.Lines), len(want), d.Lines)
+	}
+	for i, kind := range want {
+		if d.Lines[i].Kind != kind {
+			t.Errorf("line %d is kind %d; want %d", i, d.Lines[i].Kind, kind)
+		}
+	}
+	if d.Changed != 1 || d.Missing != 1 || d.Inserted != 1 || d.Late != 0 {
+		t.Errorf("counts = %d changed, %d missing, %d inserted, %d late; want 1, 1, 1, 0", d.Changed, d.Missing, d.Inserted, d.Late)
+	}
+	if next := d.NextDifference(0, false); next != 1 {
+		t.Errorf("NextDifference(0) = %d; want 1", next)
+	}
+	if prev := d.NextDifference(4, true); prev != 2 {
+		t.Errorf("NextDifference(4, backwards) = %d; want 2", prev)
+	}
+}
+
+func TestCaptureDiffTiming(t *testing.T) {
+	start := time.Now()
+	msgs := []gomidi.Message{gomidi.NoteOn(0, 60, 100), gomidi.NoteOff(0, 60)}
+	a := diffCapture(start, msgs...)
+	b := diffCapture(start, msgs...)
+	b[1].Timestamp = b[1].Timestamp.Add(8 * time.Millisecond)
+
+	d := NewCaptureDiff("a", a, "b", b)
+	d.Compare(NewFilter())
+	if d.Late != 0 || d.Lines[1].Delta != 8*time.Millisecond {
+		t.Errorf("8ms late within 10ms tolerance: %d late, delta %v", d.Late, d.Lines[1].Delta)
+	}
+
+	d.Tolerance = 0
+	d.CycleTolerance() // wraps from ignoring timing to 1ms
+	if d.Late != 1 || !d.Lines[1].Late || d.Identical() {
+		t.Errorf("8ms late beyond 1ms tolerance: %d late", d.Late)
+	}
+	if d.NextDifference(-1, false) != 1 {
+		t.Error("a late line should be a difference")
+	}
+
+	// A hex dump has every event at the same time, so its timing is not compared
+	for i := range b {
+		b[i].Timestamp = start
+	}
+	d = NewCaptureDiff("a", a, "b", b)
+	d.Compare(NewFilter())
+	if !d.Untimed || !d.Identical() {
+		t.Errorf("untimed capture: Untimed = %v, %d late; want true, 0", d.Untimed, d.Late)
+	}
+}
+
+func TestCaptureDiffFilter(t *testing.T) {
+	start := time.Now()
+	a := diffCapture(start, gomidi.NoteOn(0, 60, 100), gomidi.TimingClock(), gomidi.NoteOff(0, 60))
+	b := diffCapture(start, gomidi.NoteOn(0, 60, 100), gomidi.NoteOff(0, 60))
+	b[1].Timestamp = a[2].Timestamp
+
+	filter := NewFilter()
+	filter.ToggleMessageType("Clock")
+	d := NewCaptureDiff("a", a, "b", b)
+	d.Compare(filter)
+	if !d.Identical() {
+		t.Errorf("with clock hidden the captures should match: %+v", d.Lines)
+	}
+}
+
+func TestAlignSequences(t *testing.T) {
+	// Compare with a plain dynamic programming LCS on random sequences
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		a := make([]string, rng.Intn(30))
+		b := make([]string, rng.Intn(30))
+		for i := range a {
+			a[i] = string(rune('a' + rng.Intn(4)))
+		}
+		for i := range b {
+			b[i] = string(rune('a' + rng.Intn(4)))
+		}
+
+		pairs, ok := alignSequences(a, b)
+		if !ok {
+			t.Fatalf("alignSequences(%v, %v) gave up", a, b)
+		}
+		for i, p := range pairs {
+			if a[p[0]] != b[p[1]] || (i > 0 && (p[0] <= pairs[i-1][0] || p[1] <= pairs[i-1][1])) {
+				t.Fatalf("alignSequences(%v, %v) = %v is not a common subsequence", a, b, pairs)
+			}
+		}
+		if want := lcsLength(a, b); len(pairs) != want {
+			t.Fatalf("alignSequences(%v, %v) found %d pairs; want %d", a, b, len(pairs), want)
+		}
+	}
+}
+
+func TestCaptureDiffDisjoint(t *testing.T) {
+	// One capture has clock and the other does not: far too different to align, so after the
+	// common start and end events are paired by position instead of exhausting memory
+	start := time.Now()
+	var msgsA, msgsB []gomidi.Message
+	msgsA = append(msgsA, gomidi.Start())
+	msgsB = append(msgsB, gomidi.Start())
+	for i := 0; i < 10000; i++ {
+		msgsA = append(msgsA, gomidi.TimingClock())
+		msgsB = append(msgsB, gomidi.NoteOn(0, uint8(i%128), 100))
+	}
+	msgsA = append(msgsA, gomidi.Stop())
+	msgsB = append(msgsB, gomidi.Stop())
+
+	d := NewCaptureDiff("a", diffCapture(start, msgsA...), "b", diffCapture(start, msgsB...))
+	d.Compare(NewFilter())
+
+	if !d.Positional {
+		t.Error("disjoint captures should be paired by position")
+	}
+	if d.Same != 2 || d.Missing != 10000 || d.Inserted != 10000 {
+		t.Errorf("diff = %d same, %d missing, %d inserted; want 2 same, 10000 missing and 10000 inserted", d.Same, d.Missing, d.Inserted)
+	}
+	if first, last := d.Lines[0], d.Lines[len(d.Lines)-1]; first.Kind != DiffSame || last.Kind != DiffSame || last.A != 10001 {
+		t.Errorf("first and last lines = %+v, %+v; want the common Start and Stop", first, last)
+	}
+}
+
+func lcsLength(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				table[i][j] = table[i-1][j-1] + 1
+			} else {
+				table[i][j] = max(table[i-1][j], table[i][j-1])
+			}
+		}
+	}
+	return table[len(a)][len(b)]
+}
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+// ExportFormat is a file format the event buffer can be exported to
+type ExportFormat int
+
+const (
+	ExportJSONL ExportFormat = iota // one JSON object per line
+	ExportCSV                       // one row per event with a header row
+	ExportText                      // an aligned table, as shown in the viewer
+)
+
+func (f ExportFormat) String() string {
+	switch f {
+	case ExportCSV:
+		return "CSV"
+	case ExportText:
+		return "text"
+	}
+	return "JSON Lines"
+}
+
+// ExportFormatFor picks the format for a file name by its extension: .jsonl or .json
+// for JSON Lines, .csv for CSV and anything else for a text table
+func ExportFormatFor(path string) ExportFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".json", ".ndjson":
+		return ExportJSONL
+	case ".csv":
+		return ExportCSV
+	}
+	return ExportText
+}
+
+// exportTimeLayout is ISO 8601 with microseconds, the precision of driver timestamps
+const exportTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// EventRecord is an event as exported: every midi.Event field in a portable form.
+// Decoded fields are nil when the message has no such part.
+type EventRecord struct {
+	Time          string `json:"time"`
+	Arrived       string `json:"arrived,omitempty"`
+	Processed     string `json:"processed,omitempty"`
+	Channel       *int   `json:"channel,omitempty"` // 1-16
+	Group         *int   `json:"group,omitempty"`   // UMP group 1-16, for events decoded from packets
+	Type          string `json:"type"`
+	Data          string `json:"data"`
+	Note          *int   `json:"note,omitempty"`
+	NoteName      string `json:"note_name,omitempty"`
+	Velocity      *int   `json:"velocity,omitempty"`
+	Controller    *int   `json:"controller,omitempty"`
+	Value         *int   `json:"value,omitempty"`
+	Program       *int   `json:"program,omitempty"`
+	Pressure      *int   `json:"pressure,omitempty"`
+	Bend          *int   `json:"bend,omitempty"` // -8192 to 8191
+	HighRes       *int64 `json:"high_res,omitempty"`
+	HighResBits   int    `json:"high_res_bits,omitempty"`
+	NoteLabel     string `json:"note_label,omitempty"`
+	ControlLabel  string `json:"controller_label,omitempty"`
+	ProgramLabel  string `json:"program_label,omitempty"`
+	RunningStatus bool   `json:"running_status,omitempty"`
+	Sent          bool   `json:"sent,omitempty"`
+	Bookmarked    bool   `json:"bookmarked,omitempty"`
+	Packet        string `json:"packet,omitempty"` // UMP words in hex
+	Raw           string `json:"raw"`              // bytes in hex
+}
+
+// NewEventRecord converts an event for export. Time is always set, even for the zero time
+// events of a Standard MIDI File count from, so every record can be read back.
+func NewEventRecord(event midi.Event) EventRecord {
+	r := EventRecord{
+		Time:          event.Timestamp.Format(exportTimeLayout),
+		Arrived:       formatExportTime(event.Arrived),
+		Processed:     formatExportTime(event.Processed),
+		Type:          event.MessageType,
+		Data:          event.Data,
+		HighResBits:   int(event.HighResBits),
+		NoteLabel:     event.Labels.Note,
+		ControlLabel:  event.Labels.Controller,
+		ProgramLabel:  event.Labels.Program,
+		RunningStatus: event.RunningStatus,
+		Sent:          event.Sent,
+		Bookmarked:    event.Bookmarked,
+		Raw:           formatHex(event.RawBytes),
+	}
+
+	if event.Packet != nil {
+		r.Group = intPtr(int(event.Group) + 1)
+		words := make([]string, len(event.Packet))
+		for i, w := range event.Packet {
+			words[i] = fmt.Sprintf("%08X", w)
+		}
+		r.Packet = strings.Join(words, " ")
+	}
+	if event.HighResBits > 0 {
+		value := int64(event.HighRes)
+		r.HighRes = &value
+	}
+
+	var ch, key, vel, controller, value, pressure uint8
+	var bend int16
+	var abs uint16
+	msg := event.Message
+	switch {
+	case msg == nil:
+		// Only MIDI 2.0 channel voice packets without a MIDI 1.0 equivalent have a channel
+		if event.Packet != nil && uint8(event.Packet[0]>>28) == midi.UMPMIDI2Voice {
+			r.Channel = intPtr(int(event.Channel) + 1)
+		}
+		return r
+	case msg.GetNoteOn(&ch, &key, &vel), msg.GetNoteOff(&ch, &key, &vel):
+		r.Note, r.NoteName, r.Velocity = intPtr(int(key)), midi.NoteToName(key), intPtr(int(vel))
+	case msg.GetPolyAfterTouch(&ch, &key, &pressure):
+		r.Note, r.NoteName, r.Pressure = intPtr(int(key)), midi.NoteToName(key), intPtr(int(pressure))
+	case msg.GetControlChange(&ch, &controller, &value):
+		r.Controller, r.Value = intPtr(int(controller)), intPtr(int(value))
+	case msg.GetProgramChange(&ch, &value):
+		r.Program = intPtr(int(value))
+	case msg.GetAfterTouch(&ch, &pressure):
+		r.Pressure = intPtr(int(pressure))
+	case msg.GetPitchBend(&ch, &bend, &abs):
+		r.Bend = intPtr(int(bend))
+	}
+	if msg.GetChannel(&ch) {
+		r.Channel = intPtr(int(ch) + 1)
+	}
+	return r
+}
+
+// exportColumns are the CSV header, in EventRecord field order
+var exportColumns = []string{
+	"time", "arrived", "processed", "channel", "group", "type", "data",
+	"note", "note_name", "velocity", "controller", "value", "program", "pressure", "bend",
+	"high_res", "high_res_bits", "note_label", "controller_label", "program_label",
+	"running_status", "packet", "raw", "sent", "bookmarked",
+}
+
+// csvRow returns the record's values in exportColumns order, empty where a field is absent
+func (r EventRecord) csvRow() []string {
+	highResBits := ""
+	if r.HighResBits > 0 {
+		highResBits = strconv.Itoa(r.HighResBits)
+	}
+	highRes := ""
+	if r.HighRes != nil {
+		highRes = strconv.FormatInt(*r.HighRes, 10)
+	}
+	return []string{
+		r.Time, r.Arrived, r.Processed, formatOptional(r.Channel), formatOptional(r.Group), r.Type, r.Data,
+		formatOptional(r.Note), r.NoteName, formatOptional(r.Velocity), formatOptional(r.Controller),
+		formatOptional(r.Value), formatOptional(r.Program), formatOptional(r.Pressure), formatOptional(r.Bend),
+		highRes, highResBits, r.NoteLabel, r.ControlLabel, r.ProgramLabel,
+		strconv.FormatBool(r.RunningStatus), r.Packet, r.Raw, strconv.FormatBool(r.Sent),
+		strconv.FormatBool(r.Bookmarked),
+	}
+}
+
+// WriteEvents writes events, oldest first, in a format
+func WriteEvents(w io.Writer, events []midi.Event, format ExportFormat) error {
+	switch format {
+	case ExportCSV:
+		out := csv.NewWriter(w)
+		out.Write(exportColumns)
+		for _, event := range events {
+			out.Write(NewEventRecord(event).csvRow())
+		}
+		out.Flush()
+		return out.Error()
+
+	case ExportText:
+		out := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(out, "Time\tChan\tEvent\tData\tRaw")
+		for _, event := range events {
+			r := NewEventRecord(event)
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n",
+				event.Timestamp.Format("2006-01-02 15:04:05.000000"), formatOptional(r.Channel), r.Type, r.Data, r.Raw)
+		}
+		return out.Flush()
+	}
+
+	out := json.NewEncoder(w)
+	for _, event := range events {
+		if err := out.Encode(NewEventRecord(event)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadEvents reads events exported as JSON Lines, oldest first. Each event is rebuilt from
+// its raw bytes, or its UMP packet if it has one, and stamped with its exported time; markers
+// are restored from their name. Error events are skipped, as their bytes are not a message.
+func ReadEvents(r io.Reader) ([]midi.Event, error) {
+	var events []midi.Event
+	in := json.NewDecoder(r)
+	for line := 1; in.More(); line++ {
+		var record EventRecord
+		if err := in.Decode(&record); err != nil {
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+		if record.Type == midi.ErrorMessageType {
+			continue
+		}
+
+		at, err := time.Parse(time.RFC3339Nano, record.Time)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+
+		var event midi.Event
+		if record.Type == midi.MarkerMessageType {
+			event = midi.NewMarker(record.Data, at)
+		} else if record.Packet != "" {
+			var words []uint32
+			for _, field := range strings.Fields(record.Packet) {
+				w, err := strconv.ParseUint(field, 16, 32)
+				if err != nil {
+					return nil, fmt.Errorf("record %d: bad packet word %q", line, field)
+				}
+				words = append(words, uint32(w))
+			}
+			event = midi.DecodeUMP(words)
+		} else {
+			raw, err := hex.DecodeString(strings.ReplaceAll(record.Raw, " ", ""))
+			if err != nil || len(raw) == 0 {
+				return nil, fmt.Errorf("record %d: bad raw bytes %q", line, record.Raw)
+			}
+			event = midi.ParseMessage(gomidi.Message(raw))
+		}
+		event.Timestamp = at
+		event.Sent = record.Sent
+		event.Bookmarked = record.Bookmarked
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// formatExportTime renders a time for export, or "" for the zero time
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(exportTimeLayout)
+}
+
+// formatHex renders bytes as space-separated hex pairs
+func formatHex(b []byte) string {
+	return fmt.Sprintf("% X", b)
+}
+
+// formatOptional renders an optional number, or "" if it is absent
+func formatOptional(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+func TestExportFormatFor(t *testing.T) {
+	cases := map[string]ExportFormat{
+		"capture.jsonl": ExportJSONL,
+		"capture.JSON":  ExportJSONL,
+		"capture.csv":   ExportCSV,
+		"capture.txt":   ExportText,
+		"capture":       ExportText,
+	}
+	for path, want := range cases {
+		if got := ExportFormatFor(path); got != want {
+			t.Errorf("ExportFormatFor(%q) = %s; want %s", path, got, want)
+		}
+	}
+}
+
+func TestNewEventRecord(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)
+	event := noteEvent(gomidi.NoteOn(2, 60, 100), at)
+	event.Arrived = at.Add(time.Millisecond)
+
+	r := NewEventRecord(event)
+	if r.Time != "2024-05-01T12:30:00.123456Z" || r.Arrived != "2024-05-01T12:30:00.124456Z" || r.Processed != "" {
+		t.Errorf("times = %q, %q, %q", r.Time, r.Arrived, r.Processed)
+	}
+	if r.Channel == nil || *r.Channel != 3 {
+		t.Errorf("Channel = %v; want 3", r.Channel)
+	}
+	if r.Note == nil || *r.Note != 60 || r.NoteName != "C4" || r.Velocity == nil || *r.Velocity != 100 {
+		t.Errorf("note = %v %q vel %v; want 60 C4 vel 100", r.Note, r.NoteName, r.Velocity)
+	}
+	if r.Controller != nil || r.Value != nil || r.Bend != nil {
+		t.Error("a Note On should have no controller, value or bend")
+	}
+	if r.Raw != "92 3C 64" {
+		t.Errorf("Raw = %q; want 92 3C 64", r.Raw)
+	}
+
+	bend := NewEventRecord(noteEvent(gomidi.Pitchbend(0, -8192), at))
+	if bend.Bend == nil || *bend.Bend != -8192 {
+		t.Errorf("Bend = %v; want -8192", bend.Bend)
+	}
+
+	sysex := NewEventRecord(noteEvent(gomidi.SysEx([]byte{0x7E, 0x7F, 0x06, 0x01}), at))
+	if sysex.Channel != nil || sysex.Raw != "F0 7E 7F 06 01 F7" {
+		t.Errorf("SysEx record = channel %v raw %q; want no channel and F0 7E 7F 06 01 F7", sysex.Channel, sysex.Raw)
+	}
+}
+
+func TestNewEventRecordUMP(t *testing.T) {
+	// MIDI 2.0 Note On, group 1, channel 1, note 60, velocity 0xFFFF
+	event := midi.DecodeUMP([]uint32{0x41903C00, 0xFFFF0000})
+	r := NewEventRecord(event)
+	if r.Group == nil || *r.Group != 2 {
+		t.Errorf("Group = %v; want 2", r.Group)
+	}
+	if r.Packet != "41903C00 FFFF0000" {
+		t.Errorf("Packet = %q", r.Packet)
+	}
+	if r.HighRes == nil || *r.HighRes != 0xFFFF || r.HighResBits != 16 {
+		t.Errorf("HighRes = %v (%d bits); want 65535 (16 bits)", r.HighRes, r.HighResBits)
+	}
+}
+
+func TestWriteEvents(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	events := []midi.Event{
+		noteEvent(gomidi.NoteOn(0, 60, 100), at),
+		noteEvent(gomidi.ControlChange(0, 7, 90), at.Add(time.Second)),
+	}
+
+	var jsonl bytes.Buffer
+	if err := WriteEvents(&jsonl, events, ExportJSONL); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(jsonl.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("JSON Lines has %d lines; want 2", len(lines))
+	}
+	var cc map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &cc); err != nil {
+		t.Fatal(err)
+	}
+	if cc["type"] != "CC" || cc["controller"] != 7.0 || cc["value"] != 90.0 || cc["raw"] != "B0 07 5A" {
+		t.Errorf("CC record = %v", cc)
+	}
+	if _, ok := cc["note"]; ok {
+		t.Error("CC record should have no note")
+	}
+
+	var csvOut bytes.Buffer
+	if err := WriteEvents(&csvOut, events, ExportCSV); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&csvOut).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "time" || len(rows[1]) != len(exportColumns) {
+		t.Fatalf("CSV = %v", rows)
+	}
+	if rows[1][3] != "1" || rows[1][7] != "60" || rows[2][10] != "7" || rows[2][11] != "90" {
+		t.Errorf("CSV rows = %v", rows[1:])
+	}
+
+	var text bytes.Buffer
+	if err := WriteEvents(&text, events, ExportText); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text.String(), "Time") || !strings.Contains(text.String(), "B0 07 5A") {
+		t.Errorf("text table = %q", text.String())
+	}
+}
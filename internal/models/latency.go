@@ -0,0 +1,200 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// LatencyIterationCounts are the selectable numbers of probes per latency test, in cycling order
+var LatencyIterationCounts = []int{10, 50, 100, 500, 1000}
+
+// DefaultLatencyIterations is the number of probes a latency test sends unless changed
+const DefaultLatencyIterations = 100
+
+// LatencyProbeInterval is the pause between probes, long enough for slow links to keep up
+const LatencyProbeInterval = 50 * time.Millisecond
+
+// LatencyTimeout is how long a probe may take to come back before it is counted as lost
+const LatencyTimeout = time.Second
+
+// LatencyTest measures round-trip times by sending numbered probes out of a port and
+// timing their return on an input. Probes are matched by session, so replies to an
+// earlier test are ignored.
+type LatencyTest struct {
+	Iterations int
+	Running    bool
+	RTTs       []time.Duration // round-trip times of returned probes, in arrival order
+	Lost       int             // probes that did not return within LatencyTimeout
+
+	session uint16
+	next    int               // sequence number of the next probe
+	sent    map[int]time.Time // probes awaiting their return; zero until the send is confirmed
+	early   map[int]time.Time // returns seen before their send was confirmed
+}
+
+// NewLatencyTest creates an idle latency test
+func NewLatencyTest() LatencyTest {
+	return LatencyTest{Iterations: DefaultLatencyIterations}
+}
+
+// CycleIterations advances the number of probes to the next selectable value
+func (t *LatencyTest) CycleIterations() {
+	for i, n := range LatencyIterationCounts {
+		if n == t.Iterations {
+			t.Iterations = LatencyIterationCounts[(i+1)%len(LatencyIterationCounts)]
+			return
+		}
+	}
+	t.Iterations = LatencyIterationCounts[0]
+}
+
+// Start discards previous results, starts a new session at now and returns the session
+func (t *LatencyTest) Start(now time.Time) uint16 {
+	previous := t.session
+	*t = LatencyTest{
+		Iterations: t.Iterations,
+		Running:    true,
+		session:    uint16(now.UnixNano()>>10) & midi.MaxProbeSequence,
+		sent:       make(map[int]time.Time),
+		early:      make(map[int]time.Time),
+	}
+	// A restarted test never reuses the session it replaces
+	if t.session == previous {
+		t.session = (t.session + 1) & midi.MaxProbeSequence
+	}
+	return t.session
+}
+
+// Stop ends the test, counting probes still out as lost
+func (t *LatencyTest) Stop() {
+	t.Lost += len(t.sent)
+	t.sent = make(map[int]time.Time)
+	t.Running = false
+}
+
+// IsSession reports whether a scheduled step belongs to the running test
+func (t LatencyTest) IsSession(session uint16) bool {
+	return t.Running && session == t.session
+}
+
+// NextProbe returns the next probe to send, or false once all probes have been sent
+func (t *LatencyTest) NextProbe() (seq int, probe []byte, ok bool) {
+	if !t.Running || t.next >= t.Iterations {
+		return 0, nil, false
+	}
+	seq = t.next
+	t.next++
+	t.sent[seq] = time.Time{}
+	return seq, midi.LatencyProbe(t.session, seq), true
+}
+
+// Sent records when a probe left the output
+func (t *LatencyTest) Sent(seq int, at time.Time) {
+	if _, waiting := t.sent[seq]; !waiting {
+		return
+	}
+	if arrived, ok := t.early[seq]; ok {
+		delete(t.early, seq)
+		delete(t.sent, seq)
+		t.RTTs = append(t.RTTs, arrived.Sub(at))
+		return
+	}
+	t.sent[seq] = at
+}
+
+// Track times the return of a probe; it reports whether the event was one of this test's probes
+func (t *LatencyTest) Track(event midi.Event) bool {
+	session, seq, ok := midi.ParseLatencyProbe(event.RawBytes)
+	if !ok || t.sent == nil || session != t.session {
+		return false
+	}
+	sentAt, waiting := t.sent[seq]
+	if !waiting {
+		return false
+	}
+
+	arrived := event.Arrived
+	if arrived.IsZero() {
+		arrived = event.Timestamp
+	}
+
+	if sentAt.IsZero() {
+		t.early[seq] = arrived
+		return true
+	}
+	delete(t.sent, seq)
+	t.RTTs = append(t.RTTs, arrived.Sub(sentAt))
+	return true
+}
+
+// Expire counts probes sent more than LatencyTimeout before now as lost and ends the test
+// once every probe has returned or been lost
+func (t *LatencyTest) Expire(now time.Time) {
+	for seq, at := range t.sent {
+		if !at.IsZero() && now.Sub(at) > LatencyTimeout {
+			delete(t.sent, seq)
+			t.Lost++
+		}
+	}
+	if t.Running && t.next >= t.Iterations && len(t.sent) == 0 {
+		t.Running = false
+	}
+}
+
+// Done returns the number of probes that have returned or been lost
+func (t LatencyTest) Done() int {
+	return len(t.RTTs) + t.Lost
+}
+
+// Summary returns the minimum, mean and maximum round-trip times
+func (t LatencyTest) Summary() Latency {
+	var l Latency
+	for _, rtt := range t.RTTs {
+		l.Add(rtt)
+	}
+	return l
+}
+
+// Percentile returns the nearest-rank percentile (0-100) of the round-trip times
+func (t LatencyTest) Percentile(p float64) time.Duration {
+	if len(t.RTTs) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), t.RTTs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(p/100*float64(len(sorted))+0.999999) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// Histogram counts the round-trip times in buckets of equal width spanning the minimum to
+// the maximum; bucket i starts at min + i*width
+func (t LatencyTest) Histogram(buckets int) (counts []int, min, width time.Duration) {
+	if len(t.RTTs) == 0 || buckets < 1 {
+		return nil, 0, 0
+	}
+	summary := t.Summary()
+	min = summary.Min
+	width = (summary.Max - summary.Min) / time.Duration(buckets)
+	if width <= 0 {
+		width = time.Microsecond
+	}
+
+	counts = make([]int, buckets)
+	for _, rtt := range t.RTTs {
+		i := int((rtt - min) / width)
+		if i >= buckets {
+			i = buckets - 1
+		}
+		counts[i]++
+	}
+	return counts, min, width
+}
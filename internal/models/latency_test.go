@@ -0,0 +1,113 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// probeReply returns the event for a probe arriving at the given time
+func probeReply(probe []byte, arrived time.Time) midi.Event {
+	return midi.Event{Timestamp: arrived, Arrived: arrived, RawBytes: probe, MessageType: "SysEx"}
+}
+
+func TestLatencyTestRoundTrips(t *testing.T) {
+	test := NewLatencyTest()
+	test.Iterations = 3
+	start := time.Now()
+	test.Start(start)
+
+	seq0, probe0, _ := test.NextProbe()
+	test.Sent(seq0, start)
+	if !test.Track(probeReply(probe0, start.Add(2*time.Millisecond))) {
+		t.Fatal("Track() did not recognise the probe")
+	}
+
+	// A return seen before its send is confirmed is still timed from the send
+	seq1, probe1, _ := test.NextProbe()
+	test.Track(probeReply(probe1, start.Add(54*time.Millisecond)))
+	test.Sent(seq1, start.Add(50*time.Millisecond))
+
+	// The third probe never returns
+	seq2, _, _ := test.NextProbe()
+	test.Sent(seq2, start.Add(100*time.Millisecond))
+	if _, _, ok := test.NextProbe(); ok {
+		t.Error("NextProbe() after all iterations = ok; want done")
+	}
+
+	test.Expire(start.Add(500 * time.Millisecond))
+	if !test.Running {
+		t.Fatal("test ended before the last probe timed out")
+	}
+	test.Expire(start.Add(100*time.Millisecond + LatencyTimeout + time.Millisecond))
+	if test.Running {
+		t.Error("test still running after every probe returned or was lost")
+	}
+
+	if len(test.RTTs) != 2 || test.RTTs[0] != 2*time.Millisecond || test.RTTs[1] != 4*time.Millisecond {
+		t.Errorf("RTTs = %v; want [2ms 4ms]", test.RTTs)
+	}
+	if test.Lost != 1 || test.Done() != 3 {
+		t.Errorf("Lost = %d, Done() = %d; want 1, 3", test.Lost, test.Done())
+	}
+}
+
+func TestLatencyTestIgnoresOtherSessions(t *testing.T) {
+	test := NewLatencyTest()
+	start := time.Now()
+	first := test.Start(start)
+	seq, probe, _ := test.NextProbe()
+	test.Sent(seq, start)
+
+	second := test.Start(start)
+	if test.IsSession(first) || !test.IsSession(second) {
+		t.Error("steps scheduled by the previous session should be ignored")
+	}
+	if test.Track(probeReply(probe, start.Add(time.Millisecond))) {
+		t.Error("Track() accepted a probe from the previous session")
+	}
+	if test.Track(probeReply([]byte{0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7}, start)) {
+		t.Error("Track() accepted a SysEx that is not a probe")
+	}
+
+	test.Stop()
+	if test.IsSession(second) {
+		t.Error("a stopped test should ignore its scheduled steps")
+	}
+}
+
+func TestLatencyTestStatistics(t *testing.T) {
+	test := NewLatencyTest()
+	for i := 1; i <= 20; i++ {
+		test.RTTs = append(test.RTTs, time.Duration(i)*time.Millisecond)
+	}
+
+	summary := test.Summary()
+	if summary.Min != time.Millisecond || summary.Max != 20*time.Millisecond || summary.Mean() != 10500*time.Microsecond {
+		t.Errorf("Summary() = %+v; want 1ms-20ms averaging 10.5ms", summary)
+	}
+	if p95 := test.Percentile(95); p95 != 19*time.Millisecond {
+		t.Errorf("Percentile(95) = %s; want 19ms", p95)
+	}
+
+	counts, min, width := test.Histogram(4)
+	if min != time.Millisecond || width != 4750*time.Microsecond {
+		t.Errorf("Histogram(4) min, width = %s, %s; want 1ms, 4.75ms", min, width)
+	}
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	if len(counts) != 4 || total != 20 || counts[3] != 5 {
+		t.Errorf("Histogram(4) = %v; want 4 buckets holding all 20 with 5 in the last", counts)
+	}
+}
+
+func TestLatencyTestCycleIterations(t *testing.T) {
+	test := NewLatencyTest()
+	test.CycleIterations()
+	if test.Iterations != 500 {
+		t.Errorf("CycleIterations() from %d = %d; want 500", DefaultLatencyIterations, test.Iterations)
+	}
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// DumpGap is the longest pause between SysEx messages that still belong to the same dump
+const DumpGap = time.Second
+
+// Dump is a group of contiguous SysEx messages, captured or loaded from a .syx file
+type Dump struct {
+	Name         string
+	Manufacturer string
+	Start        time.Time
+	End          time.Time
+	Messages     [][]byte // complete messages including F0 and F7
+	Loaded       bool     // loaded from a file rather than captured
+}
+
+// Bytes returns the total size of the dump's messages
+func (d Dump) Bytes() int {
+	n := 0
+	for _, msg := range d.Messages {
+		n += len(msg)
+	}
+	return n
+}
+
+// Checksums counts the messages with valid and invalid checksums; the rest have no recognisable checksum
+func (d Dump) Checksums() (valid, invalid int) {
+	for _, msg := range d.Messages {
+		switch midi.VerifyChecksum(msg) {
+		case midi.ChecksumValid:
+			valid++
+		case midi.ChecksumInvalid:
+			invalid++
+		}
+	}
+	return valid, invalid
+}
+
+// Librarian collects received SysEx messages into dumps
+type Librarian struct {
+	Dumps    []Dump
+	open     bool // the last dump can still grow
+	captured int  // number of dumps captured so far, for naming
+}
+
+// NewLibrarian creates an empty librarian
+func NewLibrarian() Librarian {
+	return Librarian{}
+}
+
+// Track adds a SysEx event to the current dump, or starts a new one when the manufacturer
+// changes, the gap since the last message exceeds DumpGap, or other messages came in between.
+// Realtime messages such as clock do not interrupt a dump.
+func (l *Librarian) Track(event midi.Event) {
+	if event.IsRealtime() {
+		return
+	}
+	if event.MessageType != "SysEx" {
+		l.open = false
+		return
+	}
+
+	manufacturer := midi.DecodeSysEx(sysExBody(event.RawBytes)).Manufacturer
+	msg := append([]byte(nil), event.RawBytes...)
+
+	if l.open {
+		last := &l.Dumps[len(l.Dumps)-1]
+		if last.Manufacturer == manufacturer && event.Timestamp.Sub(last.End) <= DumpGap {
+			last.Messages = append(last.Messages, msg)
+			last.End = event.Timestamp
+			return
+		}
+	}
+
+	l.captured++
+	l.Dumps = append(l.Dumps, Dump{
+		Name:         fmt.Sprintf("%s %d", manufacturer, l.captured),
+		Manufacturer: manufacturer,
+		Start:        event.Timestamp,
+		End:          event.Timestamp,
+		Messages:     [][]byte{msg},
+	})
+	l.open = true
+}
+
+// Add appends a dump, such as one loaded from a file. It never grows with captured messages.
+func (l *Librarian) Add(dump Dump) {
+	l.Dumps = append(l.Dumps, dump)
+	l.open = false
+}
+
+// Rename renames the dump at index i
+func (l *Librarian) Rename(i int, name string) {
+	if i >= 0 && i < len(l.Dumps) && name != "" {
+		l.Dumps[i].Name = name
+	}
+}
+
+// Reset removes captured dumps, keeping those loaded from files
+func (l *Librarian) Reset() {
+	kept := l.Dumps[:0]
+	for _, dump := range l.Dumps {
+		if dump.Loaded {
+			kept = append(kept, dump)
+		}
+	}
+	l.Dumps = kept
+	l.open = false
+	l.captured = 0
+}
+
+// NewLoadedDump creates a dump from messages loaded from a file
+func NewLoadedDump(name string, msgs [][]byte) Dump {
+	dump := Dump{Name: name, Messages: msgs, Loaded: true}
+	if len(msgs) > 0 {
+		dump.Manufacturer = midi.DecodeSysEx(sysExBody(msgs[0])).Manufacturer
+	}
+	return dump
+}
+
+// sysExBody strips the F0 and F7 framing from a complete SysEx message
+func sysExBody(msg []byte) []byte {
+	if len(msg) > 0 && msg[0] == 0xF0 {
+		msg = msg[1:]
+	}
+	if len(msg) > 0 && msg[len(msg)-1] == 0xF7 {
+		msg = msg[:len(msg)-1]
+	}
+	return msg
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+)
+
+func TestLibrarianGroupsDumps(t *testing.T) {
+	lib := NewLibrarian()
+	start := time.Now()
+
+	roland := gomidi.SysEx([]byte{0x41, 0x10, 0x42, 0x12, 0x40, 0x00, 0x7F, 0x00, 0x41})
+	yamaha := gomidi.SysEx([]byte{0x43, 0x10, 0x4C, 0x00, 0x00, 0x7E, 0x00})
+
+	lib.Track(noteEvent(roland, start))
+	lib.Track(noteEvent(gomidi.TimingClock(), start.Add(10*time.Millisecond))) // realtime does not split
+	lib.Track(noteEvent(roland, start.Add(20*time.Millisecond)))
+	lib.Track(noteEvent(yamaha, start.Add(30*time.Millisecond))) // new manufacturer
+	lib.Track(noteEvent(yamaha, start.Add(3*time.Second)))       // after a long gap
+	lib.Track(noteEvent(gomidi.NoteOn(0, 60, 100), start.Add(3*time.Second)))
+	lib.Track(noteEvent(yamaha, start.Add(3*time.Second))) // after other messages
+
+	if len(lib.Dumps) != 4 {
+		t.Fatalf("Dumps = %d; want 4", len(lib.Dumps))
+	}
+	first := lib.Dumps[0]
+	if first.Name != "Roland 1" || len(first.Messages) != 2 {
+		t.Errorf("Dumps[0] = %q with %d messages; want Roland 1 with 2", first.Name, len(first.Messages))
+	}
+	if first.Bytes() != 22 {
+		t.Errorf("Dumps[0].Bytes() = %d; want 22", first.Bytes())
+	}
+	if valid, invalid := first.Checksums(); valid != 2 || invalid != 0 {
+		t.Errorf("Dumps[0].Checksums() = %d, %d; want 2, 0", valid, invalid)
+	}
+	if lib.Dumps[1].Manufacturer != "Yamaha" {
+		t.Errorf("Dumps[1].Manufacturer = %q; want Yamaha", lib.Dumps[1].Manufacturer)
+	}
+}
+
+func TestLibrarianReset(t *testing.T) {
+	lib := NewLibrarian()
+	msg := []byte{0xF0, 0x43, 0x10, 0x4C, 0x00, 0x00, 0x7E, 0x00, 0xF7}
+
+	lib.Track(noteEvent(gomidi.SysEx(msg[1:len(msg)-1]), time.Now()))
+	lib.Add(NewLoadedDump("backup.syx", [][]byte{msg}))
+	lib.Rename(1, "XG reset")
+	lib.Reset()
+
+	if len(lib.Dumps) != 1 || lib.Dumps[0].Name != "XG reset" || !lib.Dumps[0].Loaded {
+		t.Errorf("Reset() kept %+v; want only the loaded dump", lib.Dumps)
+	}
+	if lib.Dumps[0].Manufacturer != "Yamaha" {
+		t.Errorf("loaded dump Manufacturer = %q; want Yamaha", lib.Dumps[0].Manufacturer)
+	}
+}
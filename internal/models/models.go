@@ -1,6 +1,10 @@
 package models
 
-import "midi-viewer/internal/midi"
+import (
+	"time"
+
+	"midi-viewer/internal/midi"
+)
 
 // AppState represents the current state of the application
 type AppState int
@@ -18,6 +22,32 @@ type Filter struct {
 	HiddenMessageTypes map[string]bool  // message types to hide (empty = show all)
 	HiddenColumns      map[string]bool  // columns to hide (empty = show all)
 	ShowMusicalNotes   bool             // show musical note names (C4) instead of numbers (60)
+	ShowNames          bool             // show CC, program and drum names alongside numbers
+	StuckNoteThreshold time.Duration    // highlight notes held longer than this (0 = off)
+	SysExDelay         time.Duration    // pause between SysEx messages sent by the librarian
+	MPE                MPEMode          // group per-note expression on MPE member channels under their notes
+	TimeMode           TimeMode         // what the Time column shows
+	Microseconds       bool             // show times with microsecond precision
+}
+
+// StuckNoteThresholds are the selectable stuck-note thresholds, in cycling order
+var StuckNoteThresholds = []time.Duration{
+	0,
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+// SysExDelays are the selectable inter-message delays for sending SysEx, in cycling order
+var SysExDelays = []time.Duration{
+	0,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
 }
 
 // NewFilter creates a new empty filter (showing all events)
@@ -27,11 +57,36 @@ func NewFilter() Filter {
 		HiddenMessageTypes: make(map[string]bool),
 		HiddenColumns:      make(map[string]bool),
 		ShowMusicalNotes:   true, // Default to musical notes
+		StuckNoteThreshold: 5 * time.Second,
+		SysExDelay:         20 * time.Millisecond,
+	}
+}
+
+// Clone returns a copy of the filter that shares no maps with it
+func (f Filter) Clone() Filter {
+	c := f
+	c.HiddenChannels = cloneSet(f.HiddenChannels)
+	c.HiddenMessageTypes = cloneSet(f.HiddenMessageTypes)
+	c.HiddenColumns = cloneSet(f.HiddenColumns)
+	return c
+}
+
+// cloneSet copies a set, returning an empty set for nil
+func cloneSet[K comparable](set map[K]bool) map[K]bool {
+	c := make(map[K]bool, len(set))
+	for k, v := range set {
+		c[k] = v
 	}
+	return c
 }
 
 // ShouldShow returns true if an event should be displayed given the current filter
 func (f Filter) ShouldShow(event midi.Event) bool {
+	// Markers annotate the whole stream, not a channel or message type
+	if event.MessageType == midi.MarkerMessageType {
+		return true
+	}
+
 	// Hide if channel is in hidden list
 	if f.HiddenChannels[event.Channel] {
 		return false
@@ -45,6 +100,22 @@ func (f Filter) ShouldShow(event midi.Event) bool {
 	return true
 }
 
+// ShownIndex returns the index in events of the event that has newer events shown after it
+// among those the filter shows, or -1 if fewer are shown. It finds a shown event in the
+// unfiltered buffer by position, since captures can repeat identical events.
+func (f Filter) ShownIndex(events []midi.Event, newer int) int {
+	for i := len(events) - 1; i >= 0; i-- {
+		if !f.ShouldShow(events[i]) {
+			continue
+		}
+		if newer == 0 {
+			return i
+		}
+		newer--
+	}
+	return -1
+}
+
 // ToggleChannel toggles a channel's visibility
 func (f *Filter) ToggleChannel(ch uint8) {
 	if f.HiddenChannels[ch] {
@@ -86,3 +157,47 @@ func (f *Filter) ToggleColumn(col string) {
 func (f Filter) IsColumnVisible(col string) bool {
 	return !f.HiddenColumns[col]
 }
+
+// CycleStuckNoteThreshold advances the stuck-note threshold to the next selectable value
+func (f *Filter) CycleStuckNoteThreshold() {
+	for i, threshold := range StuckNoteThresholds {
+		if threshold == f.StuckNoteThreshold {
+			f.StuckNoteThreshold = StuckNoteThresholds[(i+1)%len(StuckNoteThresholds)]
+			return
+		}
+	}
+	f.StuckNoteThreshold = StuckNoteThresholds[0]
+}
+
+// CycleSysExDelay advances the SysEx send delay to the next selectable value
+func (f *Filter) CycleSysExDelay() {
+	for i, delay := range SysExDelays {
+		if delay == f.SysExDelay {
+			f.SysExDelay = SysExDelays[(i+1)%len(SysExDelays)]
+			return
+		}
+	}
+	f.SysExDelay = SysExDelays[0]
+}
+
+// CycleMPEMode advances the MPE mode to the next selectable value
+func (f *Filter) CycleMPEMode() {
+	for i, mode := range MPEModes {
+		if mode == f.MPE {
+			f.MPE = MPEModes[(i+1)%len(MPEModes)]
+			return
+		}
+	}
+	f.MPE = MPEModes[0]
+}
+
+// CycleTimeMode advances the time mode to the next selectable value
+func (f *Filter) CycleTimeMode() {
+	for i, mode := range TimeModes {
+		if mode == f.TimeMode {
+			f.TimeMode = TimeModes[(i+1)%len(TimeModes)]
+			return
+		}
+	}
+	f.TimeMode = TimeModes[0]
+}
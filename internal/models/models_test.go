@@ -103,6 +103,23 @@ func TestFilterShouldShow_ChannelFilter(t *testing.T) {
 	}
 }
 
+func TestFilterShownIndex(t *testing.T) {
+	filter := NewFilter()
+	filter.ToggleChannel(1)
+
+	// Identical notes, as in a hex dump where every event has the same time, around a hidden one
+	note := midi.Event{Channel: 0, MessageType: "Note On", Data: "Note: 60, Velocity: 100"}
+	hidden := midi.Event{Channel: 1, MessageType: "Note On", Data: "Note: 60, Velocity: 100"}
+	events := []midi.Event{note, note, hidden, note}
+
+	// Shown are events 0, 1 and 3, newest last
+	for newer, want := range []int{3, 1, 0, -1} {
+		if got := filter.ShownIndex(events, newer); got != want {
+			t.Errorf("ShownIndex(%d newer) = %d; want %d", newer, got, want)
+		}
+	}
+}
+
 func TestFilterShouldShow_MessageTypeFilter(t *testing.T) {
 	filter := NewFilter()
 	filter.ToggleMessageType("Note On")  // Hide Note On
@@ -160,6 +177,16 @@ func TestFilterShouldShow_CombinedFilters(t *testing.T) {
 	}
 }
 
+func TestFilterShouldShow_Marker(t *testing.T) {
+	filter := NewFilter()
+	filter.ToggleChannel(0)
+	filter.ToggleMessageType(midi.MarkerMessageType)
+
+	if !filter.ShouldShow(midi.NewMarker("pressed preset 3", time.Now())) {
+		t.Error("ShouldShow should always return true for markers")
+	}
+}
+
 func TestFilterIsChannelVisible(t *testing.T) {
 	filter := NewFilter()
 	filter.ToggleChannel(5) // Hide channel 5
@@ -219,3 +246,20 @@ func TestFilterIsColumnVisible(t *testing.T) {
 		t.Error("IsColumnVisible('Note') should return true for non-hidden column")
 	}
 }
+
+func TestFilterClone(t *testing.T) {
+	filter := NewFilter()
+	filter.ToggleChannel(1)
+
+	clone := filter.Clone()
+	clone.ToggleChannel(2)
+	clone.ToggleMessageType("Clock")
+	clone.ToggleColumn("Vel")
+
+	if !filter.IsChannelVisible(2) || !filter.IsMessageTypeVisible("Clock") || !filter.IsColumnVisible("Vel") {
+		t.Error("changing a clone should not change the original filter")
+	}
+	if clone.IsChannelVisible(1) {
+		t.Error("a clone should keep the original's hidden channels")
+	}
+}
@@ -0,0 +1,318 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// MPEMode selects how MPE zones are determined
+type MPEMode int
+
+const (
+	MPEOff   MPEMode = iota // no MPE grouping
+	MPEAuto                 // zones from MPE Configuration Messages, or a 15-channel lower zone until one is seen
+	MPELower                // a 15-channel lower zone: manager channel 1, members 2-16
+	MPEUpper                // a 15-channel upper zone: manager channel 16, members 1-15
+)
+
+// MPEModes are the selectable MPE modes, in cycling order
+var MPEModes = []MPEMode{MPEOff, MPEAuto, MPELower, MPEUpper}
+
+func (m MPEMode) String() string {
+	switch m {
+	case MPEAuto:
+		return "auto"
+	case MPELower:
+		return "lower"
+	case MPEUpper:
+		return "upper"
+	}
+	return "off"
+}
+
+// Default pitch bend ranges in semitones from the MPE specification
+const (
+	DefaultMPEMemberBendRange  = 48
+	DefaultMPEManagerBendRange = 2
+)
+
+// MPETimbreController is the CC carrying per-note timbre (the third dimension) in MPE
+const MPETimbreController = 74
+
+// maxMPENotes limits how many notes, including released ones, keep their expression
+const maxMPENotes = 256
+
+// MPEZone is a manager channel and its member channels
+type MPEZone struct {
+	Manager uint8 // 0 for the lower zone, 15 for the upper zone
+	Members int   // number of member channels
+}
+
+// IsMember reports whether ch is one of the zone's member channels
+func (z MPEZone) IsMember(ch uint8) bool {
+	if z.Manager == 0 {
+		return ch >= 1 && int(ch) <= z.Members
+	}
+	return ch < 15 && int(ch) >= 15-z.Members
+}
+
+func (z MPEZone) String() string {
+	if z.Manager == 0 {
+		return fmt.Sprintf("lower %d", z.Members)
+	}
+	return fmt.Sprintf("upper %d", z.Members)
+}
+
+// MPENote is a note on a member channel with the latest per-note expression received for it
+type MPENote struct {
+	Channel     uint8
+	Key         uint8
+	Velocity    uint8
+	Start       time.Time
+	End         time.Time // zero while the note is held
+	Bend        int16     // latest pitch bend, -8192 to 8191
+	Timbre      uint8     // latest CC 74
+	HasTimbre   bool
+	Pressure    uint8 // latest channel pressure
+	HasPressure bool
+	Updates     int // expression messages received for the note
+}
+
+// Held reports whether the note has not been released
+func (n MPENote) Held() bool {
+	return n.End.IsZero()
+}
+
+// BendSemitones converts the note's pitch bend to semitones for a bend range
+func (n MPENote) BendSemitones(bendRange int) float64 {
+	return float64(n.Bend) / 8192 * float64(bendRange)
+}
+
+// MPEState detects MPE zones and bend ranges and groups per-note expression under its note.
+// Pitch bend, CC 74 and channel pressure on a channel belong to the latest note on that channel;
+// those received before a note's Note On set its initial expression.
+type MPEState struct {
+	lower, upper int                // member channels per zone from MPE Configuration Messages
+	configured   bool               // an MPE Configuration Message has been received
+	bendRanges   map[uint8]int      // pitch bend sensitivity per channel from RPN 0
+	memberBend   int                // last bend range set on a member channel (0 = default)
+	rpn          map[uint8][2]uint8 // selected RPN MSB/LSB per channel
+	initial      map[uint8]MPENote  // expression received on a channel with no held note
+	notes        []MPENote
+}
+
+// NewMPEState creates an empty MPE state
+func NewMPEState() MPEState {
+	return MPEState{
+		bendRanges: make(map[uint8]int),
+		rpn:        make(map[uint8][2]uint8),
+		initial:    make(map[uint8]MPENote),
+	}
+}
+
+// Track updates zones, bend ranges and per-note expression from an event
+func (m *MPEState) Track(event midi.Event) {
+	var ch, key, vel, controller, value, pressure uint8
+	var bend int16
+	var abs uint16
+
+	switch {
+	case event.Message.GetNoteOn(&ch, &key, &vel) && vel > 0:
+		note := m.initial[ch]
+		delete(m.initial, ch)
+		note.Channel, note.Key, note.Velocity, note.Start = ch, key, vel, event.Timestamp
+		m.notes = append(m.notes, note)
+		m.prune()
+
+	case event.Message.GetNoteOn(&ch, &key, &vel), event.Message.GetNoteOff(&ch, &key, &vel):
+		// Note On with velocity 0 is a Note Off
+		for i := len(m.notes) - 1; i >= 0; i-- {
+			if m.notes[i].Channel == ch && m.notes[i].Key == key && m.notes[i].Held() {
+				m.notes[i].End = event.Timestamp
+				break
+			}
+		}
+
+	case event.Message.GetControlChange(&ch, &controller, &value):
+		m.trackController(ch, controller, value)
+		if controller == MPETimbreController {
+			m.express(ch, func(n *MPENote) { n.Timbre, n.HasTimbre = value, true })
+		}
+
+	case event.Message.GetPitchBend(&ch, &bend, &abs):
+		m.express(ch, func(n *MPENote) { n.Bend = bend })
+
+	case event.Message.GetAfterTouch(&ch, &pressure):
+		m.express(ch, func(n *MPENote) { n.Pressure, n.HasPressure = pressure, true })
+	}
+}
+
+// express applies expression to the latest held note on ch, or saves it for the next note
+func (m *MPEState) express(ch uint8, apply func(*MPENote)) {
+	for i := len(m.notes) - 1; i >= 0; i-- {
+		if m.notes[i].Channel == ch && m.notes[i].Held() {
+			apply(&m.notes[i])
+			m.notes[i].Updates++
+			return
+		}
+	}
+
+	note := m.initial[ch]
+	apply(&note)
+	m.initial[ch] = note
+}
+
+// trackController follows RPN selection and data entry for the MPE Configuration Message (RPN 6)
+// and pitch bend sensitivity (RPN 0)
+func (m *MPEState) trackController(ch, controller, value uint8) {
+	selected, ok := m.rpn[ch]
+	if !ok {
+		selected = [2]uint8{0x7F, 0x7F}
+	}
+
+	switch controller {
+	case 101:
+		selected[0] = value
+		m.rpn[ch] = selected
+	case 100:
+		selected[1] = value
+		m.rpn[ch] = selected
+	case 99, 98:
+		// Selecting an NRPN deselects the RPN
+		m.rpn[ch] = [2]uint8{0x7F, 0x7F}
+	case 6:
+		switch selected {
+		case [2]uint8{0, 6}:
+			m.configure(ch, int(value))
+		case [2]uint8{0, 0}:
+			m.bendRanges[ch] = int(value)
+			if ch != 0 && ch != 15 {
+				m.memberBend = int(value)
+			}
+		}
+	}
+}
+
+// configure applies an MPE Configuration Message received on ch
+func (m *MPEState) configure(ch uint8, members int) {
+	if members > 15 {
+		members = 15
+	}
+
+	switch ch {
+	case 0:
+		m.lower = members
+		if m.lower+m.upper > 14 && m.upper > 0 {
+			m.upper = max(14-m.lower, 0)
+		}
+	case 15:
+		m.upper = members
+		if m.lower+m.upper > 14 && m.lower > 0 {
+			m.lower = max(14-m.upper, 0)
+		}
+	default:
+		return
+	}
+
+	// A configuration message resets bend ranges to their defaults
+	m.configured = true
+	m.bendRanges = make(map[uint8]int)
+	m.memberBend = 0
+}
+
+// Zones returns the MPE zones in use for a mode
+func (m *MPEState) Zones(mode MPEMode) []MPEZone {
+	switch mode {
+	case MPELower:
+		return []MPEZone{{Manager: 0, Members: 15}}
+	case MPEUpper:
+		return []MPEZone{{Manager: 15, Members: 15}}
+	case MPEAuto:
+		if !m.configured {
+			return []MPEZone{{Manager: 0, Members: 15}}
+		}
+		var zones []MPEZone
+		if m.lower > 0 {
+			zones = append(zones, MPEZone{Manager: 0, Members: m.lower})
+		}
+		if m.upper > 0 {
+			zones = append(zones, MPEZone{Manager: 15, Members: m.upper})
+		}
+		return zones
+	}
+	return nil
+}
+
+// IsMember reports whether ch is a member channel of a zone in use for a mode
+func (m *MPEState) IsMember(mode MPEMode, ch uint8) bool {
+	for _, zone := range m.Zones(mode) {
+		if zone.IsMember(ch) {
+			return true
+		}
+	}
+	return false
+}
+
+// BendRange returns the pitch bend range in semitones for a channel: as set by RPN 0 on the channel,
+// or on any member channel for member channels, or the MPE default
+func (m *MPEState) BendRange(ch uint8) int {
+	if r, ok := m.bendRanges[ch]; ok {
+		return r
+	}
+	if ch == 0 || ch == 15 {
+		return DefaultMPEManagerBendRange
+	}
+	if m.memberBend > 0 {
+		return m.memberBend
+	}
+	return DefaultMPEMemberBendRange
+}
+
+// Note returns the note that started on ch with key at start
+func (m *MPEState) Note(ch, key uint8, start time.Time) (MPENote, bool) {
+	for i := len(m.notes) - 1; i >= 0; i-- {
+		n := m.notes[i]
+		if n.Channel == ch && n.Key == key && n.Start.Equal(start) {
+			return n, true
+		}
+	}
+	return MPENote{}, false
+}
+
+// Held returns the held notes ordered by key
+func (m *MPEState) Held() []MPENote {
+	var held []MPENote
+	for _, n := range m.notes {
+		if n.Held() {
+			held = append(held, n)
+		}
+	}
+	sort.SliceStable(held, func(i, j int) bool { return held[i].Key < held[j].Key })
+	return held
+}
+
+// Reset forgets all notes and expression, keeping the zone configuration and bend ranges
+func (m *MPEState) Reset() {
+	m.notes = nil
+	m.initial = make(map[uint8]MPENote)
+}
+
+// prune drops the oldest released notes beyond maxMPENotes
+func (m *MPEState) prune() {
+	excess := len(m.notes) - maxMPENotes
+	if excess <= 0 {
+		return
+	}
+	kept := m.notes[:0]
+	for _, n := range m.notes {
+		if excess > 0 && !n.Held() {
+			excess--
+			continue
+		}
+		kept = append(kept, n)
+	}
+	m.notes = kept
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+)
+
+// rpn returns the messages selecting RPN msb/lsb on ch and setting its value
+func rpn(ch, msb, lsb, value uint8) []gomidi.Message {
+	return []gomidi.Message{
+		gomidi.ControlChange(ch, 101, msb),
+		gomidi.ControlChange(ch, 100, lsb),
+		gomidi.ControlChange(ch, 6, value),
+	}
+}
+
+func TestMPEZonesFromConfiguration(t *testing.T) {
+	m := NewMPEState()
+	now := time.Now()
+
+	if zones := m.Zones(MPEAuto); len(zones) != 1 || zones[0] != (MPEZone{Manager: 0, Members: 15}) {
+		t.Fatalf("Zones(auto) before configuration = %v; want lower 15", zones)
+	}
+
+	for _, msg := range rpn(0, 0, 6, 7) {
+		m.Track(noteEvent(msg, now))
+	}
+	for _, msg := range rpn(15, 0, 6, 10) {
+		m.Track(noteEvent(msg, now))
+	}
+
+	// The upper zone shrinks the lower zone so they do not overlap
+	zones := m.Zones(MPEAuto)
+	want := []MPEZone{{Manager: 0, Members: 4}, {Manager: 15, Members: 10}}
+	if len(zones) != 2 || zones[0] != want[0] || zones[1] != want[1] {
+		t.Fatalf("Zones(auto) = %v; want %v", zones, want)
+	}
+	if !m.IsMember(MPEAuto, 4) || m.IsMember(MPEAuto, 0) || !m.IsMember(MPEAuto, 5) || m.IsMember(MPEAuto, 15) {
+		t.Errorf("IsMember gave wrong membership for zones %v", zones)
+	}
+	if m.IsMember(MPEOff, 4) {
+		t.Error("IsMember(off) = true; want false")
+	}
+	if !m.IsMember(MPEUpper, 0) || m.IsMember(MPELower, 0) {
+		t.Error("forced zones gave wrong membership for channel 1")
+	}
+}
+
+func TestMPEBendRange(t *testing.T) {
+	m := NewMPEState()
+	now := time.Now()
+
+	if got := m.BendRange(3); got != DefaultMPEMemberBendRange {
+		t.Errorf("BendRange(member) = %d; want %d", got, DefaultMPEMemberBendRange)
+	}
+	if got := m.BendRange(0); got != DefaultMPEManagerBendRange {
+		t.Errorf("BendRange(manager) = %d; want %d", got, DefaultMPEManagerBendRange)
+	}
+
+	for _, msg := range rpn(1, 0, 0, 24) {
+		m.Track(noteEvent(msg, now))
+	}
+	if got := m.BendRange(1); got != 24 {
+		t.Errorf("BendRange(1) = %d; want 24", got)
+	}
+	if got := m.BendRange(5); got != 24 {
+		t.Errorf("BendRange(5) = %d; want 24 from another member channel", got)
+	}
+
+	// A configuration message resets bend ranges
+	for _, msg := range rpn(0, 0, 6, 15) {
+		m.Track(noteEvent(msg, now))
+	}
+	if got := m.BendRange(1); got != DefaultMPEMemberBendRange {
+		t.Errorf("BendRange(1) after configuration = %d; want %d", got, DefaultMPEMemberBendRange)
+	}
+}
+
+func TestMPEGroupsExpressionUnderNotes(t *testing.T) {
+	m := NewMPEState()
+	start := time.Now()
+
+	m.Track(noteEvent(gomidi.Pitchbend(1, 4096), start)) // initial bend before the note
+	m.Track(noteEvent(gomidi.NoteOn(1, 60, 100), start.Add(time.Millisecond)))
+	m.Track(noteEvent(gomidi.NoteOn(2, 64, 90), start.Add(2*time.Millisecond)))
+	m.Track(noteEvent(gomidi.ControlChange(1, MPETimbreController, 80), start.Add(3*time.Millisecond)))
+	m.Track(noteEvent(gomidi.AfterTouch(2, 70), start.Add(4*time.Millisecond)))
+	m.Track(noteEvent(gomidi.NoteOff(1, 60), start.Add(5*time.Millisecond)))
+	m.Track(noteEvent(gomidi.Pitchbend(1, -8192), start.Add(6*time.Millisecond))) // after release
+
+	first, ok := m.Note(1, 60, start.Add(time.Millisecond))
+	if !ok {
+		t.Fatal("Note(1, 60) not found")
+	}
+	if first.Bend != 4096 || !first.HasTimbre || first.Timbre != 80 || first.HasPressure || first.Updates != 1 {
+		t.Errorf("note on channel 2 = %+v; want bend 4096, timbre 80, 1 update", first)
+	}
+	if got := first.BendSemitones(48); math.Abs(got-24) > 1e-9 {
+		t.Errorf("BendSemitones(48) = %v; want 24", got)
+	}
+	if first.Held() {
+		t.Error("released note is still held")
+	}
+
+	held := m.Held()
+	if len(held) != 1 || held[0].Key != 64 || held[0].Pressure != 70 || !held[0].HasPressure {
+		t.Fatalf("Held() = %+v; want key 64 with pressure 70", held)
+	}
+
+	// Bend after the release becomes the next note's initial bend
+	m.Track(noteEvent(gomidi.NoteOn(1, 62, 100), start.Add(7*time.Millisecond)))
+	next, _ := m.Note(1, 62, start.Add(7*time.Millisecond))
+	if next.Bend != -8192 || next.Updates != 0 {
+		t.Errorf("next note = %+v; want initial bend -8192 with no updates", next)
+	}
+
+	m.Reset()
+	if len(m.Held()) != 0 {
+		t.Error("Held() after Reset is not empty")
+	}
+}
+
+func TestMPENoteOnVelocityZeroReleases(t *testing.T) {
+	m := NewMPEState()
+	start := time.Now()
+
+	m.Track(noteEvent(gomidi.NoteOn(1, 60, 100), start))
+	m.Track(noteEvent(gomidi.NoteOn(1, 60, 0), start.Add(time.Millisecond))) // running status style release
+
+	note, ok := m.Note(1, 60, start)
+	if !ok {
+		t.Fatal("Note(1, 60) not found")
+	}
+	if note.Held() || !note.End.Equal(start.Add(time.Millisecond)) {
+		t.Errorf("note = %+v; want released by the velocity 0 Note On", note)
+	}
+	if len(m.Held()) != 0 {
+		t.Errorf("Held() = %+v; want no held notes", m.Held())
+	}
+}
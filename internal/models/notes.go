@@ -0,0 +1,104 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// HeldNote is a note that has received a Note On but no matching Note Off yet
+type HeldNote struct {
+	Channel uint8
+	Key     uint8
+	Start   time.Time
+	Origin  midi.Event // the Note On that started the note
+}
+
+// NoteTracker keeps track of held notes and Note Offs that had no prior Note On
+type NoteTracker struct {
+	held           map[uint8]map[uint8]HeldNote // channel -> note -> held note
+	OrphanNoteOffs int
+}
+
+// NewNoteTracker creates an empty note tracker
+func NewNoteTracker() NoteTracker {
+	return NoteTracker{
+		held: make(map[uint8]map[uint8]HeldNote),
+	}
+}
+
+// Track updates the held notes from a Note On or Note Off event
+func (t *NoteTracker) Track(event midi.Event) {
+	var ch, key, vel uint8
+
+	switch {
+	case event.Message.GetNoteOn(&ch, &key, &vel) && vel > 0:
+		if t.held[ch] == nil {
+			t.held[ch] = make(map[uint8]HeldNote)
+		}
+		t.held[ch][key] = HeldNote{
+			Channel: ch,
+			Key:     key,
+			Start:   event.Timestamp,
+			Origin:  event,
+		}
+	case event.Message.GetNoteOn(&ch, &key, &vel), event.Message.GetNoteOff(&ch, &key, &vel):
+		// Note On with velocity 0 is a Note Off
+		if _, ok := t.held[ch][key]; !ok {
+			t.OrphanNoteOffs++
+			return
+		}
+		t.Release(ch, key)
+	}
+}
+
+// Release forgets a held note without counting it as an orphan
+func (t *NoteTracker) Release(ch, key uint8) {
+	if t.held[ch] != nil {
+		delete(t.held[ch], key)
+	}
+}
+
+// Reset forgets all held notes and clears the orphan count
+func (t *NoteTracker) Reset() {
+	t.held = make(map[uint8]map[uint8]HeldNote)
+	t.OrphanNoteOffs = 0
+}
+
+// Held returns all held notes ordered by channel and note number
+func (t NoteTracker) Held() []HeldNote {
+	var notes []HeldNote
+	for _, channelNotes := range t.held {
+		for _, note := range channelNotes {
+			notes = append(notes, note)
+		}
+	}
+
+	sort.Slice(notes, func(i, j int) bool {
+		if notes[i].Channel != notes[j].Channel {
+			return notes[i].Channel < notes[j].Channel
+		}
+		return notes[i].Key < notes[j].Key
+	})
+
+	return notes
+}
+
+// Stuck returns the held notes that started more than threshold before now.
+// A zero threshold disables stuck-note detection.
+func (t NoteTracker) Stuck(now time.Time, threshold time.Duration) []HeldNote {
+	var stuck []HeldNote
+	for _, note := range t.Held() {
+		if note.IsStuck(now, threshold) {
+			stuck = append(stuck, note)
+		}
+	}
+
+	return stuck
+}
+
+// IsStuck returns true if the note has been held longer than threshold
+func (n HeldNote) IsStuck(now time.Time, threshold time.Duration) bool {
+	return threshold > 0 && now.Sub(n.Start) > threshold
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+func noteEvent(msg gomidi.Message, at time.Time) midi.Event {
+	event := midi.ParseMessage(msg)
+	event.Timestamp = at
+	return event
+}
+
+func TestNoteTrackerNoteOnOff(t *testing.T) {
+	tracker := NewNoteTracker()
+	start := time.Now()
+
+	tracker.Track(noteEvent(gomidi.NoteOn(0, 60, 100), start))
+	tracker.Track(noteEvent(gomidi.NoteOn(1, 64, 90), start))
+
+	held := tracker.Held()
+	if len(held) != 2 {
+		t.Fatalf("Held() returned %d notes; want 2", len(held))
+	}
+	if held[0].Channel != 0 || held[0].Key != 60 {
+		t.Errorf("Held()[0] = Ch%d:%d; want Ch0:60", held[0].Channel, held[0].Key)
+	}
+	if !held[0].Start.Equal(start) {
+		t.Errorf("Held()[0].Start = %v; want %v", held[0].Start, start)
+	}
+
+	tracker.Track(noteEvent(gomidi.NoteOff(0, 60), start))
+	// Note On with velocity 0 also ends a note
+	tracker.Track(noteEvent(gomidi.NoteOn(1, 64, 0), start))
+
+	if len(tracker.Held()) != 0 {
+		t.Errorf("Held() = %v; want no held notes", tracker.Held())
+	}
+	if tracker.OrphanNoteOffs != 0 {
+		t.Errorf("OrphanNoteOffs = %d; want 0", tracker.OrphanNoteOffs)
+	}
+}
+
+func TestNoteTrackerOrphanNoteOffs(t *testing.T) {
+	tracker := NewNoteTracker()
+
+	tracker.Track(noteEvent(gomidi.NoteOff(0, 60), time.Now()))
+	tracker.Track(noteEvent(gomidi.NoteOn(0, 61, 0), time.Now()))
+
+	if tracker.OrphanNoteOffs != 2 {
+		t.Errorf("OrphanNoteOffs = %d; want 2", tracker.OrphanNoteOffs)
+	}
+
+	tracker.Reset()
+	if tracker.OrphanNoteOffs != 0 {
+		t.Errorf("Reset() should clear OrphanNoteOffs, got %d", tracker.OrphanNoteOffs)
+	}
+}
+
+func TestNoteTrackerStuck(t *testing.T) {
+	tracker := NewNoteTracker()
+	now := time.Now()
+
+	tracker.Track(noteEvent(gomidi.NoteOn(0, 60, 100), now.Add(-10*time.Second)))
+	tracker.Track(noteEvent(gomidi.NoteOn(0, 62, 100), now.Add(-1*time.Second)))
+
+	stuck := tracker.Stuck(now, 5*time.Second)
+	if len(stuck) != 1 || stuck[0].Key != 60 {
+		t.Errorf("Stuck(5s) = %v; want only note 60", stuck)
+	}
+
+	if stuck := tracker.Stuck(now, 0); len(stuck) != 0 {
+		t.Errorf("Stuck(0) = %v; want none when detection is off", stuck)
+	}
+
+	tracker.Release(0, 60)
+	if stuck := tracker.Stuck(now, 5*time.Second); len(stuck) != 0 {
+		t.Errorf("Stuck(5s) after Release = %v; want none", stuck)
+	}
+}
+
+func TestFilterCycleStuckNoteThreshold(t *testing.T) {
+	filter := NewFilter()
+	filter.StuckNoteThreshold = StuckNoteThresholds[len(StuckNoteThresholds)-1]
+
+	filter.CycleStuckNoteThreshold()
+	if filter.StuckNoteThreshold != 0 {
+		t.Errorf("CycleStuckNoteThreshold() from last value = %v; want 0", filter.StuckNoteThreshold)
+	}
+
+	filter.CycleStuckNoteThreshold()
+	if filter.StuckNoteThreshold != StuckNoteThresholds[1] {
+		t.Errorf("CycleStuckNoteThreshold() = %v; want %v", filter.StuckNoteThreshold, StuckNoteThresholds[1])
+	}
+}
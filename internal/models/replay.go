@@ -0,0 +1,179 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// ReplaySpeeds are the selectable replay speeds, from slowest to fastest
+var ReplaySpeeds = []float64{0.25, 0.5, 1, 2, 4}
+
+// Replay sends a capture's events to an output with their original relative timing,
+// scaled by Speed. Only events between From and To (offsets from the first event; To 0
+// is the end) that pass the filter are sent. Steps are matched by session, so steps
+// scheduled before a replay was stopped or restarted are ignored.
+type Replay struct {
+	Name    string
+	Events  []midi.Event // the loaded capture, oldest first
+	Speed   float64
+	Loop    bool
+	From    time.Duration
+	To      time.Duration
+	Running bool
+	Sent    int // events sent since the replay started, across loops
+	Loops   int // times the range has restarted
+
+	session int
+	next    int       // index of the next event to consider
+	started time.Time // when the event at From is due
+}
+
+// NewReplay creates a replay with nothing loaded
+func NewReplay() Replay {
+	return Replay{Speed: 1}
+}
+
+// Load replaces the capture, stopping any replay and resetting the range
+func (r *Replay) Load(name string, events []midi.Event) {
+	r.Stop()
+	r.Name = name
+	r.Events = events
+	r.From, r.To = 0, 0
+}
+
+// Length returns the time from the first to the last event of the capture
+func (r Replay) Length() time.Duration {
+	if len(r.Events) == 0 {
+		return 0
+	}
+	return r.offset(len(r.Events) - 1)
+}
+
+// Position returns the offset of the next event to be sent, or From when stopped
+func (r Replay) Position() time.Duration {
+	if !r.Running || r.next >= len(r.Events) {
+		return r.From
+	}
+	return r.offset(r.next)
+}
+
+// SetRange sets the part of the capture to replay, clamped to the capture's length
+func (r *Replay) SetRange(from, to time.Duration) {
+	length := r.Length()
+	r.From = min(max(from, 0), length)
+	r.To = 0
+	if to > r.From && to < length {
+		r.To = to
+	}
+}
+
+// CycleSpeed moves the speed one selectable value faster or slower
+func (r *Replay) CycleSpeed(faster bool) {
+	for i, speed := range ReplaySpeeds {
+		if speed == r.Speed {
+			if faster && i < len(ReplaySpeeds)-1 {
+				r.Speed = ReplaySpeeds[i+1]
+			} else if !faster && i > 0 {
+				r.Speed = ReplaySpeeds[i-1]
+			}
+			return
+		}
+	}
+	r.Speed = 1
+}
+
+// Start starts replaying the range from the beginning at now and returns the session
+func (r *Replay) Start(now time.Time) int {
+	r.session++
+	r.Running = true
+	r.Sent = 0
+	r.Loops = 0
+	r.started = now
+	r.next = r.first()
+	return r.session
+}
+
+// Stop stops the replay; steps already scheduled are ignored
+func (r *Replay) Stop() {
+	r.session++
+	r.Running = false
+}
+
+// IsSession reports whether a scheduled step belongs to the running replay
+func (r Replay) IsSession(session int) bool {
+	return r.Running && session == r.session
+}
+
+// Step returns the next events to send and when they are due: the next event the filter
+// passes and any after it that are due by then or by now, whichever is later. It returns
+// no events once the range is done, stopping the replay unless it loops.
+func (r *Replay) Step(now time.Time, filter Filter) ([]midi.Event, time.Time) {
+	if !r.Running {
+		return nil, time.Time{}
+	}
+
+	var batch []midi.Event
+	var due time.Time
+	for pass := 0; pass < 2; pass++ { // a second pass starts the range again when looping
+		for ; r.next < len(r.Events) && r.inRange(r.next); r.next++ {
+			if !filter.ShouldShow(r.Events[r.next]) {
+				continue
+			}
+			at := r.dueAt(r.next)
+			if batch == nil {
+				due = at
+			} else if at.After(due) && at.After(now) {
+				return batch, due
+			}
+			batch = append(batch, r.Events[r.next])
+			r.Sent++
+		}
+		if batch != nil {
+			return batch, due
+		}
+
+		// End of the range. Looping keeps the rhythm: the range restarts when its end is due.
+		span := r.end() - r.From
+		if !r.Loop || span <= 0 {
+			r.Running = false
+			return nil, time.Time{}
+		}
+		r.started = r.started.Add(time.Duration(float64(span) / r.Speed))
+		r.next = r.first()
+		r.Loops++
+	}
+
+	// The filter passes nothing in the range
+	r.Running = false
+	return nil, time.Time{}
+}
+
+// offset returns how long after the first event event i was recorded
+func (r Replay) offset(i int) time.Duration {
+	return r.Events[i].Timestamp.Sub(r.Events[0].Timestamp)
+}
+
+// end returns the offset the range ends at
+func (r Replay) end() time.Duration {
+	if r.To > 0 {
+		return r.To
+	}
+	return r.Length()
+}
+
+// first returns the index of the first event in the range
+func (r Replay) first() int {
+	return sort.Search(len(r.Events), func(i int) bool { return r.offset(i) >= r.From })
+}
+
+// inRange reports whether event i is before the end of the range
+func (r Replay) inRange(i int) bool {
+	return r.To == 0 || r.offset(i) <= r.To
+}
+
+// dueAt returns when event i is to be sent
+func (r Replay) dueAt(i int) time.Time {
+	return r.started.Add(time.Duration(float64(r.offset(i)-r.From) / r.Speed))
+}
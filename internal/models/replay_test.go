@@ -0,0 +1,131 @@
+package models
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+// replayCapture returns Note Ons on channel 1 at 0, 100ms and 200ms and a CC on channel 2 at 100ms
+func replayCapture() []midi.Event {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []midi.Event{
+		noteEvent(gomidi.NoteOn(0, 60, 100), at),
+		noteEvent(gomidi.NoteOn(0, 62, 100), at.Add(100*time.Millisecond)),
+		noteEvent(gomidi.ControlChange(1, 7, 90), at.Add(100*time.Millisecond)),
+		noteEvent(gomidi.NoteOn(0, 64, 100), at.Add(200*time.Millisecond)),
+	}
+}
+
+func TestReplayTiming(t *testing.T) {
+	r := NewReplay()
+	r.Load("capture", replayCapture())
+	r.Speed = 2
+	start := time.Now()
+	r.Start(start)
+
+	batch, due := r.Step(start, NewFilter())
+	if len(batch) != 1 || !due.Equal(start) {
+		t.Fatalf("first step = %d events due %v; want 1 due at start", len(batch), due.Sub(start))
+	}
+
+	// Events recorded together go out together, at half the original offset
+	batch, due = r.Step(start, NewFilter())
+	if len(batch) != 2 || due.Sub(start) != 50*time.Millisecond {
+		t.Errorf("second step = %d events due %v; want 2 due at 50ms", len(batch), due.Sub(start))
+	}
+
+	// A late step also takes everything already due
+	r.Start(start)
+	r.Step(start, NewFilter())
+	batch, _ = r.Step(start.Add(time.Second), NewFilter())
+	if len(batch) != 3 {
+		t.Errorf("late step = %d events; want 3", len(batch))
+	}
+
+	if batch, _ = r.Step(start.Add(time.Second), NewFilter()); batch != nil || r.Running {
+		t.Errorf("step after the end = %d events, running %t; want none, stopped", len(batch), r.Running)
+	}
+	if r.Sent != 4 {
+		t.Errorf("Sent = %d; want 4", r.Sent)
+	}
+}
+
+func TestReplayRangeAndFilter(t *testing.T) {
+	r := NewReplay()
+	r.Load("capture", replayCapture())
+	r.SetRange(50*time.Millisecond, 150*time.Millisecond)
+	filter := NewFilter()
+	filter.ToggleChannel(1)
+
+	start := time.Now()
+	r.Start(start)
+	batch, due := r.Step(start, filter)
+	if len(batch) != 1 || batch[0].RawBytes[1] != 62 || due.Sub(start) != 50*time.Millisecond {
+		t.Fatalf("step = %v due %v; want note 62 alone at 50ms", batch, due.Sub(start))
+	}
+	if batch, _ = r.Step(start, filter); batch != nil || r.Running {
+		t.Errorf("note 64 is after the range but was stepped: %v", batch)
+	}
+}
+
+func TestReplayLoop(t *testing.T) {
+	r := NewReplay()
+	r.Load("capture", replayCapture())
+	r.Loop = true
+
+	start := time.Now()
+	r.Start(start)
+	for i := 0; i < 3; i++ {
+		r.Step(start, NewFilter())
+	}
+
+	// The range restarts when its end is due, 200ms after the start
+	batch, due := r.Step(start, NewFilter())
+	if len(batch) != 1 || due.Sub(start) != 200*time.Millisecond || r.Loops != 1 || !r.Running {
+		t.Errorf("looped step = %d events due %v after %d loops; want 1 due at 200ms after 1 loop", len(batch), due.Sub(start), r.Loops)
+	}
+
+	session := r.Start(start)
+	r.Stop()
+	if r.IsSession(session) {
+		t.Error("a stopped replay should ignore its scheduled steps")
+	}
+}
+
+func TestReadEventsRoundTrip(t *testing.T) {
+	events := replayCapture()
+	events = append(events, midi.DecodeUMP([]uint32{0x40903C00, 0xFFFF0000}))
+	events[4].Timestamp = events[3].Timestamp.Add(time.Millisecond)
+	events = append(events, midi.NewMarker("pressed preset 3", events[4].Timestamp))
+	events[1].Bookmarked = true
+
+	var buf bytes.Buffer
+	if err := WriteEvents(&buf, events, ExportJSONL); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadEvents(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(events) {
+		t.Fatalf("read %d events; want %d", len(read), len(events))
+	}
+	for i := range events {
+		if !read[i].Timestamp.Equal(events[i].Timestamp) || !bytes.Equal(read[i].RawBytes, events[i].RawBytes) {
+			t.Errorf("event %d = %v % X; want %v % X", i, read[i].Timestamp, read[i].RawBytes, events[i].Timestamp, events[i].RawBytes)
+		}
+	}
+	if read[4].Packet == nil || read[4].HighRes != 0xFFFF {
+		t.Errorf("UMP event lost its packet: %+v", read[4])
+	}
+	if read[5].MessageType != midi.MarkerMessageType || read[5].Data != "pressed preset 3" {
+		t.Errorf("marker = %+v; want a marker named pressed preset 3", read[5])
+	}
+	if !read[1].Bookmarked || read[2].Bookmarked {
+		t.Error("only the bookmarked event should be read as bookmarked")
+	}
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// SeriesKind is the kind of continuous value a series follows
+type SeriesKind int
+
+const (
+	SeriesController SeriesKind = iota // a control change
+	SeriesPitchBend                    // pitch bend
+	SeriesAftertouch                   // channel pressure
+)
+
+// Series identifies one continuous value on one channel, such as CC 1 on channel 1
+type Series struct {
+	Kind       SeriesKind
+	Channel    uint8
+	Controller uint8 // for SeriesController
+}
+
+func (s Series) String() string {
+	switch s.Kind {
+	case SeriesPitchBend:
+		return fmt.Sprintf("Ch%d Pitch Bend", s.Channel+1)
+	case SeriesAftertouch:
+		return fmt.Sprintf("Ch%d Aftertouch", s.Channel+1)
+	}
+	return fmt.Sprintf("Ch%d CC %d %s", s.Channel+1, s.Controller, midi.ControllerName(s.Controller))
+}
+
+// Point is a series value at a time, scaled to 0-1
+type Point struct {
+	At    time.Time
+	Value float64
+}
+
+// GraphWindows are the selectable graph time spans, from most to least zoomed in;
+// 0 shows everything in the event buffer
+var GraphWindows = []time.Duration{
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	0,
+}
+
+// seriesOf returns the series an event sets and its value scaled to 0-1. Events decoded from
+// MIDI 2.0 packets use their full 32-bit value, so a high-resolution fader's extra steps show.
+func seriesOf(event midi.Event) (Series, float64, bool) {
+	var ch, controller, value, pressure uint8
+	var bend int16
+	var abs uint16
+
+	highRes := event.HighResBits == 32
+	full := float64(event.HighRes) / math.MaxUint32
+
+	switch {
+	case event.Message.GetControlChange(&ch, &controller, &value):
+		s := Series{Kind: SeriesController, Channel: ch, Controller: controller}
+		if highRes {
+			return s, full, true
+		}
+		return s, float64(value) / 127, true
+	case event.Message.GetPitchBend(&ch, &bend, &abs):
+		s := Series{Kind: SeriesPitchBend, Channel: ch}
+		if highRes {
+			return s, full, true
+		}
+		return s, float64(abs) / 16383, true
+	case event.Message.GetAfterTouch(&ch, &pressure):
+		s := Series{Kind: SeriesAftertouch, Channel: ch}
+		if highRes {
+			return s, full, true
+		}
+		return s, float64(pressure) / 127, true
+	}
+	return Series{}, 0, false
+}
+
+// SeriesPoints returns the values a series took in events, in order
+func SeriesPoints(events []midi.Event, series Series) []Point {
+	var points []Point
+	for _, event := range events {
+		if s, value, ok := seriesOf(event); ok && s == series {
+			points = append(points, Point{At: event.Timestamp, Value: value})
+		}
+	}
+	return points
+}
+
+// AvailableSeries returns the series that have values in events, ordered by channel,
+// then pitch bend, aftertouch and controllers by number
+func AvailableSeries(events []midi.Event) []Series {
+	seen := make(map[Series]bool)
+	var series []Series
+	for _, event := range events {
+		if s, _, ok := seriesOf(event); ok && !seen[s] {
+			seen[s] = true
+			series = append(series, s)
+		}
+	}
+
+	order := func(k SeriesKind) int {
+		switch k {
+		case SeriesPitchBend:
+			return 0
+		case SeriesAftertouch:
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(series, func(i, j int) bool {
+		a, b := series[i], series[j]
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		if a.Kind != b.Kind {
+			return order(a.Kind) < order(b.Kind)
+		}
+		return a.Controller < b.Controller
+	})
+	return series
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+func TestSeriesPoints(t *testing.T) {
+	start := time.Now()
+	events := []midi.Event{
+		noteEvent(gomidi.ControlChange(0, 1, 0), start),
+		noteEvent(gomidi.NoteOn(0, 60, 100), start.Add(time.Millisecond)),
+		noteEvent(gomidi.ControlChange(0, 7, 100), start.Add(2*time.Millisecond)),
+		noteEvent(gomidi.ControlChange(0, 1, 127), start.Add(3*time.Millisecond)),
+		noteEvent(gomidi.ControlChange(1, 1, 64), start.Add(4*time.Millisecond)),
+	}
+
+	points := SeriesPoints(events, Series{Kind: SeriesController, Channel: 0, Controller: 1})
+	if len(points) != 2 || points[0].Value != 0 || points[1].Value != 1 || !points[1].At.Equal(start.Add(3*time.Millisecond)) {
+		t.Errorf("SeriesPoints(Ch1 CC 1) = %+v; want 0 then 1 at +3ms", points)
+	}
+}
+
+func TestSeriesPointsScaling(t *testing.T) {
+	now := time.Now()
+	events := []midi.Event{
+		noteEvent(gomidi.Pitchbend(2, 0), now),
+		noteEvent(gomidi.AfterTouch(2, 127), now),
+	}
+
+	if p := SeriesPoints(events, Series{Kind: SeriesPitchBend, Channel: 2}); len(p) != 1 || math.Abs(p[0].Value-0.5) > 0.001 {
+		t.Errorf("centred pitch bend = %+v; want 0.5", p)
+	}
+	if p := SeriesPoints(events, Series{Kind: SeriesAftertouch, Channel: 2}); len(p) != 1 || p[0].Value != 1 {
+		t.Errorf("full aftertouch = %+v; want 1", p)
+	}
+
+	// MIDI 2.0 values keep their full resolution
+	highRes := noteEvent(gomidi.ControlChange(0, 74, 64), now)
+	highRes.HighRes, highRes.HighResBits = 0x80000001, 32
+	if p := SeriesPoints([]midi.Event{highRes}, Series{Kind: SeriesController, Controller: 74}); len(p) != 1 || p[0].Value == 64.0/127 {
+		t.Errorf("high-resolution CC = %+v; want the 32-bit value", p)
+	}
+}
+
+func TestAvailableSeries(t *testing.T) {
+	now := time.Now()
+	events := []midi.Event{
+		noteEvent(gomidi.ControlChange(1, 7, 100), now),
+		noteEvent(gomidi.ControlChange(0, 64, 127), now),
+		noteEvent(gomidi.ControlChange(0, 1, 10), now),
+		noteEvent(gomidi.Pitchbend(0, 100), now),
+		noteEvent(gomidi.ControlChange(0, 1, 20), now),
+		noteEvent(gomidi.NoteOn(0, 60, 100), now),
+	}
+
+	got := AvailableSeries(events)
+	want := []Series{
+		{Kind: SeriesPitchBend, Channel: 0},
+		{Kind: SeriesController, Channel: 0, Controller: 1},
+		{Kind: SeriesController, Channel: 0, Controller: 64},
+		{Kind: SeriesController, Channel: 1, Controller: 7},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("AvailableSeries() = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("AvailableSeries()[%d] = %s; want %s", i, got[i], want[i])
+		}
+	}
+
+	if s := want[1].String(); s != "Ch1 CC 1 Mod Wheel" {
+		t.Errorf("String() = %q; want Ch1 CC 1 Mod Wheel", s)
+	}
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"midi-viewer/internal/midi"
+)
+
+// MaxPanes limits how many panes a split view has
+const MaxPanes = 8
+
+// Pane is one event list of a split view, with its own filter and scroll position
+type Pane struct {
+	Filter Filter
+	Scroll int // events scrolled back from the newest (0 = follow the newest)
+}
+
+// NewChannelPane creates a pane showing only the given channels (0-15), with the other
+// settings of base; without channels it shows what base shows
+func NewChannelPane(base Filter, channels ...uint8) Pane {
+	filter := base.Clone()
+	if len(channels) > 0 {
+		for ch := uint8(0); ch < 16; ch++ {
+			filter.HiddenChannels[ch] = true
+		}
+		for _, ch := range channels {
+			delete(filter.HiddenChannels, ch)
+		}
+	}
+	return Pane{Filter: filter}
+}
+
+// Title names the pane after its visible channels, e.g. "Ch 10" or "Ch 1-4, 10", noting
+// hidden message types
+func (p Pane) Title() string {
+	var visible []uint8
+	for ch := uint8(0); ch < 16; ch++ {
+		if p.Filter.IsChannelVisible(ch) {
+			visible = append(visible, ch)
+		}
+	}
+
+	title := "Ch " + formatChannels(visible)
+	switch len(visible) {
+	case 16:
+		title = "All channels"
+	case 0:
+		title = "No channels"
+	}
+	if len(p.Filter.HiddenMessageTypes) > 0 {
+		title += " (some types hidden)"
+	}
+	return title
+}
+
+// Track keeps a scrolled pane on the same events as new ones arrive
+func (p *Pane) Track(event midi.Event) {
+	if p.Scroll > 0 && p.Filter.ShouldShow(event) {
+		p.Scroll++
+	}
+}
+
+// Events returns the events the pane shows, oldest first
+func (p Pane) Events(events []midi.Event) []midi.Event {
+	var shown []midi.Event
+	for _, event := range events {
+		if p.Filter.ShouldShow(event) {
+			shown = append(shown, event)
+		}
+	}
+	return shown
+}
+
+// ActiveChannels returns the channels (0-15) that channel messages were received on, in order
+func ActiveChannels(events []midi.Event) []uint8 {
+	seen := make(map[uint8]bool)
+	var channels []uint8
+	for _, event := range events {
+		var ch uint8
+		if event.Message.GetChannel(&ch) && !seen[ch] {
+			seen[ch] = true
+			channels = append(channels, ch)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i] < channels[j] })
+	return channels
+}
+
+// ParseChannels reads a list of channels as numbered on devices (1-16) and returns them
+// 0-based: single channels and ranges separated by commas, such as "10" or "1-4, 10"
+func ParseChannels(s string) ([]uint8, error) {
+	seen := make(map[uint8]bool)
+	var channels []uint8
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(field, "-")
+		first, err := parseChannel(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseChannel(to); err != nil {
+				return nil, err
+			}
+			if last < first {
+				return nil, fmt.Errorf("channel range %q is backwards", field)
+			}
+		}
+		for ch := first; ch <= last; ch++ {
+			if !seen[ch] {
+				seen[ch] = true
+				channels = append(channels, ch)
+			}
+		}
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels in %q", s)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i] < channels[j] })
+	return channels, nil
+}
+
+// parseChannel reads a channel numbered 1-16 and returns it 0-based
+func parseChannel(s string) (uint8, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 || n > 16 {
+		return 0, fmt.Errorf("%q is not a channel from 1 to 16", strings.TrimSpace(s))
+	}
+	return uint8(n - 1), nil
+}
+
+// formatChannels writes sorted 0-based channels as numbered on devices, joining runs
+// into ranges
+func formatChannels(channels []uint8) string {
+	var parts []string
+	for i := 0; i < len(channels); {
+		j := i
+		for j+1 < len(channels) && channels[j+1] == channels[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", channels[i]+1, channels[j]+1))
+		} else {
+			parts = append(parts, strconv.Itoa(int(channels[i])+1))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SplitLayout returns the columns and rows to tile n panes in, as many columns side by side
+// as fit width with each at least minWidth wide
+func SplitLayout(n, width, minWidth int) (cols, rows int) {
+	if n < 1 {
+		return 0, 0
+	}
+	cols = max(min(width/minWidth, n), 1)
+	rows = (n + cols - 1) / cols
+	return cols, rows
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+func TestNewChannelPane(t *testing.T) {
+	base := NewFilter()
+	base.ToggleMessageType("Clock")
+	pane := NewChannelPane(base, 9)
+
+	if pane.Filter.IsChannelVisible(0) || !pane.Filter.IsChannelVisible(9) {
+		t.Error("a channel 10 pane should show only channel 10")
+	}
+	if !base.IsChannelVisible(0) {
+		t.Error("creating a pane should not change the base filter")
+	}
+	if got := pane.Title(); got != "Ch 10 (some types hidden)" {
+		t.Errorf("Title() = %q; want %q", got, "Ch 10 (some types hidden)")
+	}
+	if got := NewChannelPane(NewFilter(), 0, 1, 2, 3, 9).Title(); got != "Ch 1-4, 10" {
+		t.Errorf("Title() = %q; want %q", got, "Ch 1-4, 10")
+	}
+	if got := NewChannelPane(NewFilter()).Title(); got != "All channels" {
+		t.Errorf("Title() = %q; want %q", got, "All channels")
+	}
+}
+
+func TestPaneTrack(t *testing.T) {
+	pane := NewChannelPane(NewFilter(), 9)
+	drum := midi.ParseMessage(gomidi.NoteOn(9, 36, 100))
+	bass := midi.ParseMessage(gomidi.NoteOn(1, 40, 100))
+
+	pane.Track(drum)
+	if pane.Scroll != 0 {
+		t.Error("a pane following the newest event should keep following")
+	}
+
+	pane.Scroll = 3
+	pane.Track(bass)
+	pane.Track(drum)
+	if pane.Scroll != 4 {
+		t.Errorf("Scroll = %d; want 4 (only the shown event moves the pane)", pane.Scroll)
+	}
+
+	if shown := pane.Events([]midi.Event{drum, bass, drum}); len(shown) != 2 {
+		t.Errorf("Events() = %d events; want the 2 on channel 10", len(shown))
+	}
+}
+
+func TestActiveChannels(t *testing.T) {
+	events := []midi.Event{
+		noteEvent(gomidi.NoteOn(9, 36, 100), time.Now()),
+		noteEvent(gomidi.TimingClock(), time.Now()),
+		noteEvent(gomidi.ControlChange(1, 7, 90), time.Now()),
+		noteEvent(gomidi.NoteOn(9, 38, 100), time.Now()),
+	}
+	if got := ActiveChannels(events); !reflect.DeepEqual(got, []uint8{1, 9}) {
+		t.Errorf("ActiveChannels() = %v; want [1 9]", got)
+	}
+}
+
+func TestParseChannels(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []uint8
+	}{
+		{"10", []uint8{9}},
+		{"1-4, 10", []uint8{0, 1, 2, 3, 9}},
+		{"2,1,2", []uint8{0, 1}},
+	}
+	for _, tt := range tests {
+		got, err := ParseChannels(tt.in)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseChannels(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "0", "17", "4-1", "drums"} {
+		if _, err := ParseChannels(bad); err == nil {
+			t.Errorf("ParseChannels(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSplitLayout(t *testing.T) {
+	tests := []struct {
+		n, width, cols, rows int
+	}{
+		{1, 120, 1, 1},
+		{2, 120, 2, 1},
+		{4, 120, 3, 2},
+		{4, 60, 1, 4},
+		{3, 30, 1, 3},
+	}
+	for _, tt := range tests {
+		cols, rows := SplitLayout(tt.n, tt.width, 40)
+		if cols != tt.cols || rows != tt.rows {
+			t.Errorf("SplitLayout(%d, %d, 40) = %d, %d; want %d, %d", tt.n, tt.width, cols, rows, tt.cols, tt.rows)
+		}
+	}
+}
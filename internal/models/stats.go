@@ -0,0 +1,165 @@
+package models
+
+import (
+	"time"
+
+	"midi-viewer/internal/midi"
+)
+
+// StatsWindow is the length of the rolling window used for rates
+const StatsWindow = time.Second
+
+// DINBytesPerSecond is the bandwidth of a 5-pin DIN MIDI link: 31250 baud at 10 bits per byte
+const DINBytesPerSecond = 31250.0 / 10
+
+// statsSample is one message remembered for rolling rate calculation
+type statsSample struct {
+	at         time.Time
+	bytes      int
+	channel    uint8
+	hasChannel bool
+}
+
+// Stats keeps per-channel and per-type message counters plus rolling rates
+type Stats struct {
+	Start        time.Time
+	Messages     int
+	Bytes        int
+	ByChannel    map[uint8]int  // channel -> message count (channel messages only)
+	ByType       map[string]int // message type -> message count
+	PeakMessages int            // most messages seen within one window
+	PeakBytes    int            // most bytes seen within one window
+	Driver       Latency        // from the driver's timestamp to the listener callback
+	App          Latency        // from the listener callback to the viewer handling the event
+	window       []statsSample
+	windowBytes  int
+}
+
+// NewStats creates empty statistics
+func NewStats() Stats {
+	return Stats{
+		ByChannel: make(map[uint8]int),
+		ByType:    make(map[string]int),
+	}
+}
+
+// Track counts an event and updates the rolling window and peaks
+func (s *Stats) Track(event midi.Event) {
+	if s.Messages == 0 {
+		s.Start = event.Timestamp
+	}
+
+	size := len(event.RawBytes)
+	if event.RunningStatus {
+		size-- // the status byte was not sent
+	}
+	s.Messages++
+	s.Bytes += size
+	s.ByType[event.MessageType]++
+
+	sample := statsSample{at: event.Timestamp, bytes: size}
+	if event.Message.GetChannel(&sample.channel) {
+		sample.hasChannel = true
+		s.ByChannel[sample.channel]++
+	}
+
+	if !event.Arrived.IsZero() {
+		s.Driver.Add(event.Arrived.Sub(event.Timestamp))
+		if !event.Processed.IsZero() {
+			s.App.Add(event.Processed.Sub(event.Arrived))
+		}
+	}
+
+	s.window = append(s.window, sample)
+	s.windowBytes += size
+	s.prune(event.Timestamp)
+
+	if len(s.window) > s.PeakMessages {
+		s.PeakMessages = len(s.window)
+	}
+	if s.windowBytes > s.PeakBytes {
+		s.PeakBytes = s.windowBytes
+	}
+}
+
+// prune drops samples that have fallen out of the rolling window ending at now
+func (s *Stats) prune(now time.Time) {
+	cutoff := now.Add(-StatsWindow)
+	drop := 0
+	for drop < len(s.window) && !s.window[drop].at.After(cutoff) {
+		s.windowBytes -= s.window[drop].bytes
+		drop++
+	}
+	s.window = s.window[drop:]
+}
+
+// Reset clears all counters
+func (s *Stats) Reset() {
+	*s = NewStats()
+}
+
+// Rates returns the messages and bytes per second over the window ending at now
+func (s Stats) Rates(now time.Time) (messages, bytes float64) {
+	cutoff := now.Add(-StatsWindow)
+	for _, sample := range s.window {
+		if sample.at.After(cutoff) {
+			messages++
+			bytes += float64(sample.bytes)
+		}
+	}
+
+	seconds := StatsWindow.Seconds()
+	return messages / seconds, bytes / seconds
+}
+
+// ChannelRate returns the messages per second on a channel over the window ending at now
+func (s Stats) ChannelRate(ch uint8, now time.Time) float64 {
+	cutoff := now.Add(-StatsWindow)
+	count := 0
+	for _, sample := range s.window {
+		if sample.hasChannel && sample.channel == ch && sample.at.After(cutoff) {
+			count++
+		}
+	}
+
+	return float64(count) / StatsWindow.Seconds()
+}
+
+// PeakRates returns the peak messages and bytes per second seen in any window
+func (s Stats) PeakRates() (messages, bytes float64) {
+	seconds := StatsWindow.Seconds()
+	return float64(s.PeakMessages) / seconds, float64(s.PeakBytes) / seconds
+}
+
+// Latency summarises the delays measured between two points of the event pipeline
+type Latency struct {
+	Count int
+	Total time.Duration
+	Min   time.Duration
+	Max   time.Duration
+}
+
+// Add records one delay
+func (l *Latency) Add(d time.Duration) {
+	if l.Count == 0 || d < l.Min {
+		l.Min = d
+	}
+	if d > l.Max {
+		l.Max = d
+	}
+	l.Count++
+	l.Total += d
+}
+
+// Mean returns the average delay, or 0 if none has been recorded
+func (l Latency) Mean() time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+	return l.Total / time.Duration(l.Count)
+}
+
+// DINUtilisation returns a byte rate as a percentage of the DIN MIDI bandwidth
+func DINUtilisation(bytesPerSecond float64) float64 {
+	return bytesPerSecond / DINBytesPerSecond * 100
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"midi-viewer/internal/midi"
+)
+
+func TestStatsCounters(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+
+	stats.Track(noteEvent(gomidi.NoteOn(0, 60, 100), now))
+	stats.Track(noteEvent(gomidi.ControlChange(0, 7, 100), now))
+	stats.Track(noteEvent(gomidi.ControlChange(9, 7, 100), now))
+	stats.Track(noteEvent(gomidi.TimingClock(), now))
+
+	if stats.Messages != 4 {
+		t.Errorf("Messages = %d; want 4", stats.Messages)
+	}
+	if stats.Bytes != 10 {
+		t.Errorf("Bytes = %d; want 10", stats.Bytes)
+	}
+	if stats.ByChannel[0] != 2 || stats.ByChannel[9] != 1 {
+		t.Errorf("ByChannel = %v; want ch0=2, ch9=1", stats.ByChannel)
+	}
+	if len(stats.ByChannel) != 2 {
+		t.Errorf("ByChannel = %v; clock should not be counted on a channel", stats.ByChannel)
+	}
+	if stats.ByType["CC"] != 2 || stats.ByType["Clock"] != 1 {
+		t.Errorf("ByType = %v; want CC=2, Clock=1", stats.ByType)
+	}
+}
+
+func TestStatsRunningStatusBytes(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+
+	// 90 3C 64 3E 64: the second note reuses the first one's status byte
+	for _, event := range midi.Parse([]byte{0x90, 0x3C, 0x64, 0x3E, 0x64}, now) {
+		stats.Track(event)
+	}
+
+	if stats.Messages != 2 || stats.Bytes != 5 {
+		t.Errorf("Messages, Bytes = %d, %d; want 2, 5", stats.Messages, stats.Bytes)
+	}
+}
+
+func TestStatsRollingRates(t *testing.T) {
+	stats := NewStats()
+	start := time.Now()
+
+	// 10 messages in the first half second
+	for i := 0; i < 10; i++ {
+		stats.Track(noteEvent(gomidi.ControlChange(0, 1, uint8(i)), start.Add(time.Duration(i)*50*time.Millisecond)))
+	}
+
+	msgs, bytes := stats.Rates(start.Add(500 * time.Millisecond))
+	if msgs != 10 || bytes != 30 {
+		t.Errorf("Rates() = %.0f msg/s, %.0f B/s; want 10, 30", msgs, bytes)
+	}
+
+	// One message two seconds later pushes the burst out of the window
+	stats.Track(noteEvent(gomidi.ControlChange(0, 1, 0), start.Add(2*time.Second)))
+
+	msgs, _ = stats.Rates(start.Add(2 * time.Second))
+	if msgs != 1 {
+		t.Errorf("Rates() after burst = %.0f msg/s; want 1", msgs)
+	}
+
+	peakMsgs, peakBytes := stats.PeakRates()
+	if peakMsgs != 10 || peakBytes != 30 {
+		t.Errorf("PeakRates() = %.0f msg/s, %.0f B/s; want 10, 30", peakMsgs, peakBytes)
+	}
+
+	if rate := stats.ChannelRate(0, start.Add(2*time.Second)); rate != 1 {
+		t.Errorf("ChannelRate(0) = %.0f; want 1", rate)
+	}
+}
+
+func TestStatsReset(t *testing.T) {
+	stats := NewStats()
+	stats.Track(midi.ParseMessage(gomidi.NoteOn(0, 60, 100)))
+
+	stats.Reset()
+
+	if stats.Messages != 0 || len(stats.ByType) != 0 || stats.PeakMessages != 0 {
+		t.Errorf("Reset() left counters: %+v", stats)
+	}
+}
+
+func TestDINUtilisation(t *testing.T) {
+	if got := DINUtilisation(3125); math.Abs(got-100) > 0.001 {
+		t.Errorf("DINUtilisation(3125) = %.3f; want 100", got)
+	}
+	if got := DINUtilisation(312.5); math.Abs(got-10) > 0.001 {
+		t.Errorf("DINUtilisation(312.5) = %.3f; want 10", got)
+	}
+}
+
+func TestStatsLatency(t *testing.T) {
+	stats := NewStats()
+	start := time.Now()
+
+	for i, delays := range [][2]time.Duration{{time.Millisecond, 100 * time.Microsecond}, {3 * time.Millisecond, 300 * time.Microsecond}} {
+		event := midi.ParseMessage(gomidi.NoteOn(0, 60, 100))
+		event.Timestamp = start.Add(time.Duration(i) * time.Second)
+		event.Arrived = event.Timestamp.Add(delays[0])
+		event.Processed = event.Arrived.Add(delays[1])
+		stats.Track(event)
+	}
+
+	// Events without arrival times, such as those read from files, are not counted
+	stats.Track(midi.ParseMessage(gomidi.NoteOff(0, 60)))
+
+	if stats.Driver.Count != 2 || stats.Driver.Mean() != 2*time.Millisecond ||
+		stats.Driver.Min != time.Millisecond || stats.Driver.Max != 3*time.Millisecond {
+		t.Errorf("Driver = %+v; want 2 delays from 1ms to 3ms averaging 2ms", stats.Driver)
+	}
+	if stats.App.Count != 2 || stats.App.Mean() != 200*time.Microsecond {
+		t.Errorf("App = %+v; want 2 delays averaging 200µs", stats.App)
+	}
+	if (Latency{}).Mean() != 0 {
+		t.Error("Mean() of no delays should be 0")
+	}
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeMode selects what the Time column shows
+type TimeMode int
+
+const (
+	TimeAbsolute TimeMode = iota // wall clock time
+	TimeElapsed                  // time since capture started
+	TimeDelta                    // time since the previous visible event
+	TimeMarker                   // time relative to the time marker
+)
+
+// TimeModes are the selectable time modes, in cycling order
+var TimeModes = []TimeMode{TimeAbsolute, TimeElapsed, TimeDelta, TimeMarker}
+
+func (m TimeMode) String() string {
+	switch m {
+	case TimeElapsed:
+		return "elapsed"
+	case TimeDelta:
+		return "delta"
+	case TimeMarker:
+		return "marker"
+	}
+	return "absolute"
+}
+
+// Heading returns the Time column heading for the mode
+func (m TimeMode) Heading() string {
+	switch m {
+	case TimeElapsed:
+		return "Elapsed"
+	case TimeDelta:
+		return "Delta"
+	case TimeMarker:
+		return "From Marker"
+	}
+	return "Time"
+}
+
+// FormatTime renders a timestamp for the Time column. ref is the capture start, the previous
+// visible event or the marker depending on mode; a zero ref leaves relative times blank.
+func FormatTime(mode TimeMode, t, ref time.Time, micro bool) string {
+	if mode == TimeAbsolute {
+		if micro {
+			return t.Format("15:04:05.000000")
+		}
+		return t.Format("15:04:05.000")
+	}
+	if ref.IsZero() {
+		return ""
+	}
+	return FormatDuration(t.Sub(ref), mode != TimeElapsed, micro)
+}
+
+// FormatDuration renders a duration as seconds with millisecond or microsecond precision,
+// with minutes and hours above a minute. signed adds a + to positive durations.
+func FormatDuration(d time.Duration, signed, micro bool) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	} else if signed {
+		sign = "+"
+	}
+
+	unit, digits := time.Millisecond, 3
+	if micro {
+		unit, digits = time.Microsecond, 6
+	}
+	d = d.Round(unit)
+
+	hours := d / time.Hour
+	minutes := d % time.Hour / time.Minute
+	seconds := d % time.Minute / time.Second
+	fraction := d % time.Second / unit
+
+	switch {
+	case hours > 0:
+		return fmt.Sprintf("%s%d:%02d:%02d.%0*d", sign, hours, minutes, seconds, digits, fraction)
+	case minutes > 0:
+		return fmt.Sprintf("%s%d:%02d.%0*d", sign, minutes, seconds, digits, fraction)
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, seconds, digits, fraction)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d      time.Duration
+		signed bool
+		micro  bool
+		want   string
+	}{
+		{1234 * time.Millisecond, false, false, "1.234"},
+		{1234 * time.Millisecond, true, false, "+1.234"},
+		{-1500 * time.Microsecond, true, true, "-0.001500"},
+		{1500 * time.Microsecond, true, false, "+0.002"},
+		{0, true, false, "+0.000"},
+		{83*time.Second + 5*time.Millisecond, false, false, "1:23.005"},
+		{time.Hour + 2*time.Minute + 3*time.Second + 45*time.Microsecond, false, true, "1:02:03.000045"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d, tt.signed, tt.micro); got != tt.want {
+			t.Errorf("FormatDuration(%s, %v, %v) = %q; want %q", tt.d, tt.signed, tt.micro, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	at := time.Date(2024, 1, 2, 13, 14, 15, 123456789, time.Local)
+	ref := at.Add(-250 * time.Millisecond)
+
+	tests := []struct {
+		mode  TimeMode
+		ref   time.Time
+		micro bool
+		want  string
+	}{
+		{TimeAbsolute, ref, false, "13:14:15.123"},
+		{TimeAbsolute, ref, true, "13:14:15.123456"},
+		{TimeElapsed, ref, false, "0.250"},
+		{TimeDelta, ref, true, "+0.250000"},
+		{TimeMarker, at.Add(time.Second), false, "-1.000"},
+		{TimeDelta, time.Time{}, false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := FormatTime(tt.mode, at, tt.ref, tt.micro); got != tt.want {
+			t.Errorf("FormatTime(%s, micro %v) = %q; want %q", tt.mode, tt.micro, got, tt.want)
+		}
+	}
+}
+
+func TestCycleTimeMode(t *testing.T) {
+	f := NewFilter()
+	for _, want := range []TimeMode{TimeElapsed, TimeDelta, TimeMarker, TimeAbsolute} {
+		f.CycleTimeMode()
+		if f.TimeMode != want {
+			t.Fatalf("CycleTimeMode() = %s; want %s", f.TimeMode, want)
+		}
+	}
+}
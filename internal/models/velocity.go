@@ -0,0 +1,205 @@
+package models
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"midi-viewer/internal/midi"
+)
+
+// A key produces the full velocity range if it has been played at or below FullRangeLow
+// and at or above FullRangeHigh
+const (
+	FullRangeLow  = 16
+	FullRangeHigh = 120
+)
+
+// OddKeyThreshold is how far a key's mean velocity may differ from its neighbourhood
+// before the key is reported as odd
+const OddKeyThreshold = 15.0
+
+// oddKeyNeighbours is how many keys either side of a key belong to its neighbourhood
+const oddKeyNeighbours = 2
+
+// VelocityCounts counts Note On velocities, indexed by velocity
+type VelocityCounts [128]int
+
+// Buckets sums the counts into n buckets of equal width covering velocities 1-127
+func (c VelocityCounts) Buckets(n int) []int {
+	if n < 1 {
+		return nil
+	}
+	buckets := make([]int, n)
+	for vel := 1; vel < 128; vel++ {
+		buckets[(vel-1)*n/127] += c[vel]
+	}
+	return buckets
+}
+
+// BucketRange returns the lowest and highest velocity in bucket i of n
+func BucketRange(i, n int) (from, to int) {
+	// Velocity v is in bucket (v-1)*n/127, so bucket i starts at the first v with (v-1)*n >= i*127
+	from = (i*127+n-1)/n + 1
+	to = ((i+1)*127 + n - 1) / n
+	return from, to
+}
+
+// KeyVelocity holds the velocities a key has produced on a channel
+type KeyVelocity struct {
+	Channel uint8
+	Key     uint8
+	Counts  VelocityCounts
+	Notes   int
+	Min     uint8
+	Max     uint8
+	sum     int
+}
+
+// Mean returns the average velocity, or 0 if the key has not been played
+func (k KeyVelocity) Mean() float64 {
+	if k.Notes == 0 {
+		return 0
+	}
+	return float64(k.sum) / float64(k.Notes)
+}
+
+// FullRange reports whether the key has produced both soft and hard velocities
+func (k KeyVelocity) FullRange() bool {
+	return k.Notes > 0 && k.Min <= FullRangeLow && k.Max >= FullRangeHigh
+}
+
+// VelocityStats collects Note On velocities per key and per channel for keybed calibration
+type VelocityStats struct {
+	keys     map[[2]uint8]*KeyVelocity // channel, key -> velocities
+	channels map[uint8]*VelocityCounts
+}
+
+// NewVelocityStats creates empty velocity statistics
+func NewVelocityStats() VelocityStats {
+	return VelocityStats{
+		keys:     make(map[[2]uint8]*KeyVelocity),
+		channels: make(map[uint8]*VelocityCounts),
+	}
+}
+
+// Track counts the velocity of a Note On
+func (v *VelocityStats) Track(event midi.Event) {
+	var ch, key, vel uint8
+	if !event.Message.GetNoteStart(&ch, &key, &vel) {
+		return
+	}
+
+	k := v.keys[[2]uint8{ch, key}]
+	if k == nil {
+		k = &KeyVelocity{Channel: ch, Key: key, Min: vel, Max: vel}
+		v.keys[[2]uint8{ch, key}] = k
+	}
+	k.Counts[vel]++
+	k.Notes++
+	k.sum += int(vel)
+	k.Min = min(k.Min, vel)
+	k.Max = max(k.Max, vel)
+
+	counts := v.channels[ch]
+	if counts == nil {
+		counts = &VelocityCounts{}
+		v.channels[ch] = counts
+	}
+	counts[vel]++
+}
+
+// Reset forgets all velocities
+func (v *VelocityStats) Reset() {
+	*v = NewVelocityStats()
+}
+
+// Channels returns the channels Note Ons have been received on, in order
+func (v VelocityStats) Channels() []uint8 {
+	channels := make([]uint8, 0, len(v.channels))
+	for ch := range v.channels {
+		channels = append(channels, ch)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i] < channels[j] })
+	return channels
+}
+
+// ChannelCounts returns the velocities counted on a channel
+func (v VelocityStats) ChannelCounts(ch uint8) VelocityCounts {
+	if counts := v.channels[ch]; counts != nil {
+		return *counts
+	}
+	return VelocityCounts{}
+}
+
+// KeyReport is one key's line in the calibration report
+type KeyReport struct {
+	KeyVelocity
+	Deviation float64 // mean velocity minus the median of its neighbourhood (0 if no neighbour was played)
+	Odd       bool    // Deviation exceeds OddKeyThreshold
+}
+
+// Report returns the played keys ordered by channel and key. Each key's mean velocity is
+// compared with the median of the played keys up to two semitones either side of it on the
+// same channel, including itself; the median keeps one odd key from making its neighbours
+// look odd too.
+func (v VelocityStats) Report() []KeyReport {
+	report := make([]KeyReport, 0, len(v.keys))
+	for _, k := range v.keys {
+		r := KeyReport{KeyVelocity: *k}
+
+		var means []float64
+		for offset := -oddKeyNeighbours; offset <= oddKeyNeighbours; offset++ {
+			key := int(k.Key) + offset
+			if key < 0 || key > 127 {
+				continue
+			}
+			if n := v.keys[[2]uint8{k.Channel, uint8(key)}]; n != nil {
+				means = append(means, n.Mean())
+			}
+		}
+		if len(means) > 1 {
+			sort.Float64s(means)
+			median := means[len(means)/2]
+			if len(means)%2 == 0 {
+				median = (means[len(means)/2-1] + median) / 2
+			}
+			r.Deviation = k.Mean() - median
+			r.Odd = math.Abs(r.Deviation) > OddKeyThreshold
+		}
+
+		report = append(report, r)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Channel != report[j].Channel {
+			return report[i].Channel < report[j].Channel
+		}
+		return report[i].Key < report[j].Key
+	})
+	return report
+}
+
+// WriteVelocityCSV writes a calibration report as CSV, one row per key
+func WriteVelocityCSV(w io.Writer, report []KeyReport) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"channel", "key", "note", "notes", "min", "max", "mean", "full_range", "deviation", "odd"})
+	for _, r := range report {
+		out.Write([]string{
+			fmt.Sprintf("%d", r.Channel+1),
+			fmt.Sprintf("%d", r.Key),
+			midi.NoteToName(r.Key),
+			fmt.Sprintf("%d", r.Notes),
+			fmt.Sprintf("%d", r.Min),
+			fmt.Sprintf("%d", r.Max),
+			fmt.Sprintf("%.1f", r.Mean()),
+			fmt.Sprintf("%t", r.FullRange()),
+			fmt.Sprintf("%.1f", r.Deviation),
+			fmt.Sprintf("%t", r.Odd),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
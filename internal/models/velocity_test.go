@@ -0,0 +1,115 @@
+package models
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+)
+
+func TestVelocityStatsPerKey(t *testing.T) {
+	v := NewVelocityStats()
+	now := time.Now()
+
+	for _, vel := range []uint8{10, 64, 125} {
+		v.Track(noteEvent(gomidi.NoteOn(0, 60, vel), now))
+	}
+	v.Track(noteEvent(gomidi.NoteOn(0, 62, 50), now))
+	v.Track(noteEvent(gomidi.NoteOn(0, 62, 0), now)) // a release, not a velocity
+	v.Track(noteEvent(gomidi.NoteOff(0, 62), now))
+	v.Track(noteEvent(gomidi.NoteOn(9, 36, 100), now))
+
+	report := v.Report()
+	if len(report) != 3 {
+		t.Fatalf("Report() = %d keys; want 3", len(report))
+	}
+
+	c4 := report[0]
+	if c4.Key != 60 || c4.Notes != 3 || c4.Min != 10 || c4.Max != 125 || c4.Mean() != 199.0/3 {
+		t.Errorf("key 60 = %d notes, %d-%d, mean %.2f; want 3 notes, 10-125, mean 66.33", c4.Notes, c4.Min, c4.Max, c4.Mean())
+	}
+	if !c4.FullRange() {
+		t.Error("key 60 should have produced the full range")
+	}
+	if report[1].Key != 62 || report[1].Notes != 1 || report[1].FullRange() {
+		t.Errorf("key 62 = %+v; want 1 note without the full range", report[1].KeyVelocity)
+	}
+	if report[2].Channel != 9 {
+		t.Errorf("last key is on channel %d; want 9", report[2].Channel)
+	}
+
+	if got := v.Channels(); len(got) != 2 || got[0] != 0 || got[1] != 9 {
+		t.Errorf("Channels() = %v; want [0 9]", got)
+	}
+	buckets := v.ChannelCounts(0).Buckets(8)
+	if len(buckets) != 8 || buckets[0] != 1 || buckets[3] != 2 || buckets[7] != 1 {
+		t.Errorf("ChannelCounts(0).Buckets(8) = %v; want 10, 50/64 and 125 in buckets 0, 3 and 7", buckets)
+	}
+
+	for i := 0; i < 8; i++ {
+		from, to := BucketRange(i, 8)
+		for vel := from; vel <= to; vel++ {
+			if got := (vel - 1) * 8 / 127; got != i {
+				t.Errorf("BucketRange(%d, 8) = %d-%d, but velocity %d is in bucket %d", i, from, to, vel, got)
+			}
+		}
+	}
+	if from, to := BucketRange(7, 8); from != 113 || to != 127 {
+		t.Errorf("BucketRange(7, 8) = %d-%d; want 113-127", from, to)
+	}
+
+	v.Reset()
+	if len(v.Report()) != 0 || len(v.Channels()) != 0 {
+		t.Error("Reset() should forget all velocities")
+	}
+}
+
+func TestVelocityStatsOddKeys(t *testing.T) {
+	v := NewVelocityStats()
+	now := time.Now()
+
+	for key := uint8(60); key <= 64; key++ {
+		vel := uint8(80)
+		if key == 62 {
+			vel = 40 // a weak key
+		}
+		v.Track(noteEvent(gomidi.NoteOn(0, key, vel), now))
+	}
+	v.Track(noteEvent(gomidi.NoteOn(0, 90, 20), now)) // no neighbours to compare with
+
+	for _, r := range v.Report() {
+		wantOdd := r.Key == 62
+		if r.Odd != wantOdd {
+			t.Errorf("key %d Odd = %v (deviation %.1f); want %v", r.Key, r.Odd, r.Deviation, wantOdd)
+		}
+		if r.Key == 62 && r.Deviation != -40 {
+			t.Errorf("key 62 Deviation = %.1f; want -40", r.Deviation)
+		}
+		if r.Key == 90 && r.Deviation != 0 {
+			t.Errorf("key 90 Deviation = %.1f; want 0", r.Deviation)
+		}
+	}
+}
+
+func TestWriteVelocityCSV(t *testing.T) {
+	v := NewVelocityStats()
+	v.Track(noteEvent(gomidi.NoteOn(0, 60, 100), time.Now()))
+
+	var buf bytes.Buffer
+	if err := WriteVelocityCSV(&buf, v.Report()); err != nil {
+		t.Fatalf("WriteVelocityCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("WriteVelocityCSV() wrote %d lines; want header and 1 key", len(lines))
+	}
+	if lines[0] != "channel,key,note,notes,min,max,mean,full_range,deviation,odd" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if lines[1] != "1,60,C4,1,100,100,100.0,false,0.0,false" {
+		t.Errorf("row = %q", lines[1])
+	}
+}
@@ -0,0 +1,139 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/midi"
+)
+
+// dashboardColumnWidth is the width of one column of meters in the dashboard
+const dashboardColumnWidth = 60
+
+// renderDashboard renders the last-seen controller values as meters, filling exactly height lines
+func (e EventViewer) renderDashboard(height int) string {
+	channelStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Primary).
+		Bold(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Foreground)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Muted)
+
+	meterStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Success)
+
+	labelWidth := 26
+	valueWidth := 6
+	meterWidth := dashboardColumnWidth - labelWidth - valueWidth - 4
+
+	meterLine := func(label, meter, value string) string {
+		return "  " + labelStyle.Width(labelWidth).Render(label) +
+			meterStyle.Render(meter) + " " +
+			labelStyle.Width(valueWidth).Align(lipgloss.Right).Render(value)
+	}
+
+	// Build one block of lines per channel, skipping controllers that were never used
+	var lines []string
+	for _, ch := range e.controllers.Channels() {
+		if !e.filter.IsChannelVisible(ch) {
+			continue
+		}
+
+		lines = append(lines, channelStyle.Render(fmt.Sprintf("Ch %d", ch+1)))
+
+		for _, controller := range e.controllers.Controllers(ch) {
+			value, _ := e.controllers.Value(ch, controller)
+			name := e.instruments.ControllerName(ch, controller)
+			if name == "" {
+				name = midi.ControllerName(controller)
+			}
+			label := fmt.Sprintf("CC %3d %s", controller, name)
+			lines = append(lines, meterLine(label, renderMeter(int(value), 127, meterWidth), fmt.Sprintf("%d", value)))
+		}
+
+		if bend, ok := e.controllers.PitchBend(ch); ok {
+			lines = append(lines, meterLine("Pitch Bend", renderBipolarMeter(int(bend), 8192, meterWidth), fmt.Sprintf("%d", bend)))
+		}
+
+		if pressure, ok := e.controllers.Aftertouch(ch); ok {
+			lines = append(lines, meterLine("Aftertouch", renderMeter(int(pressure), 127, meterWidth), fmt.Sprintf("%d", pressure)))
+		}
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, mutedStyle.Render("  No controller, pitch bend or aftertouch data yet"))
+	}
+
+	// Lay the lines out in as many columns as the terminal width allows
+	numCols := e.width / dashboardColumnWidth
+	if numCols < 1 {
+		numCols = 1
+	}
+	if height < 1 {
+		return ""
+	}
+
+	capacity := numCols * height
+	if len(lines) > capacity {
+		hidden := len(lines) - capacity + 1
+		lines = append(lines[:capacity-1], mutedStyle.Render(fmt.Sprintf("  ... %d more", hidden)))
+	}
+
+	var cols []string
+	for start := 0; start < len(lines); start += height {
+		end := start + height
+		if end > len(lines) {
+			end = len(lines)
+		}
+		col := strings.Join(lines[start:end], "\n")
+		cols = append(cols, lipgloss.NewStyle().Width(dashboardColumnWidth).Height(height).Render(col))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, cols...) + "\n"
+}
+
+// renderMeter renders value in [0, max] as a horizontal bar of the given width
+func renderMeter(value, max, width int) string {
+	if width < 1 {
+		return ""
+	}
+
+	filled := value * width / max
+	if filled > width {
+		filled = width
+	}
+	if filled < 0 {
+		filled = 0
+	}
+
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// renderBipolarMeter renders value in [-limit, limit) as a bar growing out from the centre
+func renderBipolarMeter(value, limit, width int) string {
+	if width < 3 {
+		return ""
+	}
+
+	half := width / 2
+	cells := []rune(strings.Repeat("░", width))
+	cells[half] = '│'
+
+	n := value * half / limit
+	switch {
+	case n > 0:
+		for i := half + 1; i <= half+n && i < width; i++ {
+			cells[i] = '█'
+		}
+	case n < 0:
+		for i := half - 1; i >= half+n && i >= 0; i-- {
+			cells[i] = '█'
+		}
+	}
+
+	return string(cells)
+}
@@ -12,10 +12,13 @@ import (
 )
 
 type deviceSelectorKeyMap struct {
-	Up     key.Binding
-	Down   key.Binding
-	Select key.Binding
-	Quit   key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Select   key.Binding
+	Identify key.Binding
+	Serial   key.Binding
+	Baud     key.Binding
+	Quit     key.Binding
 }
 
 var deviceSelectorKeys = deviceSelectorKeyMap{
@@ -31,6 +34,18 @@ var deviceSelectorKeys = deviceSelectorKeyMap{
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "select"),
 	),
+	Identify: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "identify"),
+	),
+	Serial: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add serial port"),
+	),
+	Baud: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "serial baud rate"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c", "esc"),
 		key.WithHelp("q/esc", "quit"),
@@ -45,25 +60,35 @@ type DeviceSelector struct {
 	width        int
 	height       int
 	err          error
+
+	identifying map[int]bool  // device numbers with an identity probe in flight
+	identifyErr map[int]error // device numbers whose last probe failed
+
+	prompt    textPrompt
+	prompting bool   // the add serial port prompt is shown
+	status    string // result of the last action, shown above the help
 }
 
 // NewDeviceSelector creates a new device selector
 func NewDeviceSelector(t theme.Theme) DeviceSelector {
 	return DeviceSelector{
-		theme:   t,
-		cursor:  0,
+		theme:       t,
+		cursor:      0,
+		identifying: make(map[int]bool),
+		identifyErr: make(map[int]error),
 	}
 }
 
-// Init initializes the device selector
+// Init initializes the device selector and turns on mouse events
 func (d DeviceSelector) Init() tea.Cmd {
-	return func() tea.Msg {
+	return tea.Batch(func() tea.Msg {
 		devices, err := midi.GetInputDevices()
 		if err != nil {
 			return DeviceErrorMsg{err}
 		}
+		devices = append(devices, midi.SerialDevices(len(devices))...)
 		return DevicesLoadedMsg{devices}
-	}
+	}, tea.EnableMouseCellMotion)
 }
 
 // Update handles messages
@@ -76,13 +101,37 @@ func (d DeviceSelector) Update(msg tea.Msg) (DeviceSelector, tea.Cmd) {
 	case DevicesLoadedMsg:
 		d.devices = msg.Devices
 		if len(d.devices) == 0 {
-			d.err = fmt.Errorf("no MIDI input devices found")
+			d.err = fmt.Errorf("no MIDI input devices found (press a to add a serial port)")
 		}
 
 	case DeviceErrorMsg:
 		d.err = msg.Err
 
+	case DeviceIdentifiedMsg:
+		delete(d.identifying, msg.Number)
+		delete(d.identifyErr, msg.Number)
+		for i := range d.devices {
+			if d.devices[i].Number != msg.Number {
+				continue
+			}
+			if msg.Err != nil {
+				d.identifyErr[msg.Number] = msg.Err
+			} else {
+				reply := msg.Reply
+				d.devices[i].Identity = &reply
+			}
+		}
+
+	case tea.MouseMsg:
+		if !d.prompting {
+			return d.updateMouse(msg)
+		}
+
 	case tea.KeyMsg:
+		if d.prompting {
+			return d.updatePrompt(msg)
+		}
+
 		switch {
 		case key.Matches(msg, deviceSelectorKeys.Quit):
 			return d, tea.Quit
@@ -100,14 +149,112 @@ func (d DeviceSelector) Update(msg tea.Msg) (DeviceSelector, tea.Cmd) {
 					return DeviceSelectedMsg{d.devices[d.cursor]}
 				}
 			}
+		case key.Matches(msg, deviceSelectorKeys.Identify):
+			if len(d.devices) > 0 && !d.identifying[d.devices[d.cursor].Number] {
+				device := d.devices[d.cursor]
+				d.identifying[device.Number] = true
+				return d, identifyDevice(device)
+			}
+		case key.Matches(msg, deviceSelectorKeys.Serial):
+			path := "/dev/ttyUSB0"
+			if ports := midi.SerialPorts(); len(ports) > 0 {
+				path = ports[0]
+			}
+			d.prompt = newTextPrompt("Serial port (path[:baud])", fmt.Sprintf("%s:%d", path, midi.DefaultSerialBaud))
+			d.prompting = true
+			d.status = ""
+		case key.Matches(msg, deviceSelectorKeys.Baud):
+			if len(d.devices) > 0 {
+				device := d.devices[d.cursor]
+				if serial, ok := device.Port.(*midi.SerialIn); ok {
+					d.devices[d.cursor] = midi.NewSerialDevice(serial.Path(), midi.NextSerialBaud(serial.Baud()), device.Number)
+				}
+			}
 		}
 	}
 
 	return d, nil
 }
 
+// updatePrompt handles keys while the add serial port prompt is shown
+func (d DeviceSelector) updatePrompt(msg tea.KeyMsg) (DeviceSelector, tea.Cmd) {
+	var result promptResult
+	d.prompt, result = d.prompt.update(msg)
+	if result == promptEditing {
+		return d, nil
+	}
+
+	d.prompting = false
+	if result == promptCancelled {
+		return d, nil
+	}
+
+	path, baud, err := midi.ParseSerialSpec(d.prompt.Value())
+	if err != nil {
+		d.status = err.Error()
+		return d, nil
+	}
+
+	number := 0
+	for _, device := range d.devices {
+		if device.Number >= number {
+			number = device.Number + 1
+		}
+	}
+	d.devices = append(d.devices, midi.NewSerialDevice(path, baud, number))
+	d.cursor = len(d.devices) - 1
+	d.err = nil
+	d.status = ""
+
+	return d, nil
+}
+
+// deviceListTop is the row of the first device: below the title and its padding
+const deviceListTop = 4
+
+// updateMouse handles the mouse: clicking a device moves the cursor to it and clicking the
+// device under the cursor selects it; the wheel moves the cursor
+func (d DeviceSelector) updateMouse(msg tea.MouseMsg) (DeviceSelector, tea.Cmd) {
+	if len(d.devices) == 0 {
+		return d, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		d.cursor = max(d.cursor-1, 0)
+	case msg.Button == tea.MouseButtonWheelDown:
+		d.cursor = min(d.cursor+1, len(d.devices)-1)
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		i := msg.Y - deviceListTop
+		if i < 0 || i >= len(d.devices) {
+			return d, nil
+		}
+		if i != d.cursor {
+			d.cursor = i
+			return d, nil
+		}
+		return d, func() tea.Msg {
+			return DeviceSelectedMsg{d.devices[i]}
+		}
+	}
+
+	return d, nil
+}
+
+// identifyDevice probes a device with a Universal Identity Request
+func identifyDevice(device midi.Device) tea.Cmd {
+	return func() tea.Msg {
+		reply, err := midi.Identify(device, midi.IdentifyTimeout)
+		return DeviceIdentifiedMsg{Number: device.Number, Reply: reply, Err: err}
+	}
+}
+
 // View renders the device selector
 func (d DeviceSelector) View() string {
+	if d.prompting || (d.status != "" && len(d.devices) == 0) {
+		return d.renderDeviceList()
+	}
+
 	if d.err != nil {
 		return d.renderError()
 	}
@@ -180,6 +327,14 @@ func (d DeviceSelector) renderDeviceList() string {
 		}
 
 		line := fmt.Sprintf("%s%d. %s", cursor, i+1, device.Name)
+		switch {
+		case d.identifying[device.Number]:
+			line += "  (identifying...)"
+		case d.identifyErr[device.Number] != nil:
+			line += fmt.Sprintf("  (%v)", d.identifyErr[device.Number])
+		case device.Identity != nil:
+			line += "  " + device.Identity.String()
+		}
 
 		if i == d.cursor {
 			b.WriteString(selectedStyle.Render(line))
@@ -190,7 +345,15 @@ func (d DeviceSelector) renderDeviceList() string {
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("↑/↓: navigate • enter: select • q/esc: quit"))
+	if d.status != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(d.theme.Error).Render(d.status))
+		b.WriteString("\n")
+	}
+	if d.prompting {
+		b.WriteString(helpStyle.Render(d.prompt.View()))
+	} else {
+		b.WriteString(helpStyle.Render("↑/↓: navigate • enter/click: select • i: identify • a: add serial • b: baud • q/esc: quit"))
+	}
 
 	return b.String()
 }
@@ -205,6 +368,13 @@ type DeviceSelectedMsg struct {
 	Device midi.Device
 }
 
+// DeviceIdentifiedMsg is sent when an identity probe of a device finishes
+type DeviceIdentifiedMsg struct {
+	Number int
+	Reply  midi.IdentityReply
+	Err    error
+}
+
 // DeviceErrorMsg is sent when there's an error loading or selecting devices
 type DeviceErrorMsg struct {
 	Err error
@@ -0,0 +1,249 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/models"
+)
+
+type diffKeyMap struct {
+	Load      key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	PageUp    key.Binding
+	PageDown  key.Binding
+	Next      key.Binding
+	Previous  key.Binding
+	Tolerance key.Binding
+}
+
+var diffKeys = diffKeyMap{
+	Load: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "load captures"),
+	),
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "scroll up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "scroll down"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdn", "page down"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next difference"),
+	),
+	Previous: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous difference"),
+	),
+	Tolerance: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "timing tolerance"),
+	),
+}
+
+// diffPage is how many lines page up and page down move
+const diffPage = 10
+
+// updateDiff handles capture diff keys; handled is false for keys it does not use
+func (e EventViewer) updateDiff(msg tea.KeyMsg) (EventViewer, tea.Cmd, bool) {
+	last := max(len(e.diff.Lines)-1, 0)
+
+	switch {
+	case key.Matches(msg, diffKeys.Load):
+		e.startPrompt(promptDiffFirst, "Compare capture", e.diffFirst)
+	case key.Matches(msg, diffKeys.Up):
+		e.diffScroll = max(e.diffScroll-1, 0)
+	case key.Matches(msg, diffKeys.Down):
+		e.diffScroll = min(e.diffScroll+1, last)
+	case key.Matches(msg, diffKeys.PageUp):
+		e.diffScroll = max(e.diffScroll-diffPage, 0)
+	case key.Matches(msg, diffKeys.PageDown):
+		e.diffScroll = min(e.diffScroll+diffPage, last)
+	case key.Matches(msg, diffKeys.Next), key.Matches(msg, diffKeys.Previous):
+		if i := e.diff.NextDifference(e.diffScroll, key.Matches(msg, diffKeys.Previous)); i >= 0 {
+			e.diffScroll = i
+		} else {
+			e.status = "No more differences"
+		}
+	case key.Matches(msg, diffKeys.Tolerance):
+		if e.diff.Lines != nil {
+			e.diff.CycleTolerance()
+		}
+	default:
+		return e, nil, false
+	}
+
+	return e, nil, true
+}
+
+// loadDiff reads the captures to compare. Without a second path the first capture is
+// compared with current, the events received by the viewer.
+func loadDiff(pathA, pathB string, current []midi.Event, currentName string) tea.Cmd {
+	current = append([]midi.Event(nil), current...)
+	return func() tea.Msg {
+		a, err := models.LoadCapture(pathA)
+		if err != nil {
+			return DiffLoadedMsg{Err: err}
+		}
+
+		b, nameB := current, currentName
+		if pathB != "" {
+			if b, err = models.LoadCapture(pathB); err != nil {
+				return DiffLoadedMsg{Err: err}
+			}
+			nameB = filepath.Base(pathB)
+		}
+		return DiffLoadedMsg{Diff: models.NewCaptureDiff(filepath.Base(pathA), a, nameB, b)}
+	}
+}
+
+// diffSummary describes the result of a diff for the status line
+func diffSummary(d models.CaptureDiff) string {
+	if d.Identical() {
+		return fmt.Sprintf("%s and %s match: %d events", d.NameA, d.NameB, d.Same)
+	}
+	return fmt.Sprintf("%s vs %s: %d changed, %d missing, %d inserted, %d late", d.NameA, d.NameB, d.Changed, d.Missing, d.Inserted, d.Late)
+}
+
+// renderDiff renders two captures side by side, aligned message by message, filling exactly
+// height lines. Both panes scroll together as each line holds the matching events of both.
+func (e EventViewer) renderDiff(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Secondary).
+		Bold(true).
+		Underline(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Foreground)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Primary).
+		Bold(true)
+
+	changedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Warning)
+
+	missingStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Error)
+
+	insertedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Success)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Muted)
+
+	d := e.diff
+	var lines []string
+	tolerance := "timing ignored"
+	if d.Untimed {
+		tolerance = "timing not compared: a capture has no timing"
+	} else if d.Tolerance > 0 {
+		tolerance = fmt.Sprintf("timing tolerance %s", d.Tolerance)
+	}
+	lines = append(lines, titleStyle.Render("Capture Diff")+"  "+mutedStyle.Render(tolerance))
+
+	if d.NameA == "" {
+		lines = append(lines, mutedStyle.Render("  Press f to compare a capture (.mid, .syx, .jsonl or a hex dump) with another one or with"))
+		lines = append(lines, mutedStyle.Render("  the events received here. Events are aligned by their bytes, so only missing, inserted and"))
+		lines = append(lines, mutedStyle.Render("  changed messages and timing beyond the tolerance show as differences."))
+		content := "  " + strings.Join(lines, "\n  ")
+		return lipgloss.NewStyle().Height(height).Render(content) + "\n"
+	}
+
+	lines = append(lines, labelStyle.Render("  A: ")+valueStyle.Render(d.NameA)+labelStyle.Render(fmt.Sprintf(" (%d events)", len(d.A)))+
+		labelStyle.Render("  B: ")+valueStyle.Render(d.NameB)+labelStyle.Render(fmt.Sprintf(" (%d events)", len(d.B))))
+	if d.Identical() {
+		lines = append(lines, insertedStyle.Render(fmt.Sprintf("  Identical: %d events match", d.Same)))
+	} else {
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("  %d same  ", d.Same))+
+			changedStyle.Render(fmt.Sprintf("%d changed  ", d.Changed))+
+			missingStyle.Render(fmt.Sprintf("%d missing  ", d.Missing))+
+			insertedStyle.Render(fmt.Sprintf("%d inserted  ", d.Inserted))+
+			changedStyle.Render(fmt.Sprintf("%d late", d.Late)))
+	}
+	if d.Positional {
+		lines = append(lines, changedStyle.Render("  Too different to align: events between the common start and end are paired by position"))
+	}
+	lines = append(lines, "")
+
+	paneWidth := max((e.width-4-3)/2, 20)
+	headingA := fitWidth("A: "+d.NameA, paneWidth)
+	lines = append(lines, titleStyle.Render(headingA)+strings.Repeat(" ", paneWidth-lipgloss.Width(headingA)+3)+
+		titleStyle.Render(fitWidth("B: "+d.NameB, paneWidth)))
+
+	for i := e.diffScroll; i < len(d.Lines) && len(lines) < height; i++ {
+		line := d.Lines[i]
+		left, right := "", ""
+		if line.A >= 0 {
+			left = diffEventText(d.A, line.A)
+		}
+		if line.B >= 0 {
+			right = diffEventText(d.B, line.B)
+			if line.Late {
+				right = fmt.Sprintf("%s %s", models.FormatDuration(line.Delta, true, false), right)
+			}
+		}
+
+		gutter, style := " = ", mutedStyle
+		switch line.Kind {
+		case models.DiffChanged:
+			gutter, style = " ~ ", changedStyle
+		case models.DiffMissing:
+			gutter, style = " - ", missingStyle
+		case models.DiffInserted:
+			gutter, style = " + ", insertedStyle
+		}
+		rightStyle := style
+		if line.Late {
+			gutter = " Δ "
+			if line.Kind == models.DiffSame {
+				rightStyle = changedStyle
+			}
+		}
+
+		left = fitWidth(left, paneWidth)
+		lines = append(lines, style.Render(left)+strings.Repeat(" ", paneWidth-lipgloss.Width(left))+
+			style.Render(gutter)+rightStyle.Render(fitWidth(right, paneWidth)))
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	content := "  " + strings.Join(lines, "\n  ")
+	return lipgloss.NewStyle().Height(height).Render(content) + "\n"
+}
+
+// diffEventText renders event i of a compared capture as its offset from the first event,
+// type and data
+func diffEventText(events []midi.Event, i int) string {
+	offset := events[i].Timestamp.Sub(events[0].Timestamp)
+	return fmt.Sprintf("%10s  %-16s %s", models.FormatDuration(offset, false, false), events[i].MessageType, events[i].Data)
+}
+
+// DiffLoadedMsg is sent when the captures to compare have been read
+type DiffLoadedMsg struct {
+	Diff models.CaptureDiff
+	Err  error
+}
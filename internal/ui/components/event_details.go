@@ -0,0 +1,109 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/models"
+)
+
+// renderEventDetails renders every field of the selected event, as exported, filling exactly
+// height lines
+func (e EventViewer) renderEventDetails(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Foreground(e.theme.Secondary).Bold(true).Underline(true)
+	labelStyle := lipgloss.NewStyle().Foreground(e.theme.Muted).Width(16)
+	valueStyle := lipgloss.NewStyle().Foreground(e.theme.Foreground)
+
+	i := e.selected()
+	if i < 0 {
+		return lipgloss.NewStyle().Height(height).Render("  No event selected") + "\n"
+	}
+	event := e.events[i]
+	r := models.NewEventRecord(event)
+
+	optional := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d", *v)
+	}
+	highRes := ""
+	if r.HighRes != nil {
+		highRes = fmt.Sprintf("%d (%d-bit)", *r.HighRes, r.HighResBits)
+	}
+	var flags []string
+	if r.RunningStatus {
+		flags = append(flags, "running status")
+	}
+	if r.Sent {
+		flags = append(flags, "sent by a replay")
+	}
+	if r.Bookmarked {
+		flags = append(flags, "bookmarked")
+	}
+
+	fields := []struct{ label, value string }{
+		{"Type", r.Type},
+		{"Data", r.Data},
+		{"Time", r.Time},
+		{"Arrived", r.Arrived},
+		{"Processed", r.Processed},
+		{"Channel", optional(r.Channel)},
+		{"Group", optional(r.Group)},
+		{"Note", strings.TrimSpace(optional(r.Note) + " " + r.NoteName)},
+		{"Velocity", optional(r.Velocity)},
+		{"Controller", optional(r.Controller)},
+		{"Value", optional(r.Value)},
+		{"Program", optional(r.Program)},
+		{"Pressure", optional(r.Pressure)},
+		{"Pitch bend", optional(r.Bend)},
+		{"High resolution", highRes},
+		{"Note label", r.NoteLabel},
+		{"Control label", r.ControlLabel},
+		{"Program label", r.ProgramLabel},
+		{"Flags", strings.Join(flags, ", ")},
+		{"UMP packet", r.Packet},
+		{"Raw", r.Raw},
+	}
+
+	lines := []string{titleStyle.Render(fmt.Sprintf("Event %d of %d", i+1, len(e.events)))}
+	valueWidth := max(e.width-2-16, 8)
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		// Long values, such as the raw bytes of a SysEx dump, wrap under their label
+		for j, part := range wrapText(field.value, valueWidth) {
+			label := ""
+			if j == 0 {
+				label = field.label
+			}
+			lines = append(lines, labelStyle.Render(label)+valueStyle.Render(part))
+		}
+	}
+	if len(lines) > height {
+		lines = append(lines[:height-1], labelStyle.Render("…"))
+	}
+
+	return lipgloss.NewStyle().Height(height).Render("  "+strings.Join(lines, "\n  ")) + "\n"
+}
+
+// wrapText breaks s into lines of at most width characters, at spaces where it can
+func wrapText(s string, width int) []string {
+	var lines []string
+	for len([]rune(s)) > width {
+		runes := []rune(s)
+		cut := strings.LastIndex(string(runes[:width+1]), " ")
+		if cut <= 0 {
+			cut = len(string(runes[:width]))
+		}
+		lines = append(lines, strings.TrimRight(s[:cut], " "))
+		s = strings.TrimLeft(s[cut:], " ")
+	}
+	return append(lines, s)
+}
@@ -3,22 +3,46 @@ package components
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	gomidi "gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+	"midi-viewer/internal/instruments"
 	"midi-viewer/internal/midi"
 	"midi-viewer/internal/models"
 	"midi-viewer/internal/ui/theme"
 )
 
 type eventViewerKeyMap struct {
-	Pause   key.Binding
-	Options key.Binding
-	Clear   key.Binding
-	Back    key.Binding
-	Quit    key.Binding
+	Pause     key.Binding
+	Options   key.Binding
+	Clear     key.Binding
+	Panic     key.Binding
+	Dashboard key.Binding
+	Stats     key.Binding
+	Librarian key.Binding
+	Marker    key.Binding
+	Annotate  key.Binding
+	Latency   key.Binding
+	Velocity  key.Binding
+	Graph     key.Binding
+	Replay    key.Binding
+	Diff      key.Binding
+	Split     key.Binding
+	Export    key.Binding
+	ExportAll key.Binding
+	Back      key.Binding
+	Quit      key.Binding
+}
+
+type panicKeyMap struct {
+	AllNotesOff key.Binding
+	ResetCCs    key.Binding
+	NoteOffs    key.Binding
+	Cancel      key.Binding
 }
 
 var eventViewerKeys = eventViewerKeyMap{
@@ -34,6 +58,62 @@ var eventViewerKeys = eventViewerKeyMap{
 		key.WithKeys("c"),
 		key.WithHelp("c", "clear events"),
 	),
+	Panic: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "panic"),
+	),
+	Dashboard: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "controller dashboard"),
+	),
+	Stats: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "statistics"),
+	),
+	Librarian: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "sysex librarian"),
+	),
+	Marker: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "set time marker"),
+	),
+	Annotate: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "add named marker"),
+	),
+	Latency: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "latency test"),
+	),
+	Velocity: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "velocity analysis"),
+	),
+	Graph: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "controller graph"),
+	),
+	Replay: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "replay"),
+	),
+	Diff: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "compare captures"),
+	),
+	Split: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "split view"),
+	),
+	Export: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "export shown events"),
+	),
+	ExportAll: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "export all events"),
+	),
 	Back: key.NewBinding(
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "back to devices"),
@@ -44,9 +124,57 @@ var eventViewerKeys = eventViewerKeyMap{
 	),
 }
 
+var panicKeys = panicKeyMap{
+	AllNotesOff: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "all notes off"),
+	),
+	ResetCCs: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reset all controllers"),
+	),
+	NoteOffs: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "note off for held notes"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc", "p"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+// viewMode selects what the main area of the event viewer shows
+type viewMode int
+
+const (
+	viewEvents viewMode = iota
+	viewDashboard
+	viewStats
+	viewLibrarian
+	viewLatency
+	viewVelocity
+	viewGraph
+	viewReplay
+	viewDiff
+	viewSplit
+)
+
+// stuckNoteRefreshInterval is how often held notes are re-checked against the stuck threshold
+const stuckNoteRefreshInterval = 500 * time.Millisecond
+
+// maxStuckNotesListed limits how many stuck notes are listed below the active notes
+const maxStuckNotesListed = 4
+
 // EventViewer displays MIDI events in a scrolling list
 type EventViewer struct {
 	events       []midi.Event
+	listCursor   int            // selected index into events; -1 follows the newest event
+	details      bool           // showing the selected event's details instead of the list
+	colWidths    map[string]int // event list column widths dragged with the mouse
+	dragColumn   string         // column whose border is being dragged
+	dragX        int
+	dragWidth    int
+	received     []midi.Event // every event received, before the filter, for unfiltered export
 	device       midi.Device
 	theme        theme.Theme
 	width        int
@@ -54,24 +182,121 @@ type EventViewer struct {
 	paused       bool
 	filter       models.Filter
 	maxEvents    int
-	activeNotes  map[uint8]map[uint8]bool // channel -> note -> active
+	notes        models.NoteTracker
+	controllers  models.ControllerState
+	stats        models.Stats
+	assembler    midi.Assembler
+	librarian    models.Librarian
+	mpe          models.MPEState
+	latency      models.LatencyTest
+	outputs      []drivers.Out // outputs the latency test can send probes to (nil until listed)
+	latencyOut   int
+	replay       models.Replay
+	replayOut    int           // index into outputs
+	replayCancel chan struct{} // closed when the replay stops, cancelling its scheduled send
+	diff         models.CaptureDiff
+	diffScroll   int
+	diffFirst    string // path of the first capture compared, to offer again
+	panes        []models.Pane
+	paneFocus    int
+	velocity     models.VelocityStats
+	velChannel   int // index into the channels with Note Ons
+	velScroll    int
+	graphSeries  []models.Series // series overlaid on the graph, in the order added
+	graphCursor  int
+	graphWindow  int // index into models.GraphWindows
+	dumpCursor   int
+	instruments  *instruments.Resolver
+	mode         viewMode
+	now          time.Time
+	captureStart time.Time // when capture started or was last cleared, for elapsed times
+	timeMarker   time.Time // reference for marker-relative times
+	panicPrompt  bool
+	status       string
+	prompt       textPrompt
+	prompting    promptAction
+	offline      bool // showing a capture file rather than a device; no MIDI driver is used
 }
 
 // NewEventViewer creates a new event viewer
 func NewEventViewer(device midi.Device, t theme.Theme) EventViewer {
 	return EventViewer{
-		device:      device,
-		theme:       t,
-		events:      make([]midi.Event, 0),
-		paused:      false,
-		filter:      models.NewFilter(),
-		maxEvents:   1000, // Keep last 1000 events
-		activeNotes: make(map[uint8]map[uint8]bool),
+		device:       device,
+		theme:        t,
+		events:       make([]midi.Event, 0),
+		received:     make([]midi.Event, 0),
+		listCursor:   -1,
+		colWidths:    make(map[string]int),
+		paused:       false,
+		filter:       models.NewFilter(),
+		maxEvents:    1000, // Keep last 1000 events
+		notes:        models.NewNoteTracker(),
+		controllers:  models.NewControllerState(),
+		stats:        models.NewStats(),
+		assembler:    midi.NewDeviceAssembler(device),
+		librarian:    models.NewLibrarian(),
+		mpe:          models.NewMPEState(),
+		latency:      models.NewLatencyTest(),
+		replay:       models.NewReplay(),
+		velocity:     models.NewVelocityStats(),
+		graphWindow:  defaultGraphWindow,
+		now:          time.Now(),
+		captureStart: time.Now(),
+	}
+}
+
+// NewOfflineViewer creates an event viewer showing a saved capture instead of a device.
+// It never touches the MIDI driver, so it works without any MIDI hardware or RtMidi;
+// features that send MIDI are unavailable.
+func NewOfflineViewer(name string, events []midi.Event, t theme.Theme) EventViewer {
+	e := NewEventViewer(midi.Device{Name: name}, t)
+	e.offline = true
+	e.maxEvents = max(e.maxEvents, len(events))
+	if len(events) > 0 {
+		e.captureStart = events[0].Timestamp
+		e.now = events[len(events)-1].Timestamp // notes still held at the end show as stuck from there
+	}
+	for _, event := range events {
+		e.receive(event)
 	}
+	e.status = fmt.Sprintf("Opened %s (%d events)", name, len(events))
+	return e
+}
+
+// Init starts the periodic refresh used for stuck-note detection, loads instrument definitions
+// and turns on mouse events
+func (e EventViewer) Init() tea.Cmd {
+	return tea.Batch(stuckNoteTick(), loadInstruments(e.device.Name), tea.EnableMouseCellMotion)
+}
+
+// loadInstruments loads the instrument definitions from the default directory and binds them to the device
+func loadInstruments(device string) tea.Cmd {
+	return func() tea.Msg {
+		dir, err := instruments.DefaultDir()
+		if err != nil {
+			return InstrumentsLoadedMsg{Err: err}
+		}
+
+		defs, err := instruments.LoadDir(dir)
+		if err != nil {
+			return InstrumentsLoadedMsg{Err: err}
+		}
+
+		return InstrumentsLoadedMsg{Resolver: instruments.NewResolver(defs, device)}
+	}
+}
+
+func stuckNoteTick() tea.Cmd {
+	return tea.Tick(stuckNoteRefreshInterval, func(t time.Time) tea.Msg {
+		return stuckNoteTickMsg{t}
+	})
 }
 
-// GetFilter returns the current filter
+// GetFilter returns the current filter: the focused pane's in a split view
 func (e EventViewer) GetFilter() models.Filter {
+	if e.mode == viewSplit && len(e.panes) > 0 {
+		return e.panes[e.paneFocus].Filter
+	}
 	return e.filter
 }
 
@@ -82,23 +307,192 @@ func (e EventViewer) Update(msg tea.Msg) (EventViewer, tea.Cmd) {
 		e.width = msg.Width
 		e.height = msg.Height
 
+	case stuckNoteTickMsg:
+		if !e.offline {
+			e.now = msg.Time
+		}
+		return e, stuckNoteTick()
+
+	case InstrumentsLoadedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Instrument definitions: %v", msg.Err)
+		} else {
+			e.instruments = msg.Resolver
+			if e.offline {
+				// A capture was loaded before the definitions, so name its events now
+				for i := range e.received {
+					e.received[i].Labels = e.instruments.Resolve(e.received[i])
+				}
+				e.refilter()
+			}
+			if names := msg.Resolver.Definitions(); len(names) > 0 {
+				e.status = fmt.Sprintf("Instruments: %s", strings.Join(names, ", "))
+			}
+		}
+
+	case PanicSentMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Panic failed: %v", msg.Err)
+		} else {
+			e.status = fmt.Sprintf("Sent %s", msg.Description)
+			for _, note := range msg.Released {
+				e.notes.Release(note.Channel, note.Key)
+			}
+		}
+
 	case MIDIEventMsg:
 		if !e.paused {
-			event := midi.ParseMessage(msg.Message)
+			now := time.Now()
+			timestamp, arrived := msg.Timestamp, msg.Arrived
+			if arrived.IsZero() {
+				arrived = now
+			}
+			if timestamp.IsZero() {
+				timestamp = arrived
+			}
+			for _, event := range e.assembler.Feed(msg.Message, timestamp) {
+				event.Arrived = arrived
+				event.Processed = now
+				e.receive(event)
+			}
+		}
 
-			// Track note on/off for active notes display
-			e.updateActiveNotes(event)
+	case latencyTickMsg:
+		return e.updateLatencyTick(msg)
 
-			if e.filter.ShouldShow(event) {
-				e.events = append(e.events, event)
-				// Keep only last maxEvents
-				if len(e.events) > e.maxEvents {
-					e.events = e.events[len(e.events)-e.maxEvents:]
-				}
+	case ProbeSentMsg:
+		e = e.updateProbeSent(msg)
+
+	case OutputsLoadedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Could not list MIDI outputs: %v", msg.Err)
+		}
+		e.outputs = msg.Outputs
+		e.latencyOut = 0
+		e.replayOut = 0
+		// Start with the output paired with this input, the usual loopback through a device's thru
+		for i, out := range e.outputs {
+			if e.device.Out != nil && out.String() == e.device.Out.String() {
+				e.latencyOut = i
+				e.replayOut = i
 			}
 		}
 
+	case ReplaySentMsg:
+		return e.updateReplaySent(msg)
+
+	case ReplayStoppedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("All Notes Off after replay failed: %v", msg.Err)
+		}
+
+	case CaptureLoadedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Load failed: %v", msg.Err)
+		} else {
+			e.replay.Load(msg.Name, msg.Events)
+			e.status = fmt.Sprintf("Loaded %s (%d events)", msg.Name, len(msg.Events))
+		}
+
+	case DiffLoadedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Compare failed: %v", msg.Err)
+		} else {
+			e.diff = msg.Diff
+			e.diff.Compare(e.filter)
+			e.diffScroll = 0
+			e.mode = viewDiff
+			e.status = diffSummary(e.diff)
+		}
+
+	case EventsExportedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Export failed: %v", msg.Err)
+		} else {
+			e.status = fmt.Sprintf("Exported %d events to %s (%s)", msg.Events, msg.Path, msg.Format)
+		}
+
+	case VelocityExportedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Export failed: %v", msg.Err)
+		} else {
+			e.status = fmt.Sprintf("Exported %d keys to %s", msg.Keys, msg.Path)
+		}
+
+	case SyxSavedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Save failed: %v", msg.Err)
+		} else {
+			e.status = fmt.Sprintf("Saved %s", msg.Path)
+		}
+
+	case SyxLoadedMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Load failed: %v", msg.Err)
+		} else {
+			e.librarian.Add(msg.Dump)
+			e.dumpCursor = len(e.librarian.Dumps) - 1
+			e.status = fmt.Sprintf("Loaded %s (%d messages)", msg.Dump.Name, len(msg.Dump.Messages))
+		}
+
+	case DumpSentMsg:
+		if msg.Err != nil {
+			e.status = fmt.Sprintf("Send failed: %v", msg.Err)
+		} else {
+			e.status = fmt.Sprintf("Sent %s (%d messages)", msg.Name, msg.Messages)
+		}
+
+	case tea.MouseMsg:
+		return e.updateMouse(msg)
+
 	case tea.KeyMsg:
+		if e.panicPrompt {
+			return e.updatePanicPrompt(msg)
+		}
+		if e.prompting != promptNone {
+			return e.updatePrompt(msg)
+		}
+		if e.mode == viewLibrarian {
+			if viewer, cmd, handled := e.updateLibrarian(msg); handled {
+				return viewer, cmd
+			}
+		}
+		if e.mode == viewLatency {
+			if viewer, cmd, handled := e.updateLatency(msg); handled {
+				return viewer, cmd
+			}
+		}
+		if e.mode == viewVelocity {
+			if viewer, cmd, handled := e.updateVelocity(msg); handled {
+				return viewer, cmd
+			}
+		}
+		if e.mode == viewGraph {
+			if viewer, cmd, handled := e.updateGraph(msg); handled {
+				return viewer, cmd
+			}
+		}
+		if e.mode == viewReplay {
+			if viewer, cmd, handled := e.updateReplay(msg); handled {
+				return viewer, cmd
+			}
+		}
+		if e.mode == viewDiff {
+			if viewer, cmd, handled := e.updateDiff(msg); handled {
+				return viewer, cmd
+			}
+		}
+		if e.mode == viewSplit {
+			if viewer, cmd, handled := e.updateSplit(msg); handled {
+				return viewer, cmd
+			}
+		}
+		if e.mode == viewEvents {
+			if viewer, cmd, handled := e.updateEventList(msg); handled {
+				return viewer, cmd
+			}
+		}
+
 		switch {
 		case key.Matches(msg, eventViewerKeys.Back):
 			return e, func() tea.Msg {
@@ -110,6 +504,75 @@ func (e EventViewer) Update(msg tea.Msg) (EventViewer, tea.Cmd) {
 			e.paused = !e.paused
 		case key.Matches(msg, eventViewerKeys.Clear):
 			e.events = make([]midi.Event, 0)
+			e.received = make([]midi.Event, 0)
+			e.listCursor = -1
+			for i := range e.panes {
+				e.panes[i].Scroll = 0
+			}
+			e.notes.Reset()
+			e.controllers.Reset()
+			e.stats.Reset()
+			e.librarian.Reset()
+			e.mpe.Reset()
+			e.velocity.Reset()
+			e.velChannel = 0
+			e.velScroll = 0
+			e.captureStart = time.Now()
+			e.timeMarker = time.Time{}
+			e.dumpCursor = 0
+			e.assembler.Reset()
+		case key.Matches(msg, eventViewerKeys.Dashboard):
+			e.toggleMode(viewDashboard)
+		case key.Matches(msg, eventViewerKeys.Stats):
+			e.toggleMode(viewStats)
+		case key.Matches(msg, eventViewerKeys.Librarian):
+			e.toggleMode(viewLibrarian)
+		case key.Matches(msg, eventViewerKeys.Latency):
+			e.toggleMode(viewLatency)
+			if e.mode == viewLatency && e.outputs == nil && !e.offline {
+				return e, loadOutputs()
+			}
+		case key.Matches(msg, eventViewerKeys.Velocity):
+			e.toggleMode(viewVelocity)
+		case key.Matches(msg, eventViewerKeys.Graph):
+			e.toggleMode(viewGraph)
+		case key.Matches(msg, eventViewerKeys.Replay):
+			e.toggleMode(viewReplay)
+			if e.mode == viewReplay && e.outputs == nil && !e.offline {
+				return e, loadOutputs()
+			}
+		case key.Matches(msg, eventViewerKeys.Diff):
+			e.toggleMode(viewDiff)
+		case key.Matches(msg, eventViewerKeys.Split):
+			e.openSplit()
+		case key.Matches(msg, eventViewerKeys.Export):
+			if len(e.events) == 0 {
+				e.status = "No events to export"
+			} else {
+				e.startPrompt(promptExportEvents, fmt.Sprintf("Export %d shown events as (.jsonl, .csv, .txt)", len(e.events)), "capture.jsonl")
+			}
+		case key.Matches(msg, eventViewerKeys.ExportAll):
+			if len(e.received) == 0 {
+				e.status = "No events to export"
+			} else {
+				e.startPrompt(promptExportAll, fmt.Sprintf("Export all %d events as (.jsonl, .csv, .txt)", len(e.received)), "capture.jsonl")
+			}
+		case key.Matches(msg, eventViewerKeys.Marker):
+			// Mark the newest event, so times read relative to what was just seen
+			e.timeMarker = time.Now()
+			if len(e.events) > 0 {
+				e.timeMarker = e.events[len(e.events)-1].Timestamp
+			}
+			e.status = fmt.Sprintf("Time marker set at %s", models.FormatTime(models.TimeAbsolute, e.timeMarker, time.Time{}, e.filter.Microseconds))
+		case key.Matches(msg, eventViewerKeys.Annotate):
+			e.startPrompt(promptAddMarker, "Marker name", e.markerName())
+		case key.Matches(msg, eventViewerKeys.Panic):
+			if e.device.Out == nil {
+				e.status = "Panic unavailable: no MIDI output paired with this device"
+			} else {
+				e.panicPrompt = true
+				e.status = ""
+			}
 		case key.Matches(msg, eventViewerKeys.Options):
 			return e, func() tea.Msg {
 				return OpenOptionsModalMsg{}
@@ -117,14 +580,179 @@ func (e EventViewer) Update(msg tea.Msg) (EventViewer, tea.Cmd) {
 		}
 
 	case FilterUpdatedMsg:
+		if e.mode == viewSplit && len(e.panes) > 0 {
+			// The options edit the focused pane in a split view
+			e.panes[e.paneFocus].Filter = msg.Filter
+			e.panes[e.paneFocus].Scroll = 0
+			return e, nil
+		}
 		e.filter = msg.Filter
+		if e.offline {
+			// Nothing new arrives, so apply the filter to the loaded capture
+			e.refilter()
+		}
+		if e.diff.Lines != nil {
+			e.diff.Compare(e.filter)
+			e.diffScroll = min(e.diffScroll, max(len(e.diff.Lines)-1, 0))
+		}
 	}
 
 	return e, nil
 }
 
+// receive tracks a received event and adds it to the list if the filter shows it
+func (e *EventViewer) receive(event midi.Event) {
+	if event.MessageType == midi.MarkerMessageType {
+		// A marker from an opened capture: not a message, so nothing to track
+		e.buffer(event)
+		return
+	}
+
+	event.Labels = e.instruments.Resolve(event)
+
+	// The clock also advances with incoming events, so stuck notes and rates stay current
+	// even if a host does not run Init's refresh tick
+	if !e.offline && event.Timestamp.After(e.now) {
+		e.now = event.Timestamp
+	}
+
+	// Track note on/off for active and stuck notes display
+	e.notes.Track(event)
+	e.controllers.Track(event)
+	e.stats.Track(event)
+	e.librarian.Track(event)
+	e.mpe.Track(event)
+	e.latency.Track(event)
+	e.velocity.Track(event)
+
+	e.buffer(event)
+}
+
+// buffer adds an event to the unfiltered buffer, and to the list if the filter shows it
+func (e *EventViewer) buffer(event midi.Event) {
+	for i := range e.panes {
+		e.panes[i].Track(event)
+	}
+	e.received = append(e.received, event)
+	if len(e.received) > e.maxEvents {
+		e.received = e.received[len(e.received)-e.maxEvents:]
+	}
+
+	if e.filter.ShouldShow(event) {
+		e.events = append(e.events, event)
+		// Keep only last maxEvents
+		if dropped := len(e.events) - e.maxEvents; dropped > 0 {
+			e.events = e.events[dropped:]
+			if e.listCursor >= 0 {
+				e.listCursor = max(e.listCursor-dropped, 0) // keep the selected event selected
+			}
+		}
+	}
+}
+
+// refilter rebuilds the list from the unfiltered buffer with the current filter. The
+// selection is dropped, as the indexes of the events change.
+func (e *EventViewer) refilter() {
+	e.events = make([]midi.Event, 0, len(e.received))
+	e.listCursor = -1
+	for _, event := range e.received {
+		if e.filter.ShouldShow(event) {
+			e.events = append(e.events, event)
+		}
+	}
+}
+
+// toggleMode switches the main area to mode, or back to the event list if it is already shown
+func (e *EventViewer) toggleMode(mode viewMode) {
+	if e.mode == mode {
+		e.mode = viewEvents
+	} else {
+		e.mode = mode
+	}
+}
+
+// updatePanicPrompt handles keys while the panic prompt is shown
+func (e EventViewer) updatePanicPrompt(msg tea.KeyMsg) (EventViewer, tea.Cmd) {
+	e.panicPrompt = false
+
+	switch {
+	case key.Matches(msg, panicKeys.AllNotesOff):
+		return e, sendPanic(e.device, "All Notes Off", midi.AllNotesOffMessages(), e.notes.Held())
+	case key.Matches(msg, panicKeys.ResetCCs):
+		return e, sendPanic(e.device, "Reset All Controllers", midi.ResetAllControllersMessages(), nil)
+	case key.Matches(msg, panicKeys.NoteOffs):
+		held := e.notes.Held()
+		if len(held) == 0 {
+			e.status = "No held notes to release"
+			return e, nil
+		}
+		msgs := make([]gomidi.Message, len(held))
+		for i, note := range held {
+			msgs[i] = gomidi.NoteOff(note.Channel, note.Key)
+		}
+		return e, sendPanic(e.device, fmt.Sprintf("Note Off for %d held notes", len(held)), msgs, held)
+	}
+
+	return e, nil
+}
+
+// sendPanic sends panic messages to the device's output and releases the given notes on success
+func sendPanic(device midi.Device, description string, msgs []gomidi.Message, released []models.HeldNote) tea.Cmd {
+	return func() tea.Msg {
+		err := midi.SendMessages(device.Out, msgs...)
+		return PanicSentMsg{Description: description, Released: released, Err: err}
+	}
+}
+
 // View renders the event viewer
 func (e EventViewer) View() string {
+	var b strings.Builder
+
+	b.WriteString(e.renderHeader())
+	b.WriteString("\n")
+
+	notesSection := e.renderActiveNotes()
+	availableHeight := e.bodyHeight()
+
+	switch e.mode {
+	case viewDashboard:
+		b.WriteString(e.renderDashboard(availableHeight + 1)) // dashboard also uses the column header row
+	case viewStats:
+		b.WriteString(e.renderStats(availableHeight + 1))
+	case viewLibrarian:
+		b.WriteString(e.renderLibrarian(availableHeight + 1))
+	case viewLatency:
+		b.WriteString(e.renderLatency(availableHeight + 1))
+	case viewVelocity:
+		b.WriteString(e.renderVelocity(availableHeight + 1))
+	case viewGraph:
+		b.WriteString(e.renderGraph(availableHeight + 1))
+	case viewReplay:
+		b.WriteString(e.renderReplay(availableHeight + 1))
+	case viewDiff:
+		b.WriteString(e.renderDiff(availableHeight + 1))
+	case viewSplit:
+		b.WriteString(e.renderSplit(availableHeight + 1))
+	default:
+		if e.details {
+			b.WriteString(e.renderEventDetails(availableHeight + 1)) // details also use the column header row
+		} else {
+			b.WriteString(e.renderEventList(availableHeight))
+		}
+	}
+
+	// Active notes section
+	b.WriteString("\n")
+	b.WriteString(notesSection)
+	b.WriteString("\n")
+
+	b.WriteString(e.renderHelp())
+
+	return b.String()
+}
+
+// renderHeader renders the device name, indicators and status above the event list
+func (e EventViewer) renderHeader() string {
 	headerStyle := lipgloss.NewStyle().
 		Foreground(e.theme.Primary).
 		Background(e.theme.Background).
@@ -145,37 +773,95 @@ func (e EventViewer) View() string {
 		Bold(true).
 		Padding(0, 1)
 
-	helpStyle := lipgloss.NewStyle().
-		Foreground(e.theme.Muted).
-		Background(e.theme.Background).
-		Padding(0, 1).
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(e.theme.Border).
-		BorderTop(true)
-
-	var b strings.Builder
-
-	// Header
 	header := fmt.Sprintf("MIDI Monitor - %s", e.device.Name)
+	if e.offline {
+		header = fmt.Sprintf("MIDI Capture - %s", e.device.Name)
+	}
+	if e.device.Identity != nil {
+		header += fmt.Sprintf(" [%s]", e.device.Identity)
+	}
 	if e.paused {
 		status := pausedStyle.Render(" [PAUSED] ")
 		header += status
 	}
+	if e.filter.MPE != models.MPEOff {
+		var zones []string
+		for _, zone := range e.mpe.Zones(e.filter.MPE) {
+			zones = append(zones, zone.String())
+		}
+		if len(zones) == 0 {
+			zones = append(zones, "no zones")
+		}
+		header += statusStyle.Render(fmt.Sprintf(" [MPE %s] ", strings.Join(zones, ", ")))
+	}
 	if e.hasActiveFilters() {
 		filterIndicator := statusStyle.Render(" [FILTERED] ")
 		header += filterIndicator
 	}
-	b.WriteString(headerStyle.Width(e.width).Render(header))
-	b.WriteString("\n")
+	if e.status != "" {
+		header += statusStyle.Render(e.status)
+	}
+	return headerStyle.Width(e.width).Render(header)
+}
+
+// renderHelp renders the keys of the current mode, or the prompt being shown
+func (e EventViewer) renderHelp() string {
+	helpStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Muted).
+		Background(e.theme.Background).
+		Padding(0, 1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(e.theme.Border).
+		BorderTop(true)
+
+	helpText := "space: pause • o: options • c: clear • d: dashboard • s: stats • l: librarian • v: velocity • g: graph • e/E: export • R: replay • D: diff • S: split • t: time marker • L: latency • p: panic • esc: devices • q: quit"
+	switch {
+	case e.panicPrompt:
+		helpText = "panic: a: all notes off • r: reset all controllers • n: note off for held notes • esc: cancel"
+	case e.prompting != promptNone:
+		helpText = e.prompt.View()
+	case e.mode == viewLibrarian:
+		helpText = "↑/↓: select • enter: send • w: save .syx • r: load .syx • n: rename • l: close • c: clear • esc: devices • q: quit"
+	case e.mode == viewLatency:
+		helpText = "enter: start/stop • ←/→: output • n: probes • L: close • c: clear • esc: devices • q: quit"
+	case e.mode == viewVelocity:
+		helpText = "←/→: channel • ↑/↓: scroll • w: export csv • v: close • c: clear • esc: devices • q: quit"
+	case e.mode == viewGraph:
+		helpText = "↑/↓: select • enter: add/remove • +/-: zoom • g: close • c: clear • esc: devices • q: quit"
+	case e.mode == viewReplay:
+		helpText = "enter: start/stop • f: load • ←/→: output • +/-: speed • r: loop • [/]: range • R: close • esc: devices • q: quit"
+	case e.mode == viewEvents && e.details:
+		helpText = "↑/↓: select • enter/click: close details • b: bookmark • [/]: jump • esc: devices • q: quit"
+	case e.mode == viewEvents:
+		helpText = "space: pause • ↑/↓: select • enter/click: details • home: follow • m: marker • b: bookmark • [/]: jump • o: options • c: clear • d: dashboard • s: stats • l: librarian • v: velocity • g: graph • e/E: export • R: replay • D: diff • S: split • t: time marker • L: latency • p: panic • esc: devices • q: quit"
+	case e.mode == viewSplit:
+		helpText = "tab: next pane • ↑/↓: scroll • home: follow • a: add pane • x: close pane • o: pane options • S: close • c: clear • esc: devices • q: quit"
+	case e.mode == viewDiff:
+		helpText = "f: load • ↑/↓: scroll • pgup/pgdn: page • n/N: next/prev difference • t: tolerance • D: close • esc: devices • q: quit"
+	}
+	return helpStyle.Width(e.width).Render(helpText)
+}
+
+// bodyHeight returns the rows available for events below the column header: what the
+// header, help (which wraps on narrow terminals) and active notes leave
+func (e EventViewer) bodyHeight() int {
+	activeNotesHeight := lipgloss.Height(e.renderActiveNotes()) + 2                       // notes section + surrounding blank lines
+	availableHeight := e.height - 3 - lipgloss.Height(e.renderHelp()) - activeNotesHeight // header + column header + help + active notes + padding
+	return max(availableHeight, 0)
+}
+
+// renderEventList renders the column headers and the most recent events that fit in availableHeight rows
+func (e EventViewer) renderEventList(availableHeight int) string {
+	var b strings.Builder
 
-	// Calculate column widths
-	timeWidth := 12
-	eventWidth := 16
-	chanWidth := 5
-	noteWidth := 8
-	velWidth := 6
-	ctrlWidth := 7
-	valWidth := 6
+	// Column widths, as dragged with the mouse or by default
+	timeWidth := e.columnWidth("Time")
+	eventWidth := e.columnWidth("Event")
+	chanWidth := e.columnWidth("Chan")
+	noteWidth := e.columnWidth("Note")
+	velWidth := e.columnWidth("Vel")
+	ctrlWidth := e.columnWidth("Ctrl")
+	valWidth := e.columnWidth("Val")
 
 	// Column header styles
 	colHeaderStyle := lipgloss.NewStyle().
@@ -187,129 +873,165 @@ func (e EventViewer) View() string {
 	var headerRow strings.Builder
 	headerRow.WriteString("  ") // Left padding
 	if e.filter.IsColumnVisible("Time") {
-		headerRow.WriteString(colHeaderStyle.Width(timeWidth).Render("Time"))
+		headerRow.WriteString(colHeaderStyle.Width(timeWidth).Render(fitWidth(e.filter.TimeMode.Heading(), timeWidth)))
 		headerRow.WriteString("  ")
 	}
 	if e.filter.IsColumnVisible("Chan") {
-		headerRow.WriteString(colHeaderStyle.Width(chanWidth).Render("Chan"))
+		headerRow.WriteString(colHeaderStyle.Width(chanWidth).Render(fitWidth("Chan", chanWidth)))
 		headerRow.WriteString("  ")
 	}
 	if e.filter.IsColumnVisible("Event") {
-		headerRow.WriteString(colHeaderStyle.Width(eventWidth).Render("Event"))
+		headerRow.WriteString(colHeaderStyle.Width(eventWidth).Render(fitWidth("Event", eventWidth)))
 		headerRow.WriteString("  ")
 	}
 	if e.filter.IsColumnVisible("Note") {
-		headerRow.WriteString(colHeaderStyle.Width(noteWidth).Render("Note"))
+		headerRow.WriteString(colHeaderStyle.Width(noteWidth).Render(fitWidth("Note", noteWidth)))
 		headerRow.WriteString("  ")
 	}
 	if e.filter.IsColumnVisible("Vel") {
-		headerRow.WriteString(colHeaderStyle.Width(velWidth).Render("Vel"))
+		headerRow.WriteString(colHeaderStyle.Width(velWidth).Render(fitWidth("Vel", velWidth)))
 		headerRow.WriteString("  ")
 	}
 	if e.filter.IsColumnVisible("Ctrl") {
-		headerRow.WriteString(colHeaderStyle.Width(ctrlWidth).Render("Ctrl"))
+		headerRow.WriteString(colHeaderStyle.Width(ctrlWidth).Render(fitWidth("Ctrl", ctrlWidth)))
 		headerRow.WriteString("  ")
 	}
 	if e.filter.IsColumnVisible("Val") {
-		headerRow.WriteString(colHeaderStyle.Width(valWidth).Render("Val"))
+		headerRow.WriteString(colHeaderStyle.Width(valWidth).Render(fitWidth("Val", valWidth)))
 	}
 	b.WriteString(headerRow.String())
 	b.WriteString("\n")
 
-	// Calculate available height for events (accounting for column header and active notes)
-	activeNotesHeight := 3 // "Active Notes:" + notes line + blank line
-	availableHeight := e.height - 5 - activeNotesHeight // header + column header + help + active notes + padding
-	if availableHeight < 0 {
-		availableHeight = 0
-	}
-
-	// Events (show most recent at top)
-	eventCount := len(e.events)
-	startIdx := 0
-	if eventCount > availableHeight && availableHeight > 0 {
-		startIdx = eventCount - availableHeight
-	}
-
-	visibleEvents := []midi.Event{}
-	if availableHeight > 0 {
-		visibleEvents = e.events[startIdx:]
-	}
-
 	// Column value styles
 	timeColStyle := lipgloss.NewStyle().Foreground(e.theme.Muted).Width(timeWidth)
 	eventColStyle := lipgloss.NewStyle().Foreground(e.theme.Secondary).Bold(true).Width(eventWidth)
 	chanColStyle := lipgloss.NewStyle().Foreground(e.theme.Primary).Width(chanWidth)
+	errorColStyle := lipgloss.NewStyle().Foreground(e.theme.Error).Bold(true).Width(eventWidth)
 	dataColStyle := lipgloss.NewStyle().Foreground(e.theme.Foreground)
+	expressionStyle := lipgloss.NewStyle().Foreground(e.theme.Muted)
+	markerStyle := lipgloss.NewStyle().Foreground(e.theme.Success).Bold(true)
+	cursorStyle := lipgloss.NewStyle().Foreground(e.theme.Primary).Bold(true)
+	bookmarkStyle := lipgloss.NewStyle().Foreground(e.theme.Warning)
+
+	// Events, newest at top, from the newest event or with the selected event mid-list.
+	// In MPE mode per-note expression is shown under its Note On instead of as rows of
+	// its own, so one event may take two rows.
+	rows := 0
+	for i := e.listStart(availableHeight); i >= 0 && rows < availableHeight; i-- {
+		event := e.events[i]
+		if e.isMPEExpression(event) && i != e.listCursor {
+			continue
+		}
 
-	// Reverse the slice to show newest at top
-	for i := len(visibleEvents) - 1; i >= 0; i-- {
-		event := visibleEvents[i]
+		// The left padding shows the selection or a replayed event, then a bookmark
 		var row strings.Builder
-		row.WriteString("  ") // Left padding
+		switch {
+		case i == e.listCursor:
+			row.WriteString(cursorStyle.Render("▸"))
+		case event.Sent:
+			row.WriteString(expressionStyle.Render("→")) // sent by a replay
+		default:
+			row.WriteString(" ")
+		}
+		if event.Bookmarked {
+			row.WriteString(bookmarkStyle.Render("★"))
+		} else {
+			row.WriteString(" ")
+		}
 
 		if e.filter.IsColumnVisible("Time") {
-			row.WriteString(timeColStyle.Render(event.Timestamp.Format("15:04:05.000")))
+			row.WriteString(timeColStyle.Render(fitWidth(models.FormatTime(e.filter.TimeMode, event.Timestamp, e.timeReference(i), e.filter.Microseconds), timeWidth)))
 			row.WriteString("  ")
 		}
 
 		if e.filter.IsColumnVisible("Chan") {
 			chanVal := ""
-			if event.MessageType != "Unknown" && event.MessageType != "SysEx" {
+			if umpOnly(event) {
+				// Only MIDI 2.0 channel voice packets without a MIDI 1.0 equivalent have a channel
+				if uint8(event.Packet[0]>>28) == midi.UMPMIDI2Voice {
+					chanVal = fmt.Sprintf("%d", event.Channel+1)
+				}
+			} else if event.MessageType != "Unknown" && event.MessageType != "SysEx" && event.MessageType != midi.ErrorMessageType && event.MessageType != midi.MarkerMessageType {
 				chanVal = fmt.Sprintf("%d", event.Channel+1)
 			}
-			row.WriteString(chanColStyle.Render(chanVal))
+			row.WriteString(chanColStyle.Render(fitWidth(chanVal, chanWidth)))
 			row.WriteString("  ")
 		}
 
 		if e.filter.IsColumnVisible("Event") {
-			row.WriteString(eventColStyle.Render(event.MessageType))
+			if event.MessageType == midi.ErrorMessageType {
+				row.WriteString(errorColStyle.Render(fitWidth(event.MessageType, eventWidth)))
+			} else if event.MessageType == midi.MarkerMessageType {
+				row.WriteString(markerStyle.Width(eventWidth).Render(fitWidth(event.MessageType, eventWidth)))
+			} else {
+				row.WriteString(eventColStyle.Render(fitWidth(event.MessageType, eventWidth)))
+			}
 			row.WriteString("  ")
 		}
 
+		// SysEx, errors, markers and UMP-only messages have no MIDI 1.0 note or controller
+		// data; their summary or name spans the data columns instead
+		if event.MessageType == "SysEx" || event.MessageType == midi.ErrorMessageType || event.MessageType == midi.MarkerMessageType || umpOnly(event) {
+			if e.hasDataColumns() {
+				summaryStyle := dataColStyle
+				if event.MessageType == midi.ErrorMessageType {
+					summaryStyle = summaryStyle.Foreground(e.theme.Error)
+				} else if event.MessageType == midi.MarkerMessageType {
+					summaryStyle = markerStyle
+				}
+				if remaining := e.width - lipgloss.Width(row.String()); e.width > 0 && remaining > 0 {
+					summaryStyle = summaryStyle.MaxWidth(remaining)
+				}
+				row.WriteString(summaryStyle.Render(event.Data))
+			}
+			b.WriteString(row.String())
+			b.WriteString("\n")
+			rows++
+			continue
+		}
+
 		// Extract note, velocity, controller, and value from event data
 		note, vel, ctrl, val := e.parseEventData(event)
 
 		if e.filter.IsColumnVisible("Note") {
-			row.WriteString(dataColStyle.Width(noteWidth).Render(note))
+			row.WriteString(dataColStyle.Width(noteWidth).Render(fitWidth(note, noteWidth)))
 			row.WriteString("  ")
 		}
 
 		if e.filter.IsColumnVisible("Vel") {
-			row.WriteString(dataColStyle.Width(velWidth).Render(vel))
+			row.WriteString(dataColStyle.Width(velWidth).Render(fitWidth(vel, velWidth)))
 			row.WriteString("  ")
 		}
 
 		if e.filter.IsColumnVisible("Ctrl") {
-			row.WriteString(dataColStyle.Width(ctrlWidth).Render(ctrl))
+			row.WriteString(dataColStyle.Width(ctrlWidth).Render(fitWidth(ctrl, ctrlWidth)))
 			row.WriteString("  ")
 		}
 
 		if e.filter.IsColumnVisible("Val") {
-			row.WriteString(dataColStyle.Width(valWidth).Render(val))
+			row.WriteString(dataColStyle.Width(valWidth).Render(fitWidth(val, valWidth)))
 		}
 
 		b.WriteString(row.String())
 		b.WriteString("\n")
+		rows++
+
+		if expression, ok := e.mpeExpression(event); ok && rows < availableHeight {
+			b.WriteString(expressionStyle.Render("    └ " + expression))
+			b.WriteString("\n")
+			rows++
+		}
 	}
 
 	// Pad remaining space
-	for i := len(visibleEvents); i < availableHeight; i++ {
+	for ; rows < availableHeight; rows++ {
 		b.WriteString("\n")
 	}
 
-	// Active notes section
-	b.WriteString("\n")
-	b.WriteString(e.renderActiveNotes())
-	b.WriteString("\n")
-
-	// Help
-	helpText := "space: pause • o: options • c: clear • esc: devices • q: quit"
-	b.WriteString(helpStyle.Width(e.width).Render(helpText))
-
 	return b.String()
 }
 
-// renderActiveNotes renders the currently playing notes
+// renderActiveNotes renders the currently playing notes, highlighting and listing stuck ones
 func (e EventViewer) renderActiveNotes() string {
 	labelStyle := lipgloss.NewStyle().
 		Foreground(e.theme.Secondary).
@@ -319,65 +1041,194 @@ func (e EventViewer) renderActiveNotes() string {
 		Foreground(e.theme.Success).
 		Bold(true)
 
-	var notes []string
+	stuckStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Error).
+		Bold(true)
 
-	// Collect all active notes across all channels
-	for ch := uint8(0); ch < 16; ch++ {
-		if channelNotes, ok := e.activeNotes[ch]; ok && len(channelNotes) > 0 {
-			for note := range channelNotes {
-				var noteName string
-				if e.filter.ShowMusicalNotes {
-					noteName = midi.NoteToName(note)
-				} else {
-					noteName = fmt.Sprintf("%d", note)
-				}
-				notes = append(notes, fmt.Sprintf("Ch%d:%s", ch+1, noteName))
+	mutedStyle := lipgloss.NewStyle().Foreground(e.theme.Muted)
+
+	threshold := e.filter.StuckNoteThreshold
+	held := e.notes.Held()
+
+	// In MPE mode member channel notes also show their pitch bend in semitones
+	bends := make(map[[2]uint8]float64)
+	if e.filter.MPE != models.MPEOff {
+		for _, note := range e.mpe.Held() {
+			if e.mpe.IsMember(e.filter.MPE, note.Channel) && note.Bend != 0 {
+				bends[[2]uint8{note.Channel, note.Key}] = note.BendSemitones(e.mpe.BendRange(note.Channel))
 			}
 		}
 	}
 
+	var notes []string
+	for _, note := range held {
+		name := fmt.Sprintf("Ch%d:%s", note.Channel+1, e.formatNote(note.Key))
+		if bend, ok := bends[[2]uint8{note.Channel, note.Key}]; ok {
+			name += fmt.Sprintf("%+.2fst", bend)
+		}
+		if note.IsStuck(e.now, threshold) {
+			notes = append(notes, stuckStyle.Render(name))
+		} else {
+			notes = append(notes, noteStyle.Render(name))
+		}
+	}
+
 	var result strings.Builder
 	result.WriteString(labelStyle.Render("Active Notes: "))
 
 	if len(notes) == 0 {
-		result.WriteString(lipgloss.NewStyle().Foreground(e.theme.Muted).Render("(none)"))
+		result.WriteString(mutedStyle.Render("(none)"))
 	} else {
-		result.WriteString(noteStyle.Render(strings.Join(notes, " ")))
+		result.WriteString(strings.Join(notes, " "))
+	}
+
+	stuck := e.notes.Stuck(e.now, threshold)
+	if len(stuck) == 0 && e.notes.OrphanNoteOffs == 0 {
+		return result.String()
+	}
+
+	result.WriteString("\n")
+	if threshold > 0 {
+		result.WriteString(labelStyle.Render(fmt.Sprintf("Stuck Notes (>%s): ", threshold)))
+		result.WriteString(stuckStyle.Render(fmt.Sprintf("%d", len(stuck))))
+		result.WriteString("  ")
+	}
+	result.WriteString(labelStyle.Render("Orphan Note Offs: "))
+	result.WriteString(stuckStyle.Render(fmt.Sprintf("%d", e.notes.OrphanNoteOffs)))
+
+	for i, note := range stuck {
+		if i == maxStuckNotesListed {
+			result.WriteString("\n")
+			result.WriteString(mutedStyle.Render(fmt.Sprintf("  ... and %d more", len(stuck)-maxStuckNotesListed)))
+			break
+		}
+
+		var ch, key, vel uint8
+		note.Origin.Message.GetNoteOn(&ch, &key, &vel)
+
+		result.WriteString("\n")
+		result.WriteString(stuckStyle.Render(fmt.Sprintf("  Ch%d:%s", note.Channel+1, e.formatNote(note.Key))))
+		result.WriteString(mutedStyle.Render(fmt.Sprintf(" held %.1fs since %s (Note On vel %d, raw % X)",
+			e.now.Sub(note.Start).Seconds(),
+			note.Start.Format("15:04:05.000"),
+			vel,
+			note.Origin.RawBytes)))
 	}
 
 	return result.String()
 }
 
-func (e EventViewer) hasActiveFilters() bool {
-	return len(e.filter.HiddenChannels) > 0 || len(e.filter.HiddenMessageTypes) > 0
+// timeReference returns the time the Time column of e.events[i] is relative to,
+// or the zero time if there is none
+func (e EventViewer) timeReference(i int) time.Time {
+	return e.timeReferenceIn(e.events, i, e.filter.TimeMode)
 }
 
-// updateActiveNotes tracks which notes are currently playing
-func (e *EventViewer) updateActiveNotes(event midi.Event) {
-	var ch, key, vel uint8
-
-	if event.MessageType == "Note On" {
-		if event.Message.GetNoteOn(&ch, &key, &vel) {
-			if vel > 0 {
-				// Note on with velocity > 0 = note started
-				if e.activeNotes[ch] == nil {
-					e.activeNotes[ch] = make(map[uint8]bool)
-				}
-				e.activeNotes[ch][key] = true
-			} else {
-				// Note on with velocity 0 = note off
-				if e.activeNotes[ch] != nil {
-					delete(e.activeNotes[ch], key)
-				}
+// timeReferenceIn returns the time events[i] is relative to in a time mode, or the zero
+// time if there is none
+func (e EventViewer) timeReferenceIn(events []midi.Event, i int, mode models.TimeMode) time.Time {
+	switch mode {
+	case models.TimeElapsed:
+		return e.captureStart
+	case models.TimeDelta:
+		for j := i - 1; j >= 0; j-- {
+			if !e.isMPEExpression(events[j]) && events[j].MessageType != midi.MarkerMessageType {
+				return events[j].Timestamp
 			}
 		}
-	} else if event.MessageType == "Note Off" {
-		if event.Message.GetNoteOff(&ch, &key, &vel) {
-			if e.activeNotes[ch] != nil {
-				delete(e.activeNotes[ch], key)
-			}
+	case models.TimeMarker:
+		if e.timeMarker.IsZero() {
+			return e.captureStart
 		}
+		return e.timeMarker
 	}
+	return time.Time{}
+}
+
+// isMPEExpression returns true for per-note pitch bend, timbre and pressure on an MPE member channel
+func (e EventViewer) isMPEExpression(event midi.Event) bool {
+	if e.filter.MPE == models.MPEOff || !e.mpe.IsMember(e.filter.MPE, event.Channel) {
+		return false
+	}
+
+	var ch, controller, value, pressure uint8
+	var bend int16
+	var abs uint16
+	switch {
+	case event.Message.GetPitchBend(&ch, &bend, &abs), event.Message.GetAfterTouch(&ch, &pressure):
+		return true
+	case event.Message.GetControlChange(&ch, &controller, &value):
+		return controller == models.MPETimbreController
+	}
+	return false
+}
+
+// mpeExpression summarises the expression of the MPE note started by a member channel Note On
+func (e EventViewer) mpeExpression(event midi.Event) (string, bool) {
+	var ch, key, vel uint8
+	if e.filter.MPE == models.MPEOff || !event.Message.GetNoteOn(&ch, &key, &vel) || !e.mpe.IsMember(e.filter.MPE, ch) {
+		return "", false
+	}
+
+	note, ok := e.mpe.Note(ch, key, event.Timestamp)
+	if !ok {
+		return "", false
+	}
+
+	parts := []string{fmt.Sprintf("bend %+.2f st", note.BendSemitones(e.mpe.BendRange(ch)))}
+	if note.HasTimbre {
+		parts = append(parts, fmt.Sprintf("timbre %d", note.Timbre))
+	}
+	if note.HasPressure {
+		parts = append(parts, fmt.Sprintf("pressure %d", note.Pressure))
+	}
+	summary := strings.Join(parts, " • ")
+	if note.Updates > 0 {
+		summary += fmt.Sprintf(" (%d updates)", note.Updates)
+	}
+	return summary, true
+}
+
+// formatNote renders a note number as a musical name or number depending on the filter
+func (e EventViewer) formatNote(note uint8) string {
+	if e.filter.ShowMusicalNotes {
+		return midi.NoteToName(note)
+	}
+	return fmt.Sprintf("%d", note)
+}
+
+// fitWidth cuts plain text to at most width cells, marking a cut with …
+func fitWidth(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// umpOnly returns true for events decoded from Universal MIDI Packets with no MIDI 1.0 equivalent
+func umpOnly(event midi.Event) bool {
+	return event.Packet != nil && event.Message == nil
+}
+
+// hasDataColumns returns true if any of the Note, Vel, Ctrl or Val columns is visible
+func (e EventViewer) hasDataColumns() bool {
+	for _, col := range []string{"Note", "Vel", "Ctrl", "Val"} {
+		if e.filter.IsColumnVisible(col) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e EventViewer) hasActiveFilters() bool {
+	return len(e.filter.HiddenChannels) > 0 || len(e.filter.HiddenMessageTypes) > 0
 }
 
 func (e EventViewer) replaceNoteNumbers(event midi.Event, data string) string {
@@ -407,30 +1258,25 @@ func (e EventViewer) parseEventData(event midi.Event) (note, vel, ctrl, val stri
 	switch event.MessageType {
 	case "Note On", "Note Off":
 		if event.Message.GetNoteOn(&ch, &key, &velocity) || event.Message.GetNoteOff(&ch, &key, &velocity) {
-			if e.filter.ShowMusicalNotes {
-				note = midi.NoteToName(key)
-			} else {
-				note = fmt.Sprintf("%d", key)
-			}
+			note = e.noteLabel(event, ch, key)
 			vel = fmt.Sprintf("%d", velocity)
+			if event.HighResBits == 16 {
+				vel = fmt.Sprintf("%d", event.HighRes) // MIDI 2.0 velocity
+			}
 		}
 	case "Poly Aftertouch":
 		if event.Message.GetPolyAfterTouch(&ch, &key, &pressure) {
-			if e.filter.ShowMusicalNotes {
-				note = midi.NoteToName(key)
-			} else {
-				note = fmt.Sprintf("%d", key)
-			}
+			note = e.noteLabel(event, ch, key)
 			val = fmt.Sprintf("%d", pressure)
 		}
 	case "CC":
 		if event.Message.GetControlChange(&ch, &controller, &value) {
-			ctrl = fmt.Sprintf("%d", controller)
+			ctrl = e.controllerLabel(event, controller)
 			val = fmt.Sprintf("%d", value)
 		}
 	case "Program Change":
 		if event.Message.GetProgramChange(&ch, &value) {
-			val = fmt.Sprintf("%d", value)
+			val = e.programLabel(event, ch, value)
 		}
 	case "Aftertouch":
 		if event.Message.GetAfterTouch(&ch, &pressure) {
@@ -448,9 +1294,71 @@ func (e EventViewer) parseEventData(event midi.Event) (note, vel, ctrl, val stri
 	return
 }
 
-// MIDIEventMsg is sent when a new MIDI event is received
+// noteLabel renders a note, adding its device-specific or percussion name when names are shown
+func (e EventViewer) noteLabel(event midi.Event, ch, key uint8) string {
+	note := e.formatNote(key)
+	if !e.filter.ShowNames {
+		return note
+	}
+
+	name := event.Labels.Note
+	if name == "" && ch == midi.DrumChannel {
+		name = midi.DrumName(key)
+	}
+	if name != "" {
+		note += " " + name
+	}
+	return note
+}
+
+// controllerLabel renders a controller number, adding its device-specific or standard name when names are shown
+func (e EventViewer) controllerLabel(event midi.Event, controller uint8) string {
+	if !e.filter.ShowNames {
+		return fmt.Sprintf("%d", controller)
+	}
+
+	name := event.Labels.Controller
+	if name == "" {
+		name = midi.ControllerName(controller)
+	}
+	return fmt.Sprintf("%d %s", controller, name)
+}
+
+// programLabel renders a program number, adding its device-specific, GM program or drum kit name when names are shown
+func (e EventViewer) programLabel(event midi.Event, ch, program uint8) string {
+	if !e.filter.ShowNames {
+		return fmt.Sprintf("%d", program)
+	}
+
+	name := event.Labels.Program
+	if name == "" && ch == midi.DrumChannel {
+		name = midi.DrumKitName(program)
+	} else if name == "" {
+		name = midi.ProgramName(program)
+	}
+	if name == "" {
+		return fmt.Sprintf("%d", program)
+	}
+	return fmt.Sprintf("%d %s", program, name)
+}
+
+// MIDIEventMsg is sent when a new MIDI event is received.
+// Message holds the bytes of one driver callback: usually one complete message,
+// but possibly a SysEx fragment, several messages, or malformed bytes.
+// Timestamp is the driver's time for them and Arrived when the callback ran;
+// when zero, the time the viewer handles the message is used instead.
 type MIDIEventMsg struct {
-	Message gomidi.Message
+	Message   gomidi.Message
+	Timestamp time.Time
+	Arrived   time.Time
+}
+
+// ListenDevice listens on a device's input and sends each driver callback's bytes with send
+// (typically tea.Program.Send) as a MIDIEventMsg stamped in the listener goroutine
+func ListenDevice(device midi.Device, send func(tea.Msg)) (func(), error) {
+	return midi.Listen(device, func(data []byte, timestamp, arrived time.Time) {
+		send(MIDIEventMsg{Message: data, Timestamp: timestamp, Arrived: arrived})
+	})
 }
 
 // OpenOptionsModalMsg is sent to open the options modal
@@ -463,3 +1371,21 @@ type FilterUpdatedMsg struct {
 
 // BackToDeviceSelectionMsg is sent to return to device selection
 type BackToDeviceSelectionMsg struct{}
+
+// InstrumentsLoadedMsg is sent when instrument definitions have been loaded for the device
+type InstrumentsLoadedMsg struct {
+	Resolver *instruments.Resolver
+	Err      error
+}
+
+// PanicSentMsg is sent when a panic action has been sent to the MIDI output
+type PanicSentMsg struct {
+	Description string
+	Released    []models.HeldNote
+	Err         error
+}
+
+// stuckNoteTickMsg periodically refreshes the stuck-note display
+type stuckNoteTickMsg struct {
+	Time time.Time
+}
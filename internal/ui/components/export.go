@@ -0,0 +1,35 @@
+package components
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/models"
+)
+
+// exportEvents writes events to a file in the format its extension names
+func exportEvents(path string, events []midi.Event) tea.Cmd {
+	events = append([]midi.Event(nil), events...) // the buffer keeps changing while the file is written
+	format := models.ExportFormatFor(path)
+	return func() tea.Msg {
+		file, err := os.Create(path)
+		if err != nil {
+			return EventsExportedMsg{Path: path, Format: format, Err: err}
+		}
+
+		err = models.WriteEvents(file, events, format)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return EventsExportedMsg{Path: path, Format: format, Events: len(events), Err: err}
+	}
+}
+
+// EventsExportedMsg is sent when events have been written to a file
+type EventsExportedMsg struct {
+	Path   string
+	Format models.ExportFormat
+	Events int
+	Err    error
+}
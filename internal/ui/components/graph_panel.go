@@ -0,0 +1,321 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/models"
+)
+
+type graphKeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Toggle  key.Binding
+	ZoomIn  key.Binding
+	ZoomOut key.Binding
+}
+
+var graphKeys = graphKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous series"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next series"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "add/remove series"),
+	),
+	ZoomIn: key.NewBinding(
+		key.WithKeys("+", "="),
+		key.WithHelp("+", "zoom in"),
+	),
+	ZoomOut: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "zoom out"),
+	),
+}
+
+// graphListWidth is the width of the series list beside the graph
+const graphListWidth = 30
+
+// defaultGraphWindow is the index into models.GraphWindows shown at first
+const defaultGraphWindow = 2
+
+// updateGraph handles graph keys; handled is false for keys it does not use
+func (e EventViewer) updateGraph(msg tea.KeyMsg) (EventViewer, tea.Cmd, bool) {
+	available := models.AvailableSeries(e.events)
+
+	switch {
+	case key.Matches(msg, graphKeys.Up):
+		if e.graphCursor > 0 {
+			e.graphCursor--
+		}
+	case key.Matches(msg, graphKeys.Down):
+		if e.graphCursor < len(available)-1 {
+			e.graphCursor++
+		}
+	case key.Matches(msg, graphKeys.Toggle):
+		if e.graphCursor >= len(available) {
+			return e, nil, true
+		}
+		series := available[e.graphCursor]
+		for i, s := range e.graphSeries {
+			if s == series {
+				e.graphSeries = append(e.graphSeries[:i:i], e.graphSeries[i+1:]...)
+				return e, nil, true
+			}
+		}
+		if len(e.graphSeries) < len(e.graphColours()) {
+			e.graphSeries = append(e.graphSeries, series)
+		}
+	case key.Matches(msg, graphKeys.ZoomIn):
+		if e.graphWindow > 0 {
+			e.graphWindow--
+		}
+	case key.Matches(msg, graphKeys.ZoomOut):
+		if e.graphWindow < len(models.GraphWindows)-1 {
+			e.graphWindow++
+		}
+	default:
+		return e, nil, false
+	}
+
+	return e, nil, true
+}
+
+// graphColours are the colours of overlaid series, in the order they are added
+func (e EventViewer) graphColours() []lipgloss.Color {
+	return []lipgloss.Color{e.theme.Success, e.theme.Primary, e.theme.Warning, e.theme.Secondary, e.theme.Error}
+}
+
+// renderGraph renders the series list and a braille line chart of the chosen series over
+// time, filling exactly height lines. Without chosen series the one under the cursor is shown.
+func (e EventViewer) renderGraph(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Secondary).
+		Bold(true).
+		Underline(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Foreground)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Background).
+		Background(e.theme.Primary).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Muted)
+
+	available := models.AvailableSeries(e.events)
+	if len(available) == 0 {
+		content := "  " + titleStyle.Render("Graph") + "\n    " +
+			mutedStyle.Render("No controller, pitch bend or aftertouch events in the buffer yet.")
+		return lipgloss.NewStyle().Height(height).MaxHeight(height).Render(content) + "\n"
+	}
+
+	colours := e.graphColours()
+	shown := e.graphSeries
+	if len(shown) == 0 && e.graphCursor < len(available) {
+		shown = []models.Series{available[e.graphCursor]}
+	}
+	colourOf := func(s models.Series) (lipgloss.Color, bool) {
+		for i, series := range shown {
+			if series == s {
+				return colours[i], true
+			}
+		}
+		return "", false
+	}
+
+	// Series list, scrolled to keep the cursor visible
+	var list []string
+	list = append(list, titleStyle.Render("Series"))
+	listHeight := height - 1
+	first := 0
+	if e.graphCursor >= listHeight {
+		first = e.graphCursor - listHeight + 1
+	}
+	for i := first; i < len(available) && i < first+listHeight; i++ {
+		s := available[i]
+		marker := "  "
+		colour, on := colourOf(s)
+		if on {
+			marker = lipgloss.NewStyle().Foreground(colour).Render("● ")
+		}
+		name := lipgloss.NewStyle().Width(graphListWidth - 2).MaxWidth(graphListWidth - 2).Render(s.String())
+		if i == e.graphCursor {
+			list = append(list, marker+selectedStyle.Render(name))
+		} else {
+			list = append(list, marker+labelStyle.Render(name))
+		}
+	}
+
+	// Time span: the zoom window ending at the newest event, or the whole buffer
+	end := e.events[len(e.events)-1].Timestamp
+	window := models.GraphWindows[min(e.graphWindow, len(models.GraphWindows)-1)]
+	start := end.Add(-window)
+	if window == 0 {
+		start = e.events[0].Timestamp
+		window = end.Sub(start)
+	}
+
+	axisWidth := 5
+	chartWidth := e.width - graphListWidth - axisWidth - 6
+	chartHeight := height - 2 // leave a line for the time axis and one for the legend
+	var chart []string
+	if chartWidth >= 10 && chartHeight >= 2 {
+		canvas := newBrailleCanvas(chartWidth, chartHeight)
+		for i, s := range shown {
+			canvas.plot(models.SeriesPoints(e.events, s), start, window, i)
+		}
+
+		rows := canvas.render(colours)
+		for i, row := range rows {
+			label := ""
+			switch i {
+			case 0:
+				label = "max"
+			case len(rows) / 2:
+				label = "mid"
+			case len(rows) - 1:
+				label = "min"
+			}
+			chart = append(chart, mutedStyle.Render(fmt.Sprintf("%*s ", axisWidth-1, label))+row)
+		}
+
+		left := "-" + formatSpan(window)
+		chart = append(chart, strings.Repeat(" ", axisWidth)+
+			mutedStyle.Render(left+strings.Repeat(" ", max(chartWidth-len(left)-6, 1))+"newest"))
+
+		var legend []string
+		for i, s := range shown {
+			legend = append(legend, lipgloss.NewStyle().Foreground(colours[i]).Render("● "+s.String()))
+		}
+		chart = append(chart, strings.Repeat(" ", axisWidth)+strings.Join(legend, "  "))
+	}
+
+	content := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		lipgloss.NewStyle().Width(graphListWidth+2).Render(strings.Join(list, "\n")),
+		strings.Join(chart, "\n"),
+	)
+	content = "  " + strings.ReplaceAll(content, "\n", "\n  ")
+
+	return lipgloss.NewStyle().Height(height).MaxHeight(height).Render(content) + "\n"
+}
+
+// formatSpan renders a graph time span compactly
+func formatSpan(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return strings.TrimSuffix(d.Round(100*time.Millisecond).String(), ".0s")
+}
+
+// brailleCanvas is a grid of terminal cells each holding 2x4 braille dots, so a chart
+// has twice the horizontal and four times the vertical resolution of the cells
+type brailleCanvas struct {
+	width, height int      // in cells
+	dots          [][]rune // dot bits per cell
+	series        [][]int  // index of the last series drawn in each cell (-1 = none)
+}
+
+// brailleDots maps a dot's column (0-1) and row (0-3) within a cell to its bit
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+func newBrailleCanvas(width, height int) brailleCanvas {
+	c := brailleCanvas{width: width, height: height}
+	c.dots = make([][]rune, height)
+	c.series = make([][]int, height)
+	for y := range c.dots {
+		c.dots[y] = make([]rune, width)
+		c.series[y] = make([]int, width)
+		for x := range c.series[y] {
+			c.series[y][x] = -1
+		}
+	}
+	return c
+}
+
+// set turns on the dot at x, y (in dots, y growing downwards) for a series
+func (c *brailleCanvas) set(x, y, series int) {
+	if x < 0 || y < 0 || x >= c.width*2 || y >= c.height*4 {
+		return
+	}
+	c.dots[y/4][x/2] |= brailleDots[x%2][y%4]
+	c.series[y/4][x/2] = series
+}
+
+// plot draws a series as steps: each value is held until the next one, so the stepping
+// and gaps of a controller show as they were received. The value in force at start is
+// drawn from the left edge and the last value is held to the right edge.
+func (c *brailleCanvas) plot(points []models.Point, start time.Time, window time.Duration, series int) {
+	if len(points) == 0 || window <= 0 {
+		return
+	}
+
+	dotsX, dotsY := c.width*2, c.height*4
+	toX := func(t time.Time) int {
+		return int(float64(t.Sub(start)) / float64(window) * float64(dotsX-1))
+	}
+	toY := func(v float64) int {
+		return dotsY - 1 - int(v*float64(dotsY-1)+0.5)
+	}
+
+	x, y := -1, -1
+	for _, p := range points {
+		px, py := toX(p.At), toY(p.Value)
+		if px < 0 {
+			// Before the window: remember the value so it is drawn from the left edge
+			x, y = 0, py
+			continue
+		}
+		if y >= 0 {
+			for i := x; i <= px; i++ {
+				c.set(i, y, series)
+			}
+			for i := min(y, py); i <= max(y, py); i++ {
+				c.set(px, i, series)
+			}
+		}
+		c.set(px, py, series)
+		x, y = px, py
+	}
+	if y >= 0 {
+		for i := x; i < dotsX; i++ {
+			c.set(i, y, series)
+		}
+	}
+}
+
+// render returns one line per cell row, coloured by the series drawn last in each cell
+func (c brailleCanvas) render(colours []lipgloss.Color) []string {
+	lines := make([]string, c.height)
+	for y := range c.dots {
+		var line strings.Builder
+		for x, bits := range c.dots[y] {
+			cell := string(0x2800 + bits)
+			if s := c.series[y][x]; s >= 0 {
+				cell = lipgloss.NewStyle().Foreground(colours[s%len(colours)]).Render(cell)
+			}
+			line.WriteString(cell)
+		}
+		lines[y] = line.String()
+	}
+	return lines
+}
@@ -0,0 +1,252 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gitlab.com/gomidi/midi/v2/drivers"
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/models"
+)
+
+type latencyKeyMap struct {
+	Run        key.Binding
+	PrevOutput key.Binding
+	NextOutput key.Binding
+	Iterations key.Binding
+}
+
+var latencyKeys = latencyKeyMap{
+	Run: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "start/stop test"),
+	),
+	PrevOutput: key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("←", "previous output"),
+	),
+	NextOutput: key.NewBinding(
+		key.WithKeys("right"),
+		key.WithHelp("→", "next output"),
+	),
+	Iterations: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "number of probes"),
+	),
+}
+
+// latencyHistogramBuckets is the number of bars in the round-trip time histogram
+const latencyHistogramBuckets = 10
+
+// updateLatency handles latency test keys; handled is false for keys it does not use
+func (e EventViewer) updateLatency(msg tea.KeyMsg) (EventViewer, tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, latencyKeys.Run):
+		if e.latency.Running {
+			e.latency.Stop()
+			e.status = "Latency test stopped"
+			return e, nil, true
+		}
+		if e.latencyOut >= len(e.outputs) {
+			e.status = "Latency test unavailable: no MIDI output"
+			return e, nil, true
+		}
+		if e.paused {
+			e.status = "Latency test unavailable while paused"
+			return e, nil, true
+		}
+		session := e.latency.Start(time.Now())
+		e.status = fmt.Sprintf("Sending %d probes to %s", e.latency.Iterations, e.outputs[e.latencyOut])
+		return e, latencyTick(session), true
+	case key.Matches(msg, latencyKeys.PrevOutput):
+		if !e.latency.Running && e.latencyOut > 0 {
+			e.latencyOut--
+		}
+	case key.Matches(msg, latencyKeys.NextOutput):
+		if !e.latency.Running && e.latencyOut < len(e.outputs)-1 {
+			e.latencyOut++
+		}
+	case key.Matches(msg, latencyKeys.Iterations):
+		if !e.latency.Running {
+			e.latency.CycleIterations()
+		}
+	default:
+		return e, nil, false
+	}
+
+	return e, nil, true
+}
+
+// updateLatencyTick sends the next probe and expires probes that have not returned. Ticks of
+// a stopped or restarted test are dropped, so only one chain of ticks sends probes.
+func (e EventViewer) updateLatencyTick(msg latencyTickMsg) (EventViewer, tea.Cmd) {
+	if !e.latency.IsSession(msg.Session) {
+		return e, nil
+	}
+
+	e.latency.Expire(msg.Time)
+	if !e.latency.Running {
+		e.status = fmt.Sprintf("Latency test done: %d of %d probes returned", len(e.latency.RTTs), e.latency.Done())
+		return e, nil
+	}
+
+	if seq, probe, ok := e.latency.NextProbe(); ok {
+		return e, tea.Batch(sendProbe(msg.Session, e.outputs[e.latencyOut], seq, probe), latencyTick(msg.Session))
+	}
+	return e, latencyTick(msg.Session)
+}
+
+// latencyTick schedules the next step of a latency test session
+func latencyTick(session uint16) tea.Cmd {
+	return tea.Tick(models.LatencyProbeInterval, func(t time.Time) tea.Msg {
+		return latencyTickMsg{Time: t, Session: session}
+	})
+}
+
+// updateProbeSent records the send time of a probe of the running test
+func (e EventViewer) updateProbeSent(msg ProbeSentMsg) EventViewer {
+	if !e.latency.IsSession(msg.Session) {
+		return e // a probe of a stopped test
+	}
+	if msg.Err != nil {
+		e.latency.Stop()
+		e.status = fmt.Sprintf("Latency test failed: %v", msg.Err)
+	} else {
+		e.latency.Sent(msg.Seq, msg.At)
+	}
+	return e
+}
+
+// sendProbe sends a latency probe of a test session, noting the time just before it is
+// handed to the driver
+func sendProbe(session uint16, out drivers.Out, seq int, probe []byte) tea.Cmd {
+	return func() tea.Msg {
+		at := time.Now()
+		err := midi.SendMessages(out, probe)
+		return ProbeSentMsg{Seq: seq, Session: session, At: at, Err: err}
+	}
+}
+
+// loadOutputs lists the MIDI outputs for the latency test
+func loadOutputs() tea.Cmd {
+	return func() tea.Msg {
+		outs, err := midi.GetOutputDevices()
+		return OutputsLoadedMsg{Outputs: outs, Err: err}
+	}
+}
+
+// renderLatency renders the latency test settings and results, filling exactly height lines
+func (e EventViewer) renderLatency(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Secondary).
+		Bold(true).
+		Underline(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Foreground)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Primary).
+		Bold(true)
+
+	meterStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Success)
+
+	warnStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Error).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Muted)
+
+	output := "(none)"
+	if e.latencyOut < len(e.outputs) {
+		output = e.outputs[e.latencyOut].String()
+	}
+
+	test := e.latency
+	var lines []string
+	lines = append(lines, titleStyle.Render("Round-Trip Latency"))
+	lines = append(lines, labelStyle.Render("  Output: ")+valueStyle.Render(output)+
+		labelStyle.Render("  Input: ")+valueStyle.Render(e.device.Name))
+	lines = append(lines, labelStyle.Render("  Probes: ")+valueStyle.Render(fmt.Sprintf("%d", test.Iterations))+
+		labelStyle.Render(fmt.Sprintf("  every %s, lost after %s", models.LatencyProbeInterval, models.LatencyTimeout)))
+
+	progress := fmt.Sprintf("%d/%d returned", len(test.RTTs), test.Done())
+	if test.Running {
+		progress = fmt.Sprintf("running: %d/%d done", test.Done(), test.Iterations)
+	}
+	lost := labelStyle.Render(fmt.Sprintf("  lost %d", test.Lost))
+	if test.Lost > 0 {
+		lost = warnStyle.Render(fmt.Sprintf("  lost %d", test.Lost))
+	}
+	lines = append(lines, labelStyle.Render("  Status: ")+valueStyle.Render(progress)+lost)
+	lines = append(lines, "")
+
+	if len(test.RTTs) == 0 {
+		lines = append(lines, mutedStyle.Render("  Connect the output to this input with a loopback cable or a device's MIDI thru,"))
+		lines = append(lines, mutedStyle.Render("  then press enter to send probes and time their return."))
+	} else {
+		summary := test.Summary()
+		lines = append(lines, labelStyle.Render("  min ")+valueStyle.Render(formatRTT(summary.Min))+
+			labelStyle.Render("  avg ")+valueStyle.Render(formatRTT(summary.Mean()))+
+			labelStyle.Render("  p95 ")+valueStyle.Render(formatRTT(test.Percentile(95)))+
+			labelStyle.Render("  max ")+valueStyle.Render(formatRTT(summary.Max)))
+		lines = append(lines, "")
+		lines = append(lines, titleStyle.Render("Histogram"))
+
+		counts, min, width := test.Histogram(latencyHistogramBuckets)
+		most := 0
+		for _, count := range counts {
+			if count > most {
+				most = count
+			}
+		}
+		for i, count := range counts {
+			from := min + time.Duration(i)*width
+			label := fmt.Sprintf("  %9s-%-9s", formatRTT(from), formatRTT(from+width))
+			lines = append(lines, labelStyle.Render(label)+meterStyle.Render(renderMeter(count, most, 40))+
+				valueStyle.Render(fmt.Sprintf(" %d", count)))
+		}
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	content := "  " + strings.Join(lines, "\n  ")
+	return lipgloss.NewStyle().Height(height).Render(content) + "\n"
+}
+
+// formatRTT renders a round-trip time in milliseconds with microsecond precision
+func formatRTT(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
+
+// latencyTickMsg drives a running latency test
+type latencyTickMsg struct {
+	Time    time.Time
+	Session uint16
+}
+
+// ProbeSentMsg is sent when a latency probe has been sent
+type ProbeSentMsg struct {
+	Seq     int
+	Session uint16
+	At      time.Time
+	Err     error
+}
+
+// OutputsLoadedMsg is sent when the MIDI outputs have been listed
+type OutputsLoadedMsg struct {
+	Outputs []drivers.Out
+	Err     error
+}
@@ -0,0 +1,282 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/models"
+)
+
+type librarianKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Send   key.Binding
+	Save   key.Binding
+	Load   key.Binding
+	Rename key.Binding
+}
+
+var librarianKeys = librarianKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous dump"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next dump"),
+	),
+	Send: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "send dump"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "save .syx"),
+	),
+	Load: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "load .syx"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "rename dump"),
+	),
+}
+
+// updateLibrarian handles librarian keys; handled is false for keys it does not use
+func (e EventViewer) updateLibrarian(msg tea.KeyMsg) (EventViewer, tea.Cmd, bool) {
+	dumps := e.librarian.Dumps
+
+	switch {
+	case key.Matches(msg, librarianKeys.Up):
+		if e.dumpCursor > 0 {
+			e.dumpCursor--
+		}
+	case key.Matches(msg, librarianKeys.Down):
+		if e.dumpCursor < len(dumps)-1 {
+			e.dumpCursor++
+		}
+	case key.Matches(msg, librarianKeys.Load):
+		e.startPrompt(promptLoadSyx, "Load .syx file", "")
+	case key.Matches(msg, librarianKeys.Send):
+		if len(dumps) == 0 {
+			return e, nil, true
+		}
+		if e.device.Out == nil {
+			e.status = "Send unavailable: no MIDI output paired with this device"
+			return e, nil, true
+		}
+		dump := dumps[e.dumpCursor]
+		e.status = fmt.Sprintf("Sending %s...", dump.Name)
+		return e, sendDump(e.device, dump, e.filter.SysExDelay), true
+	case key.Matches(msg, librarianKeys.Save):
+		if len(dumps) > 0 {
+			e.startPrompt(promptSaveDump, "Save as", syxFileName(dumps[e.dumpCursor].Name))
+		}
+	case key.Matches(msg, librarianKeys.Rename):
+		if len(dumps) > 0 {
+			e.startPrompt(promptRenameDump, "Rename dump", dumps[e.dumpCursor].Name)
+		}
+	default:
+		return e, nil, false
+	}
+
+	return e, nil, true
+}
+
+// syxFileName turns a dump name into a .syx file name
+func syxFileName(name string) string {
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	if strings.EqualFold(filepath.Ext(clean), ".syx") {
+		return clean
+	}
+	return clean + ".syx"
+}
+
+// saveDump writes a dump to a .syx file
+func saveDump(path string, dump models.Dump) tea.Cmd {
+	return func() tea.Msg {
+		err := midi.SaveSyx(path, dump.Messages)
+		return SyxSavedMsg{Path: path, Err: err}
+	}
+}
+
+// loadSyx reads a .syx file into a dump named after the file
+func loadSyx(path string) tea.Cmd {
+	return func() tea.Msg {
+		msgs, err := midi.LoadSyx(path)
+		if err != nil {
+			return SyxLoadedMsg{Err: err}
+		}
+		return SyxLoadedMsg{Dump: models.NewLoadedDump(filepath.Base(path), msgs)}
+	}
+}
+
+// sendDump sends a dump's messages to the device's output with delay between them
+func sendDump(device midi.Device, dump models.Dump, delay time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		err := midi.SendSysEx(device.Out, dump.Messages, delay)
+		return DumpSentMsg{Name: dump.Name, Messages: len(dump.Messages), Err: err}
+	}
+}
+
+// renderLibrarian renders the captured and loaded SysEx dumps, filling exactly height lines.
+// The selected dump's messages are listed below the dumps.
+func (e EventViewer) renderLibrarian(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	colHeaderStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Secondary).
+		Bold(true).
+		Underline(true)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Foreground)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Background).
+		Background(e.theme.Primary).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Muted)
+
+	okStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Success)
+
+	badStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Error).
+		Bold(true)
+
+	nameWidth := 24
+	makerWidth := 20
+	countWidth := 6
+	bytesWidth := 9
+	checkWidth := 12
+	sourceWidth := 10
+
+	columns := func(name, maker, count, size, check, source string) string {
+		return "  " +
+			lipgloss.NewStyle().Width(nameWidth).MaxWidth(nameWidth).Render(name) + "  " +
+			lipgloss.NewStyle().Width(makerWidth).MaxWidth(makerWidth).Render(maker) + "  " +
+			lipgloss.NewStyle().Width(countWidth).Align(lipgloss.Right).Render(count) + "  " +
+			lipgloss.NewStyle().Width(bytesWidth).Align(lipgloss.Right).Render(size) + "  " +
+			lipgloss.NewStyle().Width(checkWidth).Render(check) + "  " +
+			lipgloss.NewStyle().Width(sourceWidth).Render(source)
+	}
+
+	var lines []string
+	lines = append(lines, colHeaderStyle.Render(columns("Dump", "Manufacturer", "Msgs", "Bytes", "Checksums", "Source")))
+
+	dumps := e.librarian.Dumps
+	if len(dumps) == 0 {
+		lines = append(lines, mutedStyle.Render("  No SysEx received yet. Press r to load a .syx file."))
+	}
+
+	// Show at most half the space as dumps, scrolled to keep the cursor visible
+	listHeight := (height - 1) / 2
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	first := 0
+	if e.dumpCursor >= listHeight {
+		first = e.dumpCursor - listHeight + 1
+	}
+
+	for i := first; i < len(dumps) && i < first+listHeight; i++ {
+		dump := dumps[i]
+
+		valid, invalid := dump.Checksums()
+		var check string
+		switch {
+		case invalid > 0:
+			check = fmt.Sprintf("%d BAD", invalid)
+		case valid > 0:
+			check = fmt.Sprintf("%d ok", valid)
+		default:
+			check = "-"
+		}
+
+		source := dump.Start.Format("15:04:05")
+		if dump.Loaded {
+			source = "file"
+		}
+
+		row := columns(dump.Name, dump.Manufacturer, fmt.Sprintf("%d", len(dump.Messages)),
+			fmt.Sprintf("%d", dump.Bytes()), check, source)
+		switch {
+		case i == e.dumpCursor:
+			lines = append(lines, selectedStyle.Render(row))
+		case invalid > 0:
+			lines = append(lines, badStyle.Render(row))
+		default:
+			lines = append(lines, rowStyle.Render(row))
+		}
+	}
+
+	// Messages of the selected dump
+	if e.dumpCursor < len(dumps) && len(lines) < height-1 {
+		dump := dumps[e.dumpCursor]
+		lines = append(lines, "")
+		lines = append(lines, colHeaderStyle.Render(fmt.Sprintf("  %s (send delay %s)", dump.Name, e.filter.SysExDelay)))
+
+		room := height - len(lines)
+		for i, msg := range dump.Messages {
+			if i == room-1 && len(dump.Messages) > room {
+				lines = append(lines, mutedStyle.Render(fmt.Sprintf("  ... %d more", len(dump.Messages)-i)))
+				break
+			}
+
+			status := midi.VerifyChecksum(msg)
+			summary := midi.DecodeSysEx(msg[1 : len(msg)-1]).Summary
+			line := fmt.Sprintf("  %4d  %-4s %s", i+1, status, summary)
+			switch status {
+			case midi.ChecksumValid:
+				lines = append(lines, okStyle.Render(line))
+			case midi.ChecksumInvalid:
+				lines = append(lines, badStyle.Render(line))
+			default:
+				lines = append(lines, rowStyle.Render(line))
+			}
+		}
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	return lipgloss.NewStyle().Height(height).Render(strings.Join(lines, "\n")) + "\n"
+}
+
+// SyxSavedMsg is sent when a dump has been written to a .syx file
+type SyxSavedMsg struct {
+	Path string
+	Err  error
+}
+
+// SyxLoadedMsg is sent when a .syx file has been read into a dump
+type SyxLoadedMsg struct {
+	Dump models.Dump
+	Err  error
+}
+
+// DumpSentMsg is sent when a dump has been sent to the device's output
+type DumpSentMsg struct {
+	Name     string
+	Messages int
+	Err      error
+}
@@ -0,0 +1,186 @@
+package components
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"midi-viewer/internal/midi"
+)
+
+type eventListKeyMap struct {
+	Newer    key.Binding
+	Older    key.Binding
+	Follow   key.Binding
+	Bookmark key.Binding
+	Previous key.Binding
+	Next     key.Binding
+	Details  key.Binding
+}
+
+var eventListKeys = eventListKeyMap{
+	Newer: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "select newer event"),
+	),
+	Older: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "select older event"),
+	),
+	Follow: key.NewBinding(
+		key.WithKeys("home"),
+		key.WithHelp("home", "follow newest event"),
+	),
+	Bookmark: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "toggle bookmark"),
+	),
+	Previous: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous bookmark or marker"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next bookmark or marker"),
+	),
+	Details: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "show or hide event details"),
+	),
+}
+
+// updateEventList handles event selection and bookmark keys; handled is false for keys it
+// does not use. Without a selection the list follows the newest event.
+func (e EventViewer) updateEventList(msg tea.KeyMsg) (EventViewer, tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, eventListKeys.Older):
+		e.scrollList(1)
+	case key.Matches(msg, eventListKeys.Newer):
+		if e.listCursor >= 0 {
+			e.scrollList(-1)
+		}
+	case key.Matches(msg, eventListKeys.Follow):
+		e.listCursor = -1
+	case key.Matches(msg, eventListKeys.Details):
+		if e.selected() < 0 {
+			e.status = "No event to show"
+		} else {
+			e.details = !e.details
+		}
+	case key.Matches(msg, eventListKeys.Bookmark):
+		e.toggleBookmark()
+	case key.Matches(msg, eventListKeys.Previous), key.Matches(msg, eventListKeys.Next):
+		backwards := key.Matches(msg, eventListKeys.Previous)
+		if i := e.nextMark(backwards); i >= 0 {
+			e.listCursor = i
+		} else if backwards {
+			e.status = "No earlier bookmark or marker"
+		} else {
+			e.status = "No later bookmark or marker"
+		}
+	default:
+		return e, nil, false
+	}
+
+	return e, nil, true
+}
+
+// selected returns the index into events of the selected event, or of the newest event when
+// following, or -1 if there are no events
+func (e EventViewer) selected() int {
+	if e.listCursor >= 0 && e.listCursor < len(e.events) {
+		return e.listCursor
+	}
+	return len(e.events) - 1
+}
+
+// nextMark returns the index into events of the nearest bookmarked event or marker after the
+// selected event (or before it, if backwards), or -1 if there is none
+func (e EventViewer) nextMark(backwards bool) int {
+	step := 1
+	if backwards {
+		step = -1
+	}
+	for i := e.selected() + step; i >= 0 && i < len(e.events); i += step {
+		if e.events[i].Bookmarked || e.events[i].MessageType == midi.MarkerMessageType {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleBookmark bookmarks the selected event, or removes its bookmark. The event is updated
+// in the received buffer too, so exports of all events keep the bookmark.
+func (e *EventViewer) toggleBookmark() {
+	i := e.selected()
+	if i < 0 {
+		e.status = "No event to bookmark"
+		return
+	}
+
+	event := e.events[i]
+	e.events[i].Bookmarked = !event.Bookmarked
+	if j := e.receivedIndex(i); j >= 0 {
+		e.received[j].Bookmarked = !event.Bookmarked
+	}
+
+	if event.Bookmarked {
+		e.status = "Bookmark removed"
+	} else {
+		e.status = fmt.Sprintf("Bookmarked %s at %s", event.MessageType, event.Timestamp.Format("15:04:05.000"))
+	}
+}
+
+// markerName suggests a name for the next marker
+func (e EventViewer) markerName() string {
+	n := 1
+	for _, event := range e.received {
+		if event.MessageType == midi.MarkerMessageType {
+			n++
+		}
+	}
+	return fmt.Sprintf("Marker %d", n)
+}
+
+// addMarker drops a named marker into the stream. A live marker is placed at the current time;
+// a marker in an opened capture is placed after the selected event and then selected.
+func (e *EventViewer) addMarker(name string) {
+	at := time.Now()
+	pos := len(e.received) // events arrive in time order, so a live marker always goes at the end
+	if e.offline {
+		if i := e.selected(); i >= 0 {
+			at = e.events[i].Timestamp
+			if j := e.receivedIndex(i); j >= 0 {
+				// By position rather than time: in .syx files and hex dumps every event has
+				// the same time
+				pos = j + 1
+			}
+		}
+	}
+	marker := midi.NewMarker(name, at)
+	e.status = fmt.Sprintf("Marker %q added", name)
+
+	if pos == len(e.received) {
+		e.buffer(marker)
+		if e.offline {
+			e.listCursor = len(e.events) - 1
+		}
+		return
+	}
+
+	e.received = append(e.received[:pos], append([]midi.Event{marker}, e.received[pos:]...)...)
+	e.refilter()
+	e.listCursor = 0
+	for _, event := range e.received[:pos] {
+		if e.filter.ShouldShow(event) {
+			e.listCursor++
+		}
+	}
+}
+
+// receivedIndex returns the index in received of events[i], or -1 if it is no longer there.
+// events holds the received events the filter shows, so both end with the same events.
+func (e EventViewer) receivedIndex(i int) int {
+	return e.filter.ShownIndex(e.received, len(e.events)-1-i)
+}
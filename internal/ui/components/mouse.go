@@ -0,0 +1,230 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/models"
+)
+
+// listColumns are the event list's columns, in display order
+var listColumns = []string{"Time", "Chan", "Event", "Note", "Vel", "Ctrl", "Val"}
+
+// minColumnWidth and maxColumnWidth bound the width a column can be dragged to
+const (
+	minColumnWidth = 3
+	maxColumnWidth = 60
+)
+
+// wheelLines is how many lines one wheel step scrolls
+const wheelLines = 3
+
+// columnWidth returns the width of an event list column: the width it was dragged to,
+// or its default for the current settings
+func (e EventViewer) columnWidth(col string) int {
+	if width, ok := e.colWidths[col]; ok {
+		return width
+	}
+
+	switch col {
+	case "Time":
+		if e.filter.Microseconds {
+			return 15
+		}
+		return 12
+	case "Chan":
+		return 5
+	case "Event":
+		return 16
+	case "Note":
+		if e.filter.ShowNames {
+			return 24 // room for "C#-1 Acoustic Bass Drum"
+		}
+		return 8
+	case "Vel":
+		return 6
+	case "Ctrl":
+		if e.filter.ShowNames {
+			return 26 // room for "123 Reset All Controllers"
+		}
+		return 7
+	case "Val":
+		if e.filter.ShowNames {
+			return 28 // room for "127 Acoustic Guitar (nylon)"
+		}
+		return 6
+	}
+	return 0
+}
+
+// columnBorderAt returns the visible column whose right border is at x on the column
+// header row, or "" if there is none
+func (e EventViewer) columnBorderAt(x int) string {
+	pos := 2 // left padding
+	for _, col := range listColumns {
+		if !e.filter.IsColumnVisible(col) {
+			continue
+		}
+		border := pos + e.columnWidth(col)
+		if x >= border-1 && x <= border+1 {
+			return col
+		}
+		pos = border + 2
+	}
+	return ""
+}
+
+// listStart returns the index into events of the top row of the event list: the newest
+// event, or one that keeps the selected event mid-list
+func (e EventViewer) listStart(height int) int {
+	start := len(e.events) - 1
+	if e.listCursor >= 0 {
+		start = min(e.listCursor+height/2, start)
+	}
+	return start
+}
+
+// listRows returns the index into events of the event shown on each row of the event list,
+// as renderEventList lays them out
+func (e EventViewer) listRows(height int) []int {
+	var rows []int
+	for i := e.listStart(height); i >= 0 && len(rows) < height; i-- {
+		event := e.events[i]
+		if e.isMPEExpression(event) && i != e.listCursor {
+			continue
+		}
+		rows = append(rows, i)
+		if _, ok := e.mpeExpression(event); ok && len(rows) < height {
+			rows = append(rows, i) // the expression line under the note
+		}
+	}
+	return rows
+}
+
+// updateMouse handles mouse events: the wheel scrolls the event list, the pane under the
+// pointer or the diff, clicking an event opens its details, clicking a pane focuses it and
+// dragging a column border in the column header resizes the column
+func (e EventViewer) updateMouse(msg tea.MouseMsg) (EventViewer, tea.Cmd) {
+	if e.prompting != promptNone || e.panicPrompt {
+		return e, nil
+	}
+
+	// The body starts below the header; the event list's column header is its first row
+	top := lipgloss.Height(e.renderHeader())
+	height := e.bodyHeight()
+	wheel := 0
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		wheel = -wheelLines
+	case tea.MouseButtonWheelDown:
+		wheel = wheelLines
+	}
+
+	switch e.mode {
+	case viewEvents:
+		if e.dragColumn != "" {
+			switch msg.Action {
+			case tea.MouseActionMotion:
+				e.setColumnWidth(e.dragColumn, e.dragWidth+msg.X-e.dragX)
+			case tea.MouseActionRelease:
+				e.dragColumn = ""
+			}
+			return e, nil
+		}
+		if wheel != 0 {
+			e.scrollList(wheel)
+			return e, nil
+		}
+		if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+			return e, nil
+		}
+
+		if e.details {
+			e.details = false // a click closes the details
+			return e, nil
+		}
+		if msg.Y == top {
+			if col := e.columnBorderAt(msg.X); col != "" {
+				e.dragColumn, e.dragX, e.dragWidth = col, msg.X, e.columnWidth(col)
+			}
+			return e, nil
+		}
+		rows := e.listRows(height)
+		if row := msg.Y - top - 1; row >= 0 && row < len(rows) {
+			e.listCursor = rows[row]
+			e.details = true
+		}
+
+	case viewSplit:
+		if i := e.paneAt(msg.X, msg.Y-top, height+1); i >= 0 {
+			if wheel != 0 {
+				pane := &e.panes[i]
+				pane.Scroll = min(max(pane.Scroll+wheel, 0), e.lastScroll(*pane))
+			} else if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+				e.paneFocus = i // focused, the pane's filter is the one o edits
+			}
+		}
+
+	case viewDiff:
+		if wheel != 0 {
+			e.diffScroll = min(max(e.diffScroll+wheel, 0), max(len(e.diff.Lines)-1, 0))
+		}
+	}
+
+	return e, nil
+}
+
+// scrollList moves the event list selection by delta rows, positive towards older events;
+// moving above the newest event follows new events again
+func (e *EventViewer) scrollList(delta int) {
+	if len(e.events) == 0 {
+		return
+	}
+	cursor := e.listCursor
+	if cursor < 0 {
+		cursor = len(e.events)
+	}
+	cursor -= delta
+	switch {
+	case cursor >= len(e.events):
+		e.listCursor = -1
+	case cursor < 0:
+		e.listCursor = 0
+	default:
+		e.listCursor = cursor
+	}
+}
+
+// setColumnWidth sets the width of an event list column, within bounds
+func (e *EventViewer) setColumnWidth(col string, width int) {
+	if e.colWidths == nil {
+		e.colWidths = make(map[string]int)
+	}
+	e.colWidths[col] = min(max(width, minColumnWidth), maxColumnWidth)
+}
+
+// paneAt returns the split view pane at x, y in a body of height lines, or -1 if there is none
+func (e EventViewer) paneAt(x, y, height int) int {
+	cols, rows := models.SplitLayout(len(e.panes), e.width, minPaneWidth)
+	if rows == 0 || y < 0 || y >= height {
+		return -1
+	}
+	paneWidth := max(e.width/cols, minPaneWidth/2)
+
+	// Rows share the height as renderSplit divides it
+	row, rowTop := 0, 0
+	for ; row < rows; row++ {
+		paneHeight := height / rows
+		if row < height%rows {
+			paneHeight++
+		}
+		if y < rowTop+paneHeight {
+			break
+		}
+		rowTop += paneHeight
+	}
+	i := row*cols + x/paneWidth
+	if x/paneWidth >= cols || i >= len(e.panes) {
+		return -1
+	}
+	return i
+}
@@ -2,6 +2,7 @@ package components
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -71,6 +72,7 @@ type OptionsModal struct {
 	currentSection optionsSection
 	messageTypes   []string
 	columns        []string
+	settings       []string
 }
 
 // NewOptionsModal creates a new options modal
@@ -93,6 +95,7 @@ func NewOptionsModal(filter models.Filter, t theme.Theme) OptionsModal {
 			"Start",
 			"Stop",
 			"Continue",
+			"Error",
 		},
 		columns: []string{
 			"Time",
@@ -103,6 +106,15 @@ func NewOptionsModal(filter models.Filter, t theme.Theme) OptionsModal {
 			"Ctrl",
 			"Val",
 		},
+		settings: []string{
+			"Musical Notes",
+			"Names",
+			"Stuck Notes",
+			"SysEx Delay",
+			"MPE",
+			"Time Mode",
+			"Microseconds",
+		},
 	}
 }
 
@@ -113,6 +125,9 @@ func (o OptionsModal) Update(msg tea.Msg) (OptionsModal, tea.Cmd) {
 		o.width = msg.Width
 		o.height = msg.Height
 
+	case tea.MouseMsg:
+		return o.updateMouse(msg), nil
+
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, optionsModalKeys.Up):
@@ -120,17 +135,7 @@ func (o OptionsModal) Update(msg tea.Msg) (OptionsModal, tea.Cmd) {
 				o.cursor--
 			}
 		case key.Matches(msg, optionsModalKeys.Down):
-			maxCursor := 0
-			if o.currentSection == sectionChannels {
-				maxCursor = 15 // 16 channels (0-15)
-			} else if o.currentSection == sectionMessageTypes {
-				maxCursor = len(o.messageTypes) - 1
-			} else if o.currentSection == sectionColumns {
-				maxCursor = len(o.columns) - 1
-			} else if o.currentSection == sectionSettings {
-				maxCursor = 0 // Only one setting for now
-			}
-			if o.cursor < maxCursor {
+			if o.cursor < o.itemCount(o.currentSection)-1 {
 				o.cursor++
 			}
 		case key.Matches(msg, optionsModalKeys.Left):
@@ -156,16 +161,7 @@ func (o OptionsModal) Update(msg tea.Msg) (OptionsModal, tea.Cmd) {
 				o.cursor = 0
 			}
 		case key.Matches(msg, optionsModalKeys.Toggle):
-			if o.currentSection == sectionChannels {
-				o.filter.ToggleChannel(uint8(o.cursor))
-			} else if o.currentSection == sectionMessageTypes {
-				o.filter.ToggleMessageType(o.messageTypes[o.cursor])
-			} else if o.currentSection == sectionColumns {
-				o.filter.ToggleColumn(o.columns[o.cursor])
-			} else if o.currentSection == sectionSettings {
-				// Toggle musical notes setting
-				o.filter.ShowMusicalNotes = !o.filter.ShowMusicalNotes
-			}
+			o.toggleItem()
 		case key.Matches(msg, optionsModalKeys.Clear):
 			o.filter = models.NewFilter()
 		case key.Matches(msg, optionsModalKeys.Close):
@@ -178,8 +174,102 @@ func (o OptionsModal) Update(msg tea.Msg) (OptionsModal, tea.Cmd) {
 	return o, nil
 }
 
+// itemCount returns how many items a section lists
+func (o OptionsModal) itemCount(section optionsSection) int {
+	switch section {
+	case sectionMessageTypes:
+		return len(o.messageTypes)
+	case sectionColumns:
+		return len(o.columns)
+	case sectionSettings:
+		return len(o.settings)
+	}
+	return 16 // channels
+}
+
+// toggleItem toggles the item under the cursor
+func (o *OptionsModal) toggleItem() {
+	if o.currentSection == sectionChannels {
+		o.filter.ToggleChannel(uint8(o.cursor))
+	} else if o.currentSection == sectionMessageTypes {
+		o.filter.ToggleMessageType(o.messageTypes[o.cursor])
+	} else if o.currentSection == sectionColumns {
+		o.filter.ToggleColumn(o.columns[o.cursor])
+	} else if o.currentSection == sectionSettings {
+		o.toggleSetting(o.settings[o.cursor])
+	}
+}
+
+// Where the sections sit in the modal: below the border, padding, title and a blank line,
+// each optionsColumnWidth wide, with the items below the section title and its padding
+const (
+	optionsColumnWidth = 22
+	optionsLeft        = 3 // border + padding
+	optionsSectionTop  = 4
+	optionsItemTop     = 6
+)
+
+// updateMouse handles the mouse: clicking a section title switches to it, clicking an item
+// toggles it and the wheel moves the cursor
+func (o OptionsModal) updateMouse(msg tea.MouseMsg) OptionsModal {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		o.cursor = max(o.cursor-1, 0)
+		return o
+	case tea.MouseButtonWheelDown:
+		o.cursor = min(o.cursor+1, o.itemCount(o.currentSection)-1)
+		return o
+	}
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return o
+	}
+
+	// The modal is centred as lipgloss.Place centres it
+	modal := o.renderModal()
+	x := msg.X - placeOffset(o.width, lipgloss.Width(modal)) - optionsLeft
+	y := msg.Y - placeOffset(o.height, lipgloss.Height(modal))
+	if x < 0 || x >= 4*optionsColumnWidth {
+		return o
+	}
+	section := optionsSection(x / optionsColumnWidth)
+
+	switch item := y - optionsItemTop; {
+	case y == optionsSectionTop:
+		if section != o.currentSection {
+			o.currentSection = section
+			o.cursor = 0
+		}
+	case item >= 0 && item < o.itemCount(section):
+		o.currentSection = section
+		o.cursor = item
+		o.toggleItem()
+	}
+	return o
+}
+
+// placeOffset returns where lipgloss.Place puts something size cells long, centred in total
+func placeOffset(total, size int) int {
+	gap := total - size
+	if gap <= 0 {
+		return 0
+	}
+	return gap - int(math.Round(float64(gap)*0.5))
+}
+
 // View renders the options modal
 func (o OptionsModal) View() string {
+	// Center the modal
+	return lipgloss.Place(
+		o.width,
+		o.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		o.renderModal(),
+	)
+}
+
+// renderModal renders the modal box
+func (o OptionsModal) renderModal() string {
 	modalWidth := 110
 	modalHeight := 25
 
@@ -234,28 +324,19 @@ func (o OptionsModal) View() string {
 
 	columns := lipgloss.JoinHorizontal(
 		lipgloss.Top,
-		lipgloss.NewStyle().Width(22).Render(channelsCol),
-		lipgloss.NewStyle().Width(22).Render(typesCol),
-		lipgloss.NewStyle().Width(22).Render(columnsCol),
-		lipgloss.NewStyle().Width(22).Render(settingsCol),
+		lipgloss.NewStyle().Width(optionsColumnWidth).Render(channelsCol),
+		lipgloss.NewStyle().Width(optionsColumnWidth).Render(typesCol),
+		lipgloss.NewStyle().Width(optionsColumnWidth).Render(columnsCol),
+		lipgloss.NewStyle().Width(optionsColumnWidth).Render(settingsCol),
 	)
 
 	b.WriteString(columns)
 	b.WriteString("\n")
 
-	helpText := "↑/↓: navigate • ←/→: switch section • space: toggle • c: clear all • o/esc: close"
+	helpText := "↑/↓: navigate • ←/→: switch section • space/click: toggle • c: clear all • o/esc: close"
 	b.WriteString(helpStyle.Render(helpText))
 
-	modal := modalStyle.Render(b.String())
-
-	// Center the modal
-	return lipgloss.Place(
-		o.width,
-		o.height,
-		lipgloss.Center,
-		lipgloss.Center,
-		modal,
-	)
+	return modalStyle.Render(b.String())
 }
 
 func (o OptionsModal) renderChannelSection(titleStyle, itemStyle, selectedStyle, activeStyle lipgloss.Style) string {
@@ -406,36 +487,84 @@ func (o OptionsModal) renderSettingsSection(titleStyle, itemStyle, selectedStyle
 	b.WriteString(titleStyle.Render(title))
 	b.WriteString("\n")
 
-	cursor := "  "
-	if sectionActive && o.cursor == 0 {
-		cursor = "> "
-	}
-
-	checkbox := "☐"
-	if o.filter.ShowMusicalNotes {
-		checkbox = "☑"
-	}
+	for i, setting := range o.settings {
+		cursor := "  "
+		if sectionActive && o.cursor == i {
+			cursor = "> "
+		}
 
-	label := fmt.Sprintf("%s%s Musical Notes", cursor, checkbox)
+		name, enabled := o.settingState(setting)
 
-	if sectionActive && o.cursor == 0 {
-		if o.filter.ShowMusicalNotes {
-			b.WriteString(activeStyle.Render(label))
-		} else {
-			b.WriteString(selectedStyle.Render(label))
+		checkbox := "☐"
+		if enabled {
+			checkbox = "☑"
 		}
-	} else {
-		if o.filter.ShowMusicalNotes {
-			b.WriteString(activeStyle.Render(label))
+
+		label := fmt.Sprintf("%s%s %s", cursor, checkbox, name)
+
+		if sectionActive && o.cursor == i {
+			if enabled {
+				b.WriteString(activeStyle.Render(label))
+			} else {
+				b.WriteString(selectedStyle.Render(label))
+			}
 		} else {
-			b.WriteString(itemStyle.Render(label))
+			if enabled {
+				b.WriteString(activeStyle.Render(label))
+			} else {
+				b.WriteString(itemStyle.Render(label))
+			}
 		}
+		b.WriteString("\n")
 	}
-	b.WriteString("\n")
 
 	return b.String()
 }
 
+// settingState returns the display name of a setting and whether it is enabled
+func (o OptionsModal) settingState(setting string) (string, bool) {
+	switch setting {
+	case "Musical Notes":
+		return setting, o.filter.ShowMusicalNotes
+	case "Names":
+		return setting, o.filter.ShowNames
+	case "Stuck Notes":
+		if o.filter.StuckNoteThreshold == 0 {
+			return "Stuck Notes: off", false
+		}
+		return fmt.Sprintf("Stuck Notes: >%s", o.filter.StuckNoteThreshold), true
+	case "SysEx Delay":
+		return fmt.Sprintf("SysEx Delay: %s", o.filter.SysExDelay), o.filter.SysExDelay > 0
+	case "MPE":
+		return fmt.Sprintf("MPE: %s", o.filter.MPE), o.filter.MPE != models.MPEOff
+	case "Time Mode":
+		return fmt.Sprintf("Time Mode: %s", o.filter.TimeMode), o.filter.TimeMode != models.TimeAbsolute
+	case "Microseconds":
+		return setting, o.filter.Microseconds
+	}
+	return setting, false
+}
+
+// toggleSetting toggles a boolean setting or cycles a multi-valued one
+func (o *OptionsModal) toggleSetting(setting string) {
+	switch setting {
+	case "Musical Notes":
+		o.filter.ShowMusicalNotes = !o.filter.ShowMusicalNotes
+	case "Names":
+		o.filter.ShowNames = !o.filter.ShowNames
+	case "Stuck Notes":
+		o.filter.CycleStuckNoteThreshold()
+	case "SysEx Delay":
+		o.filter.CycleSysExDelay()
+	case "MPE":
+		o.filter.CycleMPEMode()
+	case "Time Mode":
+		o.filter.CycleTimeMode()
+	case "Microseconds":
+		o.filter.Microseconds = !o.filter.Microseconds
+	}
+}
+
 // CloseOptionsModalMsg is sent when the options modal is closed
 type CloseOptionsModalMsg struct {
 	Filter models.Filter
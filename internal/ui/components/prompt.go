@@ -0,0 +1,143 @@
+package components
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptAction says what a submitted text prompt is for
+type promptAction int
+
+const (
+	promptNone promptAction = iota
+	promptSaveDump
+	promptLoadSyx
+	promptRenameDump
+	promptExportVelocity
+	promptExportEvents
+	promptExportAll
+	promptLoadCapture
+	promptReplayFrom
+	promptReplayTo
+	promptDiffFirst
+	promptDiffSecond
+	promptAddMarker
+	promptAddPane
+)
+
+// promptResult reports what a key did to a text prompt
+type promptResult int
+
+const (
+	promptEditing promptResult = iota
+	promptSubmitted
+	promptCancelled
+)
+
+// textPrompt is a single-line text input shown in place of the help bar
+type textPrompt struct {
+	label string
+	value []rune
+}
+
+func newTextPrompt(label, value string) textPrompt {
+	return textPrompt{label: label, value: []rune(value)}
+}
+
+// Value returns the entered text
+func (p textPrompt) Value() string {
+	return string(p.value)
+}
+
+// update edits the prompt's value with a key press
+func (p textPrompt) update(msg tea.KeyMsg) (textPrompt, promptResult) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		return p, promptSubmitted
+	case tea.KeyEsc, tea.KeyCtrlC:
+		return p, promptCancelled
+	case tea.KeyBackspace:
+		if len(p.value) > 0 {
+			p.value = p.value[:len(p.value)-1]
+		}
+	case tea.KeyCtrlU:
+		p.value = nil
+	case tea.KeySpace:
+		p.value = append(p.value, ' ')
+	case tea.KeyRunes:
+		p.value = append(p.value, msg.Runes...)
+	}
+	return p, promptEditing
+}
+
+// View renders the prompt with a cursor
+func (p textPrompt) View() string {
+	return p.label + ": " + string(p.value) + "█" + " • enter: ok • esc: cancel"
+}
+
+// startPrompt shows a text prompt for action
+func (e *EventViewer) startPrompt(action promptAction, label, value string) {
+	e.prompting = action
+	e.prompt = newTextPrompt(label, value)
+	e.status = ""
+}
+
+// updatePrompt handles keys while a text prompt is shown
+func (e EventViewer) updatePrompt(msg tea.KeyMsg) (EventViewer, tea.Cmd) {
+	var result promptResult
+	e.prompt, result = e.prompt.update(msg)
+
+	switch result {
+	case promptCancelled:
+		e.prompting = promptNone
+	case promptSubmitted:
+		action := e.prompting
+		e.prompting = promptNone
+		value := strings.TrimSpace(e.prompt.Value())
+		if action == promptDiffSecond {
+			// An empty second capture compares the first with the events in this viewer
+			return e, loadDiff(e.diffFirst, value, e.received, e.device.Name)
+		}
+		if value == "" {
+			return e, nil
+		}
+
+		switch action {
+		case promptSaveDump:
+			if e.dumpCursor < len(e.librarian.Dumps) {
+				return e, saveDump(value, e.librarian.Dumps[e.dumpCursor])
+			}
+		case promptLoadSyx:
+			return e, loadSyx(value)
+		case promptRenameDump:
+			e.librarian.Rename(e.dumpCursor, value)
+		case promptExportVelocity:
+			return e, exportVelocity(value, e.velocity.Report())
+		case promptExportEvents:
+			return e, exportEvents(value, e.events)
+		case promptExportAll:
+			return e, exportEvents(value, e.received)
+		case promptLoadCapture:
+			return e, loadCapture(value)
+		case promptReplayFrom, promptReplayTo:
+			at, err := parseReplayTime(value)
+			if err != nil {
+				e.status = err.Error()
+			} else if action == promptReplayFrom {
+				e.replay.SetRange(at, e.replay.To)
+			} else {
+				e.replay.SetRange(e.replay.From, at)
+			}
+		case promptAddPane:
+			e.addPane(value)
+		case promptAddMarker:
+			e.addMarker(value)
+		case promptDiffFirst:
+			e.diffFirst = value
+			e.startPrompt(promptDiffSecond, "with (empty = events in this viewer)", "")
+		}
+	}
+
+	return e, nil
+}
@@ -0,0 +1,339 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/models"
+)
+
+type replayKeyMap struct {
+	Run        key.Binding
+	Load       key.Binding
+	PrevOutput key.Binding
+	NextOutput key.Binding
+	Faster     key.Binding
+	Slower     key.Binding
+	Loop       key.Binding
+	From       key.Binding
+	To         key.Binding
+}
+
+var replayKeys = replayKeyMap{
+	Run: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "start/stop replay"),
+	),
+	Load: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "load capture"),
+	),
+	PrevOutput: key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("←", "previous output"),
+	),
+	NextOutput: key.NewBinding(
+		key.WithKeys("right"),
+		key.WithHelp("→", "next output"),
+	),
+	Faster: key.NewBinding(
+		key.WithKeys("+", "="),
+		key.WithHelp("+", "faster"),
+	),
+	Slower: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "slower"),
+	),
+	Loop: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "loop"),
+	),
+	From: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "range start"),
+	),
+	To: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "range end"),
+	),
+}
+
+// updateReplay handles replay keys; handled is false for keys it does not use
+func (e EventViewer) updateReplay(msg tea.KeyMsg) (EventViewer, tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, replayKeys.Run):
+		if e.replay.Running {
+			e.status = fmt.Sprintf("Replay stopped after %d events", e.replay.Sent)
+			return e, e.stopReplay(), true
+		}
+		if len(e.replay.Events) == 0 {
+			e.status = "Nothing to replay: press f to load a capture"
+			return e, nil, true
+		}
+		if e.replayOut >= len(e.outputs) {
+			e.status = "Replay unavailable: no MIDI output"
+			return e, nil, true
+		}
+		session := e.replay.Start(time.Now())
+		e.replayCancel = make(chan struct{})
+		e.status = fmt.Sprintf("Replaying %s to %s", e.replay.Name, e.outputs[e.replayOut])
+		return e, e.nextReplayStep(session), true
+	case key.Matches(msg, replayKeys.Load):
+		if !e.replay.Running {
+			e.startPrompt(promptLoadCapture, "Load capture (.mid, .jsonl)", "capture.jsonl")
+		}
+	case key.Matches(msg, replayKeys.PrevOutput):
+		if !e.replay.Running && e.replayOut > 0 {
+			e.replayOut--
+		}
+	case key.Matches(msg, replayKeys.NextOutput):
+		if !e.replay.Running && e.replayOut < len(e.outputs)-1 {
+			e.replayOut++
+		}
+	case key.Matches(msg, replayKeys.Faster):
+		if !e.replay.Running {
+			e.replay.CycleSpeed(true)
+		}
+	case key.Matches(msg, replayKeys.Slower):
+		if !e.replay.Running {
+			e.replay.CycleSpeed(false)
+		}
+	case key.Matches(msg, replayKeys.Loop):
+		e.replay.Loop = !e.replay.Loop
+	case key.Matches(msg, replayKeys.From):
+		if !e.replay.Running && len(e.replay.Events) > 0 {
+			e.startPrompt(promptReplayFrom, "Replay from (seconds)", formatReplayTime(e.replay.From))
+		}
+	case key.Matches(msg, replayKeys.To):
+		if !e.replay.Running && len(e.replay.Events) > 0 {
+			e.startPrompt(promptReplayTo, "Replay to (seconds, 0 = end)", formatReplayTime(e.replay.To))
+		}
+	default:
+		return e, nil, false
+	}
+
+	return e, nil, true
+}
+
+// updateReplaySent shows events a replay has sent and schedules the next ones
+func (e EventViewer) updateReplaySent(msg ReplaySentMsg) (EventViewer, tea.Cmd) {
+	if !e.replay.IsSession(msg.Session) {
+		return e, nil
+	}
+	if msg.Err != nil {
+		e.status = fmt.Sprintf("Replay failed: %v", msg.Err)
+		return e, e.stopReplay()
+	}
+
+	if !e.paused {
+		for _, event := range msg.Events {
+			event.Timestamp = msg.At
+			event.Arrived, event.Processed = time.Time{}, time.Time{}
+			event.Sent = true
+			event.Labels = e.instruments.Resolve(event)
+			e.buffer(event)
+		}
+	}
+
+	return e, e.nextReplayStep(msg.Session)
+}
+
+// nextReplayStep schedules the replay's next events, or reports that it is done
+func (e *EventViewer) nextReplayStep(session int) tea.Cmd {
+	if e.replayOut >= len(e.outputs) {
+		e.status = "Replay stopped: no MIDI output"
+		return e.stopReplay()
+	}
+
+	events, due := e.replay.Step(time.Now(), e.filter)
+	if events == nil {
+		e.status = fmt.Sprintf("Replay done: sent %d events", e.replay.Sent)
+		return nil
+	}
+	return sendReplay(session, e.replayCancel, e.outputs[e.replayOut], events, due)
+}
+
+// stopReplay stops the replay, cancels the send already scheduled and sends All Notes Off to
+// the output, so notes the replay started do not hang
+func (e *EventViewer) stopReplay() tea.Cmd {
+	e.replay.Stop()
+	if e.replayCancel != nil {
+		close(e.replayCancel)
+		e.replayCancel = nil
+	}
+	if e.replayOut >= len(e.outputs) {
+		return nil
+	}
+
+	out := e.outputs[e.replayOut]
+	return func() tea.Msg {
+		return ReplayStoppedMsg{Err: midi.SendMessages(out, midi.AllNotesOffMessages()...)}
+	}
+}
+
+// sendReplay waits until events are due and sends them, unless cancel is closed first.
+// Events decoded from Universal MIDI Packets with no MIDI 1.0 equivalent cannot be sent to
+// a MIDI 1.0 output and are skipped.
+func sendReplay(session int, cancel <-chan struct{}, out drivers.Out, events []midi.Event, due time.Time) tea.Cmd {
+	return func() tea.Msg {
+		timer := time.NewTimer(time.Until(due))
+		defer timer.Stop()
+		select {
+		case <-cancel:
+			return nil
+		case <-timer.C:
+		}
+		select {
+		case <-cancel: // stopped just as the events fell due
+			return nil
+		default:
+		}
+
+		var msgs []gomidi.Message
+		var sent []midi.Event
+		for _, event := range events {
+			if event.Message != nil {
+				msgs = append(msgs, event.Message)
+				sent = append(sent, event)
+			}
+		}
+		at := time.Now()
+		err := midi.SendMessages(out, msgs...)
+		return ReplaySentMsg{Session: session, Events: sent, At: at, Err: err}
+	}
+}
+
+// loadCapture reads a capture file to replay
+func loadCapture(path string) tea.Cmd {
+	return func() tea.Msg {
+		events, err := models.LoadCapture(path)
+		return CaptureLoadedMsg{Name: filepath.Base(path), Events: events, Err: err}
+	}
+}
+
+// parseReplayTime reads a range time as seconds ("12.5") or a Go duration ("1m30s")
+func parseReplayTime(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time in seconds", s)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// formatReplayTime renders a range time as seconds for editing
+func formatReplayTime(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// renderReplay renders the loaded capture, replay settings and progress, filling exactly height lines
+func (e EventViewer) renderReplay(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Secondary).
+		Bold(true).
+		Underline(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Foreground)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Primary).
+		Bold(true)
+
+	meterStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Success)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Muted)
+
+	output := "(none)"
+	if e.replayOut < len(e.outputs) {
+		output = e.outputs[e.replayOut].String()
+	}
+
+	r := e.replay
+	var lines []string
+	lines = append(lines, titleStyle.Render("Replay"))
+
+	if len(r.Events) == 0 {
+		lines = append(lines, mutedStyle.Render("  Press f to load a capture: a Standard MIDI File (.mid) or an export saved with e/E (.jsonl)."))
+		lines = append(lines, mutedStyle.Render("  Its events are sent to the selected output with their original timing."))
+	} else {
+		length := r.Length()
+		end := "end"
+		if r.To > 0 {
+			end = models.FormatDuration(r.To, false, false)
+		}
+		loop := "off"
+		if r.Loop {
+			loop = "on"
+		}
+
+		lines = append(lines, labelStyle.Render("  Capture: ")+valueStyle.Render(r.Name)+
+			labelStyle.Render(fmt.Sprintf("  %d events over %s", len(r.Events), models.FormatDuration(length, false, false))))
+		lines = append(lines, labelStyle.Render("  Output:  ")+valueStyle.Render(output))
+		lines = append(lines, labelStyle.Render("  Speed:   ")+valueStyle.Render(strconv.FormatFloat(r.Speed, 'f', -1, 64)+"x")+
+			labelStyle.Render("  Loop: ")+valueStyle.Render(loop)+
+			labelStyle.Render("  Range: ")+valueStyle.Render(models.FormatDuration(r.From, false, false)+" - "+end))
+		if e.hasActiveFilters() {
+			lines = append(lines, labelStyle.Render("  Only events passing the channel and type filters are sent"))
+		}
+		lines = append(lines, "")
+
+		position := r.Position()
+		state := "stopped"
+		if r.Running {
+			state = fmt.Sprintf("playing: %d sent", r.Sent)
+			if r.Loops > 0 {
+				state += fmt.Sprintf(", loop %d", r.Loops+1)
+			}
+		}
+		lines = append(lines, labelStyle.Render("  ")+
+			meterStyle.Render(renderMeter(int(position/time.Millisecond), max(int(length/time.Millisecond), 1), 40))+
+			labelStyle.Render(" ")+valueStyle.Render(models.FormatDuration(position, false, false))+
+			labelStyle.Render(" / "+models.FormatDuration(length, false, false)+"  ")+
+			mutedStyle.Render(state))
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	content := "  " + strings.Join(lines, "\n  ")
+	return lipgloss.NewStyle().Height(height).Render(content) + "\n"
+}
+
+// ReplaySentMsg is sent when replayed events have been sent to the output
+type ReplaySentMsg struct {
+	Session int
+	Events  []midi.Event
+	At      time.Time
+	Err     error
+}
+
+// ReplayStoppedMsg is sent when All Notes Off has been sent after a replay stopped
+type ReplayStoppedMsg struct {
+	Err error
+}
+
+// CaptureLoadedMsg is sent when a capture file has been read for replay
+type CaptureLoadedMsg struct {
+	Name   string
+	Events []midi.Event
+	Err    error
+}
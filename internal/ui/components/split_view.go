@@ -0,0 +1,275 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/midi"
+	"midi-viewer/internal/models"
+)
+
+type splitKeyMap struct {
+	NextPane key.Binding
+	PrevPane key.Binding
+	Newer    key.Binding
+	Older    key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Follow   key.Binding
+	Add      key.Binding
+	Close    key.Binding
+}
+
+var splitKeys = splitKeyMap{
+	NextPane: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next pane"),
+	),
+	PrevPane: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "previous pane"),
+	),
+	Newer: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "scroll to newer events"),
+	),
+	Older: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "scroll to older events"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdn", "page down"),
+	),
+	Follow: key.NewBinding(
+		key.WithKeys("home"),
+		key.WithHelp("home", "follow newest event"),
+	),
+	Add: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add pane"),
+	),
+	Close: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "close pane"),
+	),
+}
+
+// minPaneWidth is the narrowest a pane gets before panes are stacked instead of side by side
+const minPaneWidth = 40
+
+// splitPage is how many events page up and page down scroll a pane
+const splitPage = 10
+
+// maxDefaultPanes limits the panes created for the active channels when a split view opens
+const maxDefaultPanes = 4
+
+// updateSplit handles split view keys; handled is false for keys it does not use
+func (e EventViewer) updateSplit(msg tea.KeyMsg) (EventViewer, tea.Cmd, bool) {
+	if len(e.panes) == 0 {
+		return e, nil, false
+	}
+	pane := &e.panes[e.paneFocus]
+
+	switch {
+	case key.Matches(msg, splitKeys.NextPane):
+		e.paneFocus = (e.paneFocus + 1) % len(e.panes)
+	case key.Matches(msg, splitKeys.PrevPane):
+		e.paneFocus = (e.paneFocus + len(e.panes) - 1) % len(e.panes)
+	case key.Matches(msg, splitKeys.Newer):
+		pane.Scroll = max(pane.Scroll-1, 0)
+	case key.Matches(msg, splitKeys.Older):
+		pane.Scroll = min(pane.Scroll+1, e.lastScroll(*pane))
+	case key.Matches(msg, splitKeys.PageUp):
+		pane.Scroll = max(pane.Scroll-splitPage, 0)
+	case key.Matches(msg, splitKeys.PageDown):
+		pane.Scroll = min(pane.Scroll+splitPage, e.lastScroll(*pane))
+	case key.Matches(msg, splitKeys.Follow):
+		pane.Scroll = 0
+	case key.Matches(msg, splitKeys.Add):
+		if len(e.panes) >= models.MaxPanes {
+			e.status = fmt.Sprintf("At most %d panes", models.MaxPanes)
+		} else {
+			e.startPrompt(promptAddPane, "Channels for the new pane (e.g. 10 or 1-4)", "")
+		}
+	case key.Matches(msg, splitKeys.Close):
+		if len(e.panes) == 1 {
+			e.status = "The last pane cannot be closed"
+		} else {
+			e.panes = append(e.panes[:e.paneFocus], e.panes[e.paneFocus+1:]...)
+			e.paneFocus = min(e.paneFocus, len(e.panes)-1)
+		}
+	default:
+		return e, nil, false
+	}
+
+	return e, nil, true
+}
+
+// lastScroll returns how far a pane can scroll back: to its oldest event
+func (e EventViewer) lastScroll(pane models.Pane) int {
+	return max(len(pane.Events(e.received))-1, 0)
+}
+
+// openSplit switches to the split view, creating a pane for each active channel the first time
+func (e *EventViewer) openSplit() {
+	e.toggleMode(viewSplit)
+	if e.mode != viewSplit || len(e.panes) > 0 {
+		return
+	}
+
+	channels := models.ActiveChannels(e.received)
+	for _, ch := range channels[:min(len(channels), maxDefaultPanes)] {
+		e.panes = append(e.panes, models.NewChannelPane(e.filter, ch))
+	}
+	if len(e.panes) == 0 {
+		e.panes = append(e.panes, models.NewChannelPane(e.filter))
+	}
+	e.paneFocus = 0
+}
+
+// addPane adds a pane for the channels listed in value and focuses it
+func (e *EventViewer) addPane(value string) {
+	channels, err := models.ParseChannels(value)
+	if err != nil {
+		e.status = err.Error()
+		return
+	}
+	e.panes = append(e.panes, models.NewChannelPane(e.filter, channels...))
+	e.paneFocus = len(e.panes) - 1
+}
+
+// renderSplit tiles the panes, as many side by side as fit the width, filling exactly
+// height lines
+func (e EventViewer) renderSplit(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	cols, rows := models.SplitLayout(len(e.panes), e.width, minPaneWidth)
+	if rows == 0 || height < rows*3 {
+		return strings.Repeat("\n", height)
+	}
+	paneWidth := max(e.width/cols, minPaneWidth/2)
+
+	var tiles []string
+	for row := 0; row < rows; row++ {
+		// Rows share the height, the first ones taking what does not divide evenly
+		paneHeight := height / rows
+		if row < height%rows {
+			paneHeight++
+		}
+
+		var boxes []string
+		for col := 0; col < cols; col++ {
+			i := row*cols + col
+			if i >= len(e.panes) {
+				break
+			}
+			boxes = append(boxes, e.renderPane(i, paneWidth, paneHeight))
+		}
+		tiles = append(tiles, lipgloss.JoinHorizontal(lipgloss.Top, boxes...))
+	}
+
+	return strings.Join(tiles, "\n") + "\n"
+}
+
+// renderPane renders pane i as a bordered box of width by height cells, with the newest
+// event at the top
+func (e EventViewer) renderPane(i, width, height int) string {
+	pane := e.panes[i]
+	focused := i == e.paneFocus
+	innerWidth := width - 2
+	innerHeight := height - 2
+
+	borderColor := e.theme.Border
+	titleStyle := lipgloss.NewStyle().Foreground(e.theme.Muted).Bold(true)
+	if focused {
+		borderColor = e.theme.BorderHighlight
+		titleStyle = titleStyle.Foreground(e.theme.Primary)
+	}
+	timeStyle := lipgloss.NewStyle().Foreground(e.theme.Muted)
+	chanStyle := lipgloss.NewStyle().Foreground(e.theme.Primary)
+	typeStyle := lipgloss.NewStyle().Foreground(e.theme.Secondary).Bold(true)
+	dataStyle := lipgloss.NewStyle().Foreground(e.theme.Foreground)
+	markerStyle := lipgloss.NewStyle().Foreground(e.theme.Success).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(e.theme.Error).Bold(true)
+	bookmarkStyle := lipgloss.NewStyle().Foreground(e.theme.Warning)
+
+	events := pane.Events(e.received)
+	newest := len(events) - 1 - min(pane.Scroll, max(len(events)-1, 0))
+
+	title := pane.Title()
+	if focused {
+		title = "▸ " + title
+	}
+	title += fmt.Sprintf(" - %d events", len(events))
+	if pane.Scroll > 0 {
+		title += ", scrolled"
+	}
+	lines := []string{titleStyle.Render(fitWidth(title, innerWidth))}
+
+	timeWidth := 12
+	if pane.Filter.Microseconds {
+		timeWidth = 15
+	}
+	for j := newest; j >= 0 && len(lines) < innerHeight; j-- {
+		event := events[j]
+
+		flag := " "
+		if event.Bookmarked {
+			flag = bookmarkStyle.Render("★")
+		} else if event.Sent {
+			flag = timeStyle.Render("→")
+		}
+		at := models.FormatTime(pane.Filter.TimeMode, event.Timestamp, e.timeReferenceIn(events, j, pane.Filter.TimeMode), pane.Filter.Microseconds)
+		ch := ""
+		if event.Message.GetChannel(new(uint8)) {
+			ch = fmt.Sprintf("%d", event.Channel+1)
+		}
+		// Panes are narrow: where the event list has data columns, just their values
+		data := event.Data
+		note, vel, ctrl, val := e.parseEventData(event)
+		var values []string
+		for _, value := range []string{note, vel, ctrl, val} {
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+		if len(values) > 0 {
+			data = strings.Join(values, " ")
+		}
+
+		rowTypeStyle, rowDataStyle := typeStyle, dataStyle
+		switch event.MessageType {
+		case midi.MarkerMessageType:
+			rowTypeStyle, rowDataStyle = markerStyle, markerStyle
+		case midi.ErrorMessageType:
+			rowTypeStyle = errorStyle
+		}
+
+		// flag, time, channel and type take fixed widths; the data gets what is left
+		line := flag + timeStyle.Render(fmt.Sprintf("%-*s ", timeWidth, at)) +
+			chanStyle.Render(fmt.Sprintf("%-3s", ch)) +
+			rowTypeStyle.Render(fmt.Sprintf("%-12s ", fitWidth(event.MessageType, 12)))
+		if remaining := innerWidth - 1 - (timeWidth + 1) - 3 - 13; remaining > 0 {
+			line += rowDataStyle.Render(fitWidth(data, remaining))
+		}
+		lines = append(lines, line)
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Width(innerWidth).
+		Height(innerHeight).
+		MaxHeight(height).
+		Render(strings.Join(lines, "\n"))
+}
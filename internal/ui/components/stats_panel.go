@@ -0,0 +1,135 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/models"
+)
+
+// formatLatency renders the average and range of a latency
+func formatLatency(l models.Latency) string {
+	return fmt.Sprintf("avg %s (%s-%s)", l.Mean().Round(time.Microsecond), l.Min.Round(time.Microsecond), l.Max.Round(time.Microsecond))
+}
+
+// renderStats renders the message statistics panel, filling exactly height lines
+func (e EventViewer) renderStats(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Secondary).
+		Bold(true).
+		Underline(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Foreground)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Primary).
+		Bold(true)
+
+	meterStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Success)
+
+	warnStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Error).
+		Bold(true)
+
+	stats := e.stats
+	msgRate, byteRate := stats.Rates(e.now)
+	peakMsgRate, peakByteRate := stats.PeakRates()
+	utilisation := models.DINUtilisation(byteRate)
+	peakUtilisation := models.DINUtilisation(peakByteRate)
+
+	utilisationStyle := meterStyle
+	if peakUtilisation >= 100 {
+		utilisationStyle = warnStyle
+	}
+
+	var summary strings.Builder
+	summary.WriteString(titleStyle.Render("Totals"))
+	summary.WriteString("\n")
+	summary.WriteString(labelStyle.Render("  Messages: "))
+	summary.WriteString(valueStyle.Render(fmt.Sprintf("%d", stats.Messages)))
+	summary.WriteString(labelStyle.Render("  Bytes: "))
+	summary.WriteString(valueStyle.Render(fmt.Sprintf("%d", stats.Bytes)))
+	if stats.Messages > 0 {
+		summary.WriteString(labelStyle.Render(fmt.Sprintf("  since %s", stats.Start.Format("15:04:05"))))
+	}
+	summary.WriteString("\n")
+	summary.WriteString(labelStyle.Render("  Rate: "))
+	summary.WriteString(valueStyle.Render(fmt.Sprintf("%.0f msg/s  %.0f B/s", msgRate, byteRate)))
+	summary.WriteString(labelStyle.Render("  Peak: "))
+	summary.WriteString(valueStyle.Render(fmt.Sprintf("%.0f msg/s  %.0f B/s", peakMsgRate, peakByteRate)))
+	summary.WriteString("\n")
+	summary.WriteString(labelStyle.Render("  DIN utilisation: "))
+	summary.WriteString(utilisationStyle.Render(renderMeter(int(utilisation), 100, 30)))
+	summary.WriteString(valueStyle.Render(fmt.Sprintf(" %.1f%%", utilisation)))
+	summary.WriteString(labelStyle.Render(" (peak "))
+	summary.WriteString(utilisationStyle.Render(fmt.Sprintf("%.1f%%", peakUtilisation)))
+	summary.WriteString(labelStyle.Render(")"))
+	summary.WriteString("\n")
+	if stats.Driver.Count > 0 {
+		summary.WriteString(labelStyle.Render("  Driver latency: "))
+		summary.WriteString(valueStyle.Render(formatLatency(stats.Driver)))
+		summary.WriteString(labelStyle.Render("  App latency: "))
+		summary.WriteString(valueStyle.Render(formatLatency(stats.App)))
+		summary.WriteString("\n")
+	}
+
+	// Per-channel activity, scaled to the busiest channel
+	maxChannel := 0
+	for _, count := range stats.ByChannel {
+		if count > maxChannel {
+			maxChannel = count
+		}
+	}
+
+	var channels strings.Builder
+	channels.WriteString(titleStyle.Render("Channels"))
+	channels.WriteString("\n")
+	for ch := uint8(0); ch < 16; ch++ {
+		count := stats.ByChannel[ch]
+		if count == 0 {
+			continue
+		}
+		channels.WriteString(labelStyle.Render(fmt.Sprintf("  Ch %-3d %8d %6.0f/s ", ch+1, count, stats.ChannelRate(ch, e.now))))
+		channels.WriteString(meterStyle.Render(renderMeter(count, maxChannel, 16)))
+		channels.WriteString("\n")
+	}
+
+	// Per-type counts, busiest first
+	types := make([]string, 0, len(stats.ByType))
+	for msgType := range stats.ByType {
+		types = append(types, msgType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if stats.ByType[types[i]] != stats.ByType[types[j]] {
+			return stats.ByType[types[i]] > stats.ByType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	var typeCounts strings.Builder
+	typeCounts.WriteString(titleStyle.Render("Message Types"))
+	typeCounts.WriteString("\n")
+	for _, msgType := range types {
+		typeCounts.WriteString(labelStyle.Render(fmt.Sprintf("  %-16s %8d", msgType, stats.ByType[msgType])))
+		typeCounts.WriteString("\n")
+	}
+
+	breakdown := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		lipgloss.NewStyle().Width(50).Render(channels.String()),
+		typeCounts.String(),
+	)
+
+	content := "  " + strings.ReplaceAll(summary.String()+"\n"+breakdown, "\n", "\n  ")
+
+	return lipgloss.NewStyle().Height(height).MaxHeight(height).Render(content) + "\n"
+}
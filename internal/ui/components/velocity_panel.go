@@ -0,0 +1,224 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"midi-viewer/internal/models"
+)
+
+type velocityKeyMap struct {
+	Up          key.Binding
+	Down        key.Binding
+	PrevChannel key.Binding
+	NextChannel key.Binding
+	Export      key.Binding
+}
+
+var velocityKeys = velocityKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "scroll up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "scroll down"),
+	),
+	PrevChannel: key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("←", "previous channel"),
+	),
+	NextChannel: key.NewBinding(
+		key.WithKeys("right"),
+		key.WithHelp("→", "next channel"),
+	),
+	Export: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "export csv"),
+	),
+}
+
+// velocityHistogramBuckets is the number of bars in a channel's velocity histogram
+const velocityHistogramBuckets = 8
+
+// updateVelocity handles velocity analysis keys; handled is false for keys it does not use
+func (e EventViewer) updateVelocity(msg tea.KeyMsg) (EventViewer, tea.Cmd, bool) {
+	channels := e.velocity.Channels()
+
+	switch {
+	case key.Matches(msg, velocityKeys.Up):
+		if e.velScroll > 0 {
+			e.velScroll--
+		}
+	case key.Matches(msg, velocityKeys.Down):
+		if len(channels) == 0 {
+			break
+		}
+		channel := channels[min(e.velChannel, len(channels)-1)]
+		keys := 0
+		for _, r := range e.velocity.Report() {
+			if r.Channel == channel {
+				keys++
+			}
+		}
+		if e.velScroll < keys-1 {
+			e.velScroll++
+		}
+	case key.Matches(msg, velocityKeys.PrevChannel):
+		if e.velChannel > 0 {
+			e.velChannel--
+			e.velScroll = 0
+		}
+	case key.Matches(msg, velocityKeys.NextChannel):
+		if e.velChannel < len(channels)-1 {
+			e.velChannel++
+			e.velScroll = 0
+		}
+	case key.Matches(msg, velocityKeys.Export):
+		if len(channels) > 0 {
+			e.startPrompt(promptExportVelocity, "Export velocity report as", "velocity.csv")
+		}
+	default:
+		return e, nil, false
+	}
+
+	return e, nil, true
+}
+
+// exportVelocity writes the velocity report for all channels to a CSV file
+func exportVelocity(path string, report []models.KeyReport) tea.Cmd {
+	return func() tea.Msg {
+		file, err := os.Create(path)
+		if err != nil {
+			return VelocityExportedMsg{Path: path, Err: err}
+		}
+
+		err = models.WriteVelocityCSV(file, report)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return VelocityExportedMsg{Path: path, Keys: len(report), Err: err}
+	}
+}
+
+// renderVelocity renders the velocity histogram and per-key calibration report for the selected
+// channel, filling exactly height lines
+func (e EventViewer) renderVelocity(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Secondary).
+		Bold(true).
+		Underline(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Foreground)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Primary).
+		Bold(true)
+
+	meterStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Success)
+
+	warnStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Warning)
+
+	badStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Error).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(e.theme.Muted)
+
+	channels := e.velocity.Channels()
+	if len(channels) == 0 {
+		content := "  " + titleStyle.Render("Velocity Analysis") + "\n    " +
+			mutedStyle.Render("No Note Ons received yet. Play every key softly and hard to build the report.")
+		return lipgloss.NewStyle().Height(height).MaxHeight(height).Render(content) + "\n"
+	}
+
+	channel := channels[min(e.velChannel, len(channels)-1)]
+	var keys []models.KeyReport
+	notes, partial, odd := 0, 0, 0
+	for _, r := range e.velocity.Report() {
+		if r.Channel != channel {
+			continue
+		}
+		keys = append(keys, r)
+		notes += r.Notes
+		if !r.FullRange() {
+			partial++
+		}
+		if r.Odd {
+			odd++
+		}
+	}
+
+	var lines []string
+	lines = append(lines, titleStyle.Render("Velocity Analysis"))
+	lines = append(lines, labelStyle.Render("  Channel ")+valueStyle.Render(fmt.Sprintf("%d", channel+1))+
+		labelStyle.Render(fmt.Sprintf(" (%d of %d)  %d notes on %d keys  ", min(e.velChannel, len(channels)-1)+1, len(channels), notes, len(keys)))+
+		warnStyle.Render(fmt.Sprintf("%d without full range (%d-%d)", partial, models.FullRangeLow, models.FullRangeHigh))+
+		labelStyle.Render("  ")+
+		badStyle.Render(fmt.Sprintf("%d odd", odd)))
+	lines = append(lines, "")
+
+	// Histogram of all velocities on the channel
+	buckets := e.velocity.ChannelCounts(channel).Buckets(velocityHistogramBuckets)
+	most := 0
+	for _, count := range buckets {
+		most = max(most, count)
+	}
+	for i, count := range buckets {
+		from, to := models.BucketRange(i, velocityHistogramBuckets)
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("  %3d-%-3d ", from, to))+
+			meterStyle.Render(renderMeter(count, max(most, 1), 40))+
+			valueStyle.Render(fmt.Sprintf(" %d", count)))
+	}
+	lines = append(lines, "")
+
+	// Per-key table, scrolled within the remaining space
+	lines = append(lines, titleStyle.Render(fmt.Sprintf("%-5s %-5s %6s %4s %4s %6s %6s  %s", "Key", "Note", "Notes", "Min", "Max", "Mean", "Dev", "Flags")))
+	room := height - len(lines)
+	first := min(e.velScroll, max(len(keys)-room, 0))
+	for i := first; i < len(keys) && i < first+room; i++ {
+		r := keys[i]
+		var flags []string
+		if !r.FullRange() {
+			flags = append(flags, "no full range")
+		}
+		if r.Odd {
+			flags = append(flags, "odd")
+		}
+		row := fmt.Sprintf("%-5d %-5s %6d %4d %4d %6.1f %+6.1f  %s", r.Key, e.formatNote(r.Key), r.Notes, r.Min, r.Max, r.Mean(), r.Deviation, strings.Join(flags, ", "))
+		switch {
+		case r.Odd:
+			lines = append(lines, badStyle.Render(row))
+		case !r.FullRange():
+			lines = append(lines, warnStyle.Render(row))
+		default:
+			lines = append(lines, labelStyle.Render(row))
+		}
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	content := "  " + strings.Join(lines, "\n  ")
+	return lipgloss.NewStyle().Height(height).Render(content) + "\n"
+}
+
+// VelocityExportedMsg is sent when the velocity report has been written to a CSV file
+type VelocityExportedMsg struct {
+	Path string
+	Keys int
+	Err  error
+}